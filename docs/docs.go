@@ -108,6 +108,109 @@ const docTemplate = `{
                 }
             }
         },
+        "/chat/completion/regenerate/{id}": {
+            "post": {
+                "description": "在同一条用户消息下生成新的 assistant 回复分支，传入 assistant 消息或其对应的 user 消息 ID 均可",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "重新生成回复",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "消息 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "补全参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.completionParams"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/chat/completion/resume/{id}": {
+            "get": {
+                "description": "客户端断线后重新连接回复消息的事件流，重放 Last-Event-ID 请求头（或 last_event_id 参数）之后的事件，并继续输出后续事件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "续传流式输出",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "assistant 消息 ID（即 ID 事件中的 a）",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "最后收到的事件 ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "最后收到的事件 ID，请求头优先",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/chat/completion/stop/{message_id}": {
+            "post": {
+                "description": "停止正在生成的回复，已输出的内容会被保存，并在消息 extra 中标记 stopped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "停止生成",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "assistant 消息 ID（即 ID 事件中的 a）",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
         "/chat/completion/stream/{session_id}": {
             "post": {
                 "description": "流式输出聊天",
@@ -167,7 +270,7 @@ const docTemplate = `{
         },
         "/chat/config/models": {
             "get": {
-                "description": "获取模型配置",
+                "description": "获取当前用户套餐可使用的模型配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -188,9 +291,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/chat/message/list/{session_id}": {
+        "/chat/config/tools": {
             "get": {
-                "description": "获取消息",
+                "description": "获取已启用的对话工具，可用于会话或单条消息的工具选择",
                 "consumes": [
                     "application/json"
                 ],
@@ -198,57 +301,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Message"
-                ],
-                "summary": "获取消息",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "分页参数",
-                        "name": "page_num",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "sort_expr",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "start_time",
-                        "in": "query"
-                    }
+                    "config"
                 ],
+                "summary": "获取工具配置",
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-chat_ChatMessageListResponse"
+                            "$ref": "#/definitions/entity.CommonResponse-array_services_ToolInfo"
                         }
                     }
                 }
             }
         },
-        "/chat/message/list/{session_id}/shared": {
-            "get": {
-                "description": "获取分享过的消息",
+        "/chat/knowledge/create": {
+            "post": {
+                "description": "创建知识库，未指定向量化模型集合时使用系统配置的默认集合，创建后不可修改",
                 "consumes": [
                     "application/json"
                 ],
@@ -256,62 +324,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Message"
+                    "Knowledge"
                 ],
-                "summary": "获取分享过的消息",
+                "summary": "创建知识库",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "name": "code",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "分页参数",
-                        "name": "page_num",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "sort_expr",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "start_time",
-                        "in": "query"
+                        "description": "知识库参数",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.CreateKnowledgeBase.createRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "成功创建的知识库",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-chat_ChatMessageListResponse"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_KnowledgeBase"
                         }
                     }
                 }
             }
         },
-        "/chat/message/{id}/update": {
+        "/chat/knowledge/document/{id}/delete": {
             "post": {
-                "description": "更新消息（仅 extra 字段的增量合并更新）",
+                "description": "删除文档及其分段",
                 "consumes": [
                     "application/json"
                 ],
@@ -319,40 +358,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Message"
+                    "Knowledge"
                 ],
-                "summary": "更新消息",
+                "summary": "删除知识库文档",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "消息 ID",
+                        "type": "integer",
+                        "description": "文档 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "更新的消息数据",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Message"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Message"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/chat/session/del/{session_id}": {
+        "/chat/knowledge/document/{id}/reprocess": {
             "post": {
-                "description": "删除会话",
+                "description": "按知识库当前的分段参数重新分段及向量化文档，用于处理失败后重试",
                 "consumes": [
                     "application/json"
                 ],
@@ -360,21 +390,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Knowledge"
                 ],
-                "summary": "删除会话",
+                "summary": "重新处理知识库文档",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
+                        "type": "integer",
+                        "description": "文档 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "提交成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -382,9 +412,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/chat/session/flag/{session_id}": {
-            "post": {
-                "description": "更新用户会话标记",
+        "/chat/knowledge/list": {
+            "get": {
+                "description": "获取当前用户创建的知识库及公开的知识库",
                 "consumes": [
                     "application/json"
                 ],
@@ -392,40 +422,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Knowledge"
                 ],
-                "summary": "更新用户会话标记",
+                "summary": "获取知识库列表",
+                "responses": {
+                    "200": {
+                        "description": "知识库列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_schema_KnowledgeBase"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/knowledge/{id}": {
+            "get": {
+                "description": "获取当前用户可访问的知识库",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Knowledge"
+                ],
+                "summary": "获取知识库",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "会话信息",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.SessionFlagInfo"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "知识库",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_KnowledgeBase"
                         }
                     }
                 }
             }
         },
-        "/chat/session/list": {
-            "get": {
-                "description": "获取会话列表",
+        "/chat/knowledge/{id}/delete": {
+            "post": {
+                "description": "删除当前用户创建的知识库及其全部文档",
                 "consumes": [
                     "application/json"
                 ],
@@ -433,50 +477,102 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Knowledge"
                 ],
-                "summary": "获取会话列表",
+                "summary": "删除知识库",
                 "parameters": [
                     {
                         "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "分页参数",
-                        "name": "page_num",
-                        "in": "query"
-                    },
+                        "description": "知识库 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/knowledge/{id}/document/list": {
+            "get": {
+                "description": "获取知识库中的文档及其处理状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Knowledge"
+                ],
+                "summary": "获取知识库文档列表",
+                "parameters": [
                     {
                         "type": "integer",
-                        "name": "page_size",
-                        "in": "query"
-                    },
+                        "description": "知识库 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "文档列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_schema_KnowledgeDocument"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/knowledge/{id}/document/upload": {
+            "post": {
+                "description": "上传文本、Markdown、HTML 或 PDF 文档，正文提取后在后台分段及向量化，可通过文档列表查看处理状态",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Knowledge"
+                ],
+                "summary": "上传知识库文档",
+                "parameters": [
                     {
-                        "type": "string",
-                        "name": "sort_expr",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "name": "start_time",
-                        "in": "query"
+                        "type": "file",
+                        "description": "文档",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "上传的文档",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedContinuationResponse-schema_UserSession"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_KnowledgeDocument"
                         }
                     }
                 }
             }
         },
-        "/chat/session/new": {
+        "/chat/knowledge/{id}/search": {
             "post": {
-                "description": "创建会话",
+                "description": "检索知识库中与查询最相关的分段，用于调试分段及检索参数",
                 "consumes": [
                     "application/json"
                 ],
@@ -484,22 +580,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Knowledge"
+                ],
+                "summary": "检索知识库",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "查询",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.SearchKnowledgeBase.searchRequest"
+                        }
+                    }
                 ],
-                "summary": "创建会话",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "检索结果",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-string"
+                            "$ref": "#/definitions/entity.CommonResponse-array_services_RetrievedChunk"
                         }
                     }
                 }
             }
         },
-        "/chat/session/share/{session_id}": {
+        "/chat/knowledge/{id}/update": {
             "post": {
-                "description": "分享会话",
+                "description": "更新当前用户创建的知识库，分段参数仅对之后处理的文档生效",
                 "consumes": [
                     "application/json"
                 ],
@@ -507,30 +621,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Knowledge"
                 ],
-                "summary": "分享会话",
+                "summary": "更新知识库",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "分享信息",
+                        "description": "知识库参数",
                         "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/chat.ShareSession.ShareRequest"
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_KnowledgeBase"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "更新成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -538,9 +652,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/chat/session/sync": {
+        "/chat/message/list/{session_id}": {
             "get": {
-                "description": "同步会话列表",
+                "description": "获取消息",
                 "consumes": [
                     "application/json"
                 ],
@@ -548,21 +662,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Message"
                 ],
-                "summary": "同步会话列表",
+                "summary": "获取消息",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "客户端上次同步时间戳",
-                        "name": "last_sync_time",
-                        "in": "query",
-                        "required": true
+                        "name": "end_time",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
@@ -590,15 +704,15 @@ const docTemplate = `{
                     "200": {
                         "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedSyncListResponse-schema_UserSession"
+                            "$ref": "#/definitions/entity.CommonResponse-chat_ChatMessageListResponse"
                         }
                     }
                 }
             }
         },
-        "/chat/session/update/{session_id}": {
-            "post": {
-                "description": "更新会话",
+        "/chat/message/list/{session_id}/shared": {
+            "get": {
+                "description": "获取分享过的消息",
                 "consumes": [
                     "application/json"
                 ],
@@ -606,9 +720,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Message"
                 ],
-                "summary": "更新会话",
+                "summary": "获取分享过的消息",
                 "parameters": [
                     {
                         "type": "string",
@@ -618,60 +732,84 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "会话信息",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Session"
-                        }
+                        "type": "string",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-chat_ChatMessageListResponse"
                         }
                     }
                 }
             }
         },
-        "/chat/session/user/{session_id}": {
-            "get": {
-                "description": "获取用户会话",
+        "/chat/message/{id}/edit": {
+            "post": {
+                "description": "编辑一条历史用户消息，编辑后的消息作为原消息的兄弟分支保存并流式生成新的回复，原分支保持不变",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Session"
+                    "Message"
                 ],
-                "summary": "获取用户会话",
+                "summary": "编辑消息并重新发送",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
+                        "type": "integer",
+                        "description": "用户消息 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "返回数据",
+                    },
+                    {
+                        "description": "编辑后的内容及参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_UserSession"
+                            "$ref": "#/definitions/chat.EditMessageStream.editInput"
                         }
                     }
-                }
+                ],
+                "responses": {}
             }
         },
-        "/chat/session/{session_id}": {
-            "get": {
-                "description": "获取会话",
+        "/chat/message/{id}/update": {
+            "post": {
+                "description": "更新消息（仅 extra 字段的增量合并更新）",
                 "consumes": [
                     "application/json"
                 ],
@@ -679,31 +817,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Message"
                 ],
-                "summary": "获取会话",
+                "summary": "更新消息",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "会话 ID",
-                        "name": "session_id",
+                        "description": "消息 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "更新的消息数据",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Message"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Session"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Message"
                         }
                     }
                 }
             }
         },
-        "/chat/session/{session_id}/shared": {
-            "get": {
-                "description": "获取已分享的用户会话信息（仅返回 Name）",
+        "/chat/session/branch/{session_id}": {
+            "post": {
+                "description": "选中会话中的某条消息所在分支，后续对话及上下文将沿该分支（该消息下最新的回复路径）继续",
                 "consumes": [
                     "application/json"
                 ],
@@ -713,7 +860,7 @@ const docTemplate = `{
                 "tags": [
                     "Session"
                 ],
-                "summary": "获取已分享的用户会话信息",
+                "summary": "切换会话分支",
                 "parameters": [
                     {
                         "type": "string",
@@ -723,30 +870,28 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "name": "code",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "尝试获取而不抛出错误",
-                        "name": "touch",
-                        "in": "query"
+                        "description": "分支消息",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.SwitchSessionBranch.BranchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "切换后分支末端消息 ID",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_UserSession"
+                            "$ref": "#/definitions/entity.CommonResponse-uint64"
                         }
                     }
                 }
             }
         },
-        "/manage/bucket/create": {
+        "/chat/session/del/{session_id}": {
             "post": {
-                "description": "创建 储存桶",
+                "description": "删除会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -754,33 +899,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bucket"
+                    "Session"
                 ],
-                "summary": "创建 储存桶",
+                "summary": "删除会话",
                 "parameters": [
                     {
-                        "description": "储存桶参数",
-                        "name": "bucket",
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/session/flag/{session_id}": {
+            "post": {
+                "description": "更新用户会话标记",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "更新用户会话标记",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "会话信息",
+                        "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Bucket"
+                            "$ref": "#/definitions/schema.SessionFlagInfo"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的 储存桶",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Bucket"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/bucket/list": {
+        "/chat/session/list": {
             "get": {
-                "description": "批量获取 储存桶",
+                "description": "获取会话列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -788,9 +972,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bucket"
+                    "Session"
                 ],
-                "summary": "批量获取 储存桶",
+                "summary": "获取会话列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -821,49 +1005,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "储存桶列表",
-                        "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Bucket"
-                        }
-                    }
-                }
-            }
-        },
-        "/manage/bucket/{id}": {
-            "get": {
-                "description": "获取 储存桶",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Bucket"
-                ],
-                "summary": "获取 储存桶",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "储存桶 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "储存桶",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Bucket"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedContinuationResponse-schema_UserSession"
                         }
                     }
                 }
             }
         },
-        "/manage/bucket/{id}/delete": {
+        "/chat/session/new": {
             "post": {
-                "description": "删除 储存桶",
+                "description": "创建会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -871,31 +1023,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bucket"
-                ],
-                "summary": "删除 储存桶",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "储存桶 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Session"
                 ],
+                "summary": "创建会话",
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-string"
                         }
                     }
                 }
             }
         },
-        "/manage/bucket/{id}/update": {
+        "/chat/session/share/{session_id}": {
             "post": {
-                "description": "更新 储存桶",
+                "description": "分享会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -903,30 +1046,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bucket"
+                    "Session"
                 ],
-                "summary": "更新 储存桶",
+                "summary": "分享会话",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "储存桶 ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "储存桶参数",
-                        "name": "bucket",
+                        "description": "分享信息",
+                        "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Bucket"
+                            "$ref": "#/definitions/chat.ShareSession.ShareRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -934,43 +1077,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/collection/create": {
-            "post": {
-                "description": "创建模型集合",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ModelCollection"
-                ],
-                "summary": "创建模型集合",
-                "parameters": [
-                    {
-                        "description": "模型集合",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.ModelCollection"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "成功创建的模型集合",
-                        "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_ModelCollection"
-                        }
-                    }
-                }
-            }
-        },
-        "/manage/collection/list": {
+        "/chat/session/sync": {
             "get": {
-                "description": "批量获取模型集合",
+                "description": "同步会话列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -978,15 +1087,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ModelCollection"
+                    "Session"
                 ],
-                "summary": "批量获取模型集合",
+                "summary": "同步会话列表",
                 "parameters": [
                     {
                         "type": "integer",
                         "name": "end_time",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "description": "客户端上次同步时间戳",
+                        "name": "last_sync_time",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "分页参数",
@@ -1011,17 +1127,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "模型集合列表",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ModelCollection"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedSyncListResponse-schema_UserSession"
                         }
                     }
                 }
             }
         },
-        "/manage/collection/{id}": {
-            "get": {
-                "description": "获取模型集合",
+        "/chat/session/update/{session_id}": {
+            "post": {
+                "description": "更新会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -1029,31 +1145,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ModelCollection"
+                    "Session"
                 ],
-                "summary": "获取模型集合",
+                "summary": "更新会话",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ModelCollection ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "会话信息",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Session"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "模型",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_ModelCollection"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/collection/{id}/delete": {
-            "post": {
-                "description": "删除模型集合",
+        "/chat/session/user/{session_id}": {
+            "get": {
+                "description": "获取用户会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -1061,31 +1186,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "Session"
                 ],
-                "summary": "删除模型集合",
+                "summary": "获取用户会话",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ModelCollection ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_UserSession"
                         }
                     }
                 }
             }
         },
-        "/manage/collection/{id}/update": {
-            "post": {
-                "description": "更新模型集合",
+        "/chat/session/{session_id}": {
+            "get": {
+                "description": "获取会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -1093,40 +1218,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ModelCollection"
+                    "Session"
                 ],
-                "summary": "更新模型集合",
+                "summary": "获取会话",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "模型集合 ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "模型集合更新参数",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_ModelCollection"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功更新与否",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Session"
                         }
                     }
                 }
             }
         },
-        "/manage/key/create": {
-            "post": {
-                "description": "创建 APIKey 并绑定 到 API 提供商",
+        "/chat/session/{session_id}/shared": {
+            "get": {
+                "description": "获取已分享的用户会话信息（仅返回 Name）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1134,33 +1250,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "APIKey"
+                    "Session"
                 ],
-                "summary": "创建 APIKey",
+                "summary": "获取已分享的用户会话信息",
                 "parameters": [
                     {
-                        "description": "API Key",
-                        "name": "apikey",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.APIKey"
-                        }
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "尝试获取而不抛出错误",
+                        "name": "touch",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的 API Key",
+                        "description": "返回数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_APIKey"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_UserSession"
                         }
                     }
                 }
             }
         },
-        "/manage/key/list/provider/{id}": {
+        "/file/list": {
             "get": {
-                "description": "列出供应商的 APIKey",
+                "description": "获取当前用户上传完成的文件",
                 "consumes": [
                     "application/json"
                 ],
@@ -1168,17 +1293,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "APIKey"
+                    "File"
                 ],
-                "summary": "列出APIKey",
+                "summary": "获取文件列表",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "API 提供商 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "integer",
                         "name": "end_time",
@@ -1204,53 +1322,86 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "start_time",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件所属模块",
+                        "name": "module",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的 API Key",
+                        "description": "文件列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_APIKey"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_File"
                         }
                     }
                 }
             }
         },
-        "/manage/key/{id}/delete": {
+        "/file/upload": {
             "post": {
-                "description": "删除 APIKey",
+                "description": "通过接口直接上传文件，文件类型以内容嗅探结果为准，大小及类型限制见 /file/upload/policy",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "APIKey"
+                    "File"
                 ],
-                "summary": "删除 APIKey",
+                "summary": "上传文件",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "API Key ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件所属模块，如 chat",
+                        "name": "module",
+                        "in": "formData",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "上传的文件",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_File"
                         }
                     }
                 }
             }
         },
-        "/manage/model/create": {
+        "/file/upload/policy": {
+            "get": {
+                "description": "返回允许上传的模块及各模块的文件大小、类型限制，未列出的模块不允许上传",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "获取上传限制",
+                "responses": {
+                    "200": {
+                        "description": "模块 -\u003e 上传限制",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-map_string_services_UploadPolicy"
+                        }
+                    }
+                }
+            }
+        },
+        "/file/upload/presign": {
             "post": {
-                "description": "创建模型并绑定到 API 供应商",
+                "description": "校验声明的文件大小及类型后签发上传链接，客户端上传后需调用确认接口，超过 24 小时未确认的上传会被清理",
                 "consumes": [
                     "application/json"
                 ],
@@ -1258,33 +1409,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "File"
                 ],
-                "summary": "创建模型",
+                "summary": "获取预签名上传链接",
                 "parameters": [
                     {
-                        "description": "模型",
-                        "name": "model",
+                        "description": "文件信息",
+                        "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Model"
+                            "$ref": "#/definitions/base.PresignUpload.presignRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的模型",
+                        "description": "上传链接",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Model"
+                            "$ref": "#/definitions/entity.CommonResponse-services_PresignedUpload"
                         }
                     }
                 }
             }
         },
-        "/manage/model/delete/{model_id}": {
-            "post": {
-                "description": "删除模型",
+        "/file/{id}": {
+            "get": {
+                "description": "获取当前用户上传的文件及临时下载链接",
                 "consumes": [
                     "application/json"
                 ],
@@ -1292,31 +1443,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "File"
                 ],
-                "summary": "删除模型",
+                "summary": "获取文件",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Model ID",
-                        "name": "model_id",
+                        "description": "文件 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "文件",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_File"
                         }
                     }
                 }
             }
         },
-        "/manage/model/list": {
-            "get": {
-                "description": "批量获取模型",
+        "/file/{id}/complete": {
+            "post": {
+                "description": "以实际上传的内容校验文件大小及类型，不符合限制时文件会被删除",
                 "consumes": [
                     "application/json"
                 ],
@@ -1324,50 +1475,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "File"
                 ],
-                "summary": "批量获取模型",
+                "summary": "确认预签名上传",
                 "parameters": [
                     {
                         "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "分页参数",
-                        "name": "page_num",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "sort_expr",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "start_time",
-                        "in": "query"
+                        "description": "文件 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "模型列表",
+                        "description": "上传完成的文件",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_File"
                         }
                     }
                 }
             }
         },
-        "/manage/model/provider/{provider_id}": {
-            "get": {
-                "description": "获取 API 提供商的模型",
+        "/file/{id}/delete": {
+            "post": {
+                "description": "删除当前用户上传的文件，引用该文件的消息将不再显示附件",
                 "consumes": [
                     "application/json"
                 ],
@@ -1375,80 +1507,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "File"
                 ],
-                "summary": "获取 API 提供商的模型",
+                "summary": "删除文件",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Provider ID",
-                        "name": "provider_id",
+                        "description": "文件 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "name": "end_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "分页参数",
-                        "name": "page_num",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "sort_expr",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "start_time",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "模型列表",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/model/refresh": {
-            "post": {
-                "description": "更新所有模型缓存，生产环境高危操作",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+        "/file/{id}/download": {
+            "get": {
+                "description": "重定向到文件的临时下载链接",
                 "tags": [
-                    "Model"
+                    "File"
+                ],
+                "summary": "下载文件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文件 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "manageModelGroup",
                 "responses": {
-                    "200": {
-                        "description": "更新成功与否",
-                        "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
-                        }
+                    "302": {
+                        "description": "Found"
                     }
                 }
             }
         },
-        "/manage/model/update": {
+        "/manage/bucket/create": {
             "post": {
-                "description": "Update 更新模型，若参数不传入或为空，则不会更新",
+                "description": "创建 储存桶",
                 "consumes": [
                     "application/json"
                 ],
@@ -1456,33 +1562,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "Bucket"
                 ],
-                "summary": "更新模型",
+                "summary": "创建 储存桶",
                 "parameters": [
                     {
-                        "description": "模型",
-                        "name": "model",
+                        "description": "储存桶参数",
+                        "name": "bucket",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Model"
+                            "$ref": "#/definitions/schema.Bucket"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "成功创建的 储存桶",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Bucket"
                         }
                     }
                 }
             }
         },
-        "/manage/model/{model_id}": {
+        "/manage/bucket/list": {
             "get": {
-                "description": "获取模型",
+                "description": "批量获取 储存桶",
                 "consumes": [
                     "application/json"
                 ],
@@ -1490,41 +1596,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "Bucket"
                 ],
-                "summary": "获取模型",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Model ID",
-                        "name": "model_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "模型",
-                        "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Model"
-                        }
-                    }
-                }
-            }
-        },
-        "/manage/permission/list": {
-            "get": {
-                "description": "批量分页获取权限",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Permission"
-                ],
-                "summary": "批量分页获取权限",
+                "summary": "批量获取 储存桶",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1555,17 +1629,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "权限列表",
+                        "description": "储存桶列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Permission"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Bucket"
                         }
                     }
                 }
             }
         },
-        "/manage/permission/{id}": {
+        "/manage/bucket/{id}": {
             "get": {
-                "description": "获取权限",
+                "description": "获取 储存桶",
                 "consumes": [
                     "application/json"
                 ],
@@ -1573,13 +1647,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "Bucket"
                 ],
-                "summary": "获取权限",
+                "summary": "获取 储存桶",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "权限 ID",
+                        "description": "储存桶 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1587,17 +1661,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "权限",
+                        "description": "储存桶",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Permission"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Bucket"
                         }
                     }
                 }
             }
         },
-        "/manage/permission/{name}/update": {
+        "/manage/bucket/{id}/delete": {
             "post": {
-                "description": "更新权限",
+                "description": "删除 储存桶",
                 "consumes": [
                     "application/json"
                 ],
@@ -1605,30 +1679,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "Bucket"
                 ],
-                "summary": "更新权限",
+                "summary": "删除 储存桶",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "权限 name",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "储存桶 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "权限参数",
-                        "name": "permission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Permission"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "删除成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -1636,9 +1701,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/provider/all": {
-            "get": {
-                "description": "获取所有 API 提供商",
+        "/manage/bucket/{id}/update": {
+            "post": {
+                "description": "更新 储存桶",
                 "consumes": [
                     "application/json"
                 ],
@@ -1646,22 +1711,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "Bucket"
+                ],
+                "summary": "更新 储存桶",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "储存桶 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "储存桶参数",
+                        "name": "bucket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Bucket"
+                        }
+                    }
                 ],
-                "summary": "获取所有 API 提供商",
                 "responses": {
                     "200": {
-                        "description": "API 提供商列表",
+                        "description": "更新成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-array_schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/provider/create": {
+        "/manage/collection/create": {
             "post": {
-                "description": "创建 API 提供商",
+                "description": "创建模型集合",
                 "consumes": [
                     "application/json"
                 ],
@@ -1669,33 +1752,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "ModelCollection"
                 ],
-                "summary": "创建 API 提供商",
+                "summary": "创建模型集合",
                 "parameters": [
                     {
-                        "description": "API 提供商参数",
-                        "name": "provider",
+                        "description": "模型集合",
+                        "name": "model",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Provider"
+                            "$ref": "#/definitions/schema.ModelCollection"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的 API 提供商",
+                        "description": "成功创建的模型集合",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_ModelCollection"
                         }
                     }
                 }
             }
         },
-        "/manage/provider/list": {
+        "/manage/collection/list": {
             "get": {
-                "description": "批量获取 API 提供商",
+                "description": "批量获取模型集合",
                 "consumes": [
                     "application/json"
                 ],
@@ -1703,9 +1786,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "ModelCollection"
                 ],
-                "summary": "批量获取 API 提供商",
+                "summary": "批量获取模型集合",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1736,17 +1819,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "API 提供商列表",
+                        "description": "模型集合列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ModelCollection"
                         }
                     }
                 }
             }
         },
-        "/manage/provider/{id}/delete": {
-            "post": {
-                "description": "删除 API 提供商",
+        "/manage/collection/{id}": {
+            "get": {
+                "description": "获取模型集合",
                 "consumes": [
                     "application/json"
                 ],
@@ -1754,13 +1837,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "ModelCollection"
                 ],
-                "summary": "删除 API 提供商",
+                "summary": "获取模型集合",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "API 提供商 ID",
+                        "description": "ModelCollection ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1768,17 +1851,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "模型",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_ModelCollection"
                         }
                     }
                 }
             }
         },
-        "/manage/provider/{id}/update": {
+        "/manage/collection/{id}/delete": {
             "post": {
-                "description": "更新 API 提供商",
+                "description": "删除模型集合",
                 "consumes": [
                     "application/json"
                 ],
@@ -1786,30 +1869,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "Model"
                 ],
-                "summary": "更新 API 提供商",
+                "summary": "删除模型集合",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "API 提供商 ID",
+                        "description": "ModelCollection ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "API 提供商参数",
-                        "name": "provider",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Provider"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "删除成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -1817,9 +1891,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/provider/{provider_id}": {
+        "/manage/collection/{id}/stats": {
             "get": {
-                "description": "获取 API 提供商",
+                "description": "获取集合内各模型的实时调用统计（请求数、错误数、延迟及错误率的滑动平均），用于观察负载均衡效果",
                 "consumes": [
                     "application/json"
                 ],
@@ -1827,31 +1901,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Provider"
+                    "ModelCollection"
                 ],
-                "summary": "获取 API 提供商",
+                "summary": "获取模型集合的实时统计",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "API 提供商 ID",
-                        "name": "provider_id",
+                        "description": "ModelCollection ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "API 提供商",
+                        "description": "模型 ID -\u003e 统计数据",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-map_uint64_redis_ModelStats"
                         }
                     }
                 }
             }
         },
-        "/manage/role/create": {
+        "/manage/collection/{id}/update": {
             "post": {
-                "description": "创建角色",
+                "description": "更新模型集合",
                 "consumes": [
                     "application/json"
                 ],
@@ -1859,33 +1933,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "ModelCollection"
                 ],
-                "summary": "创建角色",
+                "summary": "更新模型集合",
                 "parameters": [
                     {
-                        "description": "角色参数",
-                        "name": "role",
+                        "type": "integer",
+                        "description": "模型集合 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模型集合更新参数",
+                        "name": "model",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Role"
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_ModelCollection"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的角色",
+                        "description": "成功更新与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/role/list": {
+        "/manage/key/create": {
+            "post": {
+                "description": "创建 APIKey 并绑定 到 API 提供商",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIKey"
+                ],
+                "summary": "创建 APIKey",
+                "parameters": [
+                    {
+                        "description": "API Key",
+                        "name": "apikey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.APIKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建的 API Key",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_APIKey"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/key/list/provider/{id}": {
             "get": {
-                "description": "批量分页获取角色",
+                "description": "列出供应商的 APIKey，并附带健康状态（active/cooling/disabled）、连续失败次数、冷却结束时间及各类失败的累计次数",
                 "consumes": [
                     "application/json"
                 ],
@@ -1893,10 +2008,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "APIKey"
                 ],
-                "summary": "批量分页获取角色",
+                "summary": "列出APIKey",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API 提供商 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "name": "end_time",
@@ -1926,17 +2048,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "角色列表",
+                        "description": "成功创建的 API Key",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Role"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_APIKey"
                         }
                     }
                 }
             }
         },
-        "/manage/role/{id}": {
-            "get": {
-                "description": "获取角色",
+        "/manage/key/{id}/delete": {
+            "post": {
+                "description": "删除 APIKey",
                 "consumes": [
                     "application/json"
                 ],
@@ -1944,45 +2066,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "APIKey"
                 ],
-                "summary": "获取角色",
+                "summary": "删除 APIKey",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "角色",
-                        "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Role"
-                        }
-                    }
-                }
-            }
-        },
-        "/manage/role/{id}/delete": {
-            "post": {
-                "description": "删除角色",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Role"
-                ],
-                "summary": "删除角色",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "角色 ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1998,9 +2088,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/role/{id}/update": {
+        "/manage/key/{id}/enable": {
             "post": {
-                "description": "更新角色",
+                "description": "重新启用因鉴权失败而被自动停用的 APIKey，并清除其健康数据",
                 "consumes": [
                     "application/json"
                 ],
@@ -2008,30 +2098,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "APIKey"
                 ],
-                "summary": "更新角色",
+                "summary": "启用 APIKey",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色 ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "角色参数",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Role"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "启用成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -2039,9 +2120,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/schedule/list": {
+        "/manage/knowledge/list": {
             "get": {
-                "description": "批量获取 定时任务",
+                "description": "分页获取全部用户的知识库，可按创建者筛选",
                 "consumes": [
                     "application/json"
                 ],
@@ -2049,9 +2130,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
+                    "Knowledge"
                 ],
-                "summary": "批量获取 定时任务",
+                "summary": "分页获取知识库",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2078,21 +2159,27 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "start_time",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "创建者用户 ID",
+                        "name": "owner_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "定时任务列表",
+                        "description": "知识库列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Schedule"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_KnowledgeBase"
                         }
                     }
                 }
             }
         },
-        "/manage/schedule/{name}": {
-            "get": {
-                "description": "获取 定时任务",
+        "/manage/knowledge/{id}/delete": {
+            "post": {
+                "description": "删除任意知识库及其全部文档",
                 "consumes": [
                     "application/json"
                 ],
@@ -2100,31 +2187,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
+                    "Knowledge"
                 ],
-                "summary": "获取 定时任务",
+                "summary": "删除知识库",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "定时任务 name",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "定时任务",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Schedule"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/schedule/{name}/run": {
+        "/manage/knowledge/{id}/update": {
             "post": {
-                "description": "立即运行 定时任务",
+                "description": "更新任意知识库，可设置是否对所有用户公开",
                 "consumes": [
                     "application/json"
                 ],
@@ -2132,21 +2219,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
+                    "Knowledge"
                 ],
-                "summary": "立即运行 定时任务",
+                "summary": "更新知识库",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "定时任务 name",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "知识库 ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "知识库参数",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_KnowledgeBase"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功与否",
+                        "description": "更新成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -2154,9 +2250,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/schedule/{name}/update": {
+        "/manage/model/create": {
             "post": {
-                "description": "更新 定时任务",
+                "description": "创建模型并绑定到 API 供应商",
                 "consumes": [
                     "application/json"
                 ],
@@ -2164,40 +2260,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
+                    "Model"
                 ],
-                "summary": "更新 定时任务",
+                "summary": "创建模型",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "定时任务 name",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "定时任务参数",
-                        "name": "schedule",
+                        "description": "模型",
+                        "name": "model",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Schedule"
+                            "$ref": "#/definitions/schema.Model"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "成功创建的模型",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Model"
                         }
                     }
                 }
             }
         },
-        "/manage/user/create": {
+        "/manage/model/delete/{model_id}": {
             "post": {
-                "description": "创建用户",
+                "description": "删除模型",
                 "consumes": [
                     "application/json"
                 ],
@@ -2205,33 +2294,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Model"
                 ],
-                "summary": "创建用户",
+                "summary": "删除模型",
                 "parameters": [
                     {
-                        "description": "用户参数",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.User"
-                        }
+                        "type": "integer",
+                        "description": "Model ID",
+                        "name": "model_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的用户",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/user/list": {
+        "/manage/model/list": {
             "get": {
-                "description": "批量分页获取用户",
+                "description": "批量获取模型",
                 "consumes": [
                     "application/json"
                 ],
@@ -2239,9 +2326,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Model"
                 ],
-                "summary": "批量分页获取用户",
+                "summary": "批量获取模型",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2272,17 +2359,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户列表",
+                        "description": "模型列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model"
                         }
                     }
                 }
             }
         },
-        "/manage/user/{id}": {
+        "/manage/model/provider/{provider_id}": {
             "get": {
-                "description": "获取用户",
+                "description": "获取 API 提供商的模型",
                 "consumes": [
                     "application/json"
                 ],
@@ -2290,31 +2377,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Model"
                 ],
-                "summary": "获取用户",
+                "summary": "获取 API 提供商的模型",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户 ID",
-                        "name": "id",
+                        "description": "Provider ID",
+                        "name": "provider_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户",
+                        "description": "模型列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model"
                         }
                     }
                 }
             }
         },
-        "/manage/user/{id}/delete": {
+        "/manage/model/refresh": {
             "post": {
-                "description": "删除用户",
+                "description": "更新所有模型缓存，生产环境高危操作",
                 "consumes": [
                     "application/json"
                 ],
@@ -2322,21 +2435,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
-                ],
-                "summary": "删除用户",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "用户 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Model"
                 ],
+                "summary": "manageModelGroup",
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "更新成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -2344,9 +2448,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manage/user/{id}/logout": {
+        "/manage/model/update": {
             "post": {
-                "description": "强制登出用户",
+                "description": "Update 更新模型，若参数不传入或为空，则不会更新",
                 "consumes": [
                     "application/json"
                 ],
@@ -2354,31 +2458,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Model"
                 ],
-                "summary": "强制登出用户",
+                "summary": "更新模型",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "用户 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "模型",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Model"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功登出的设备数量",
+                        "description": "更新成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-int"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/manage/user/{id}/update": {
-            "post": {
-                "description": "更新用户",
+        "/manage/model/{model_id}": {
+            "get": {
+                "description": "获取模型",
                 "consumes": [
                     "application/json"
                 ],
@@ -2386,40 +2492,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Model"
                 ],
-                "summary": "更新用户",
+                "summary": "获取模型",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户 ID",
-                        "name": "id",
+                        "description": "Model ID",
+                        "name": "model_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "用户参数",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.User"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "模型",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Model"
                         }
                     }
                 }
             }
         },
-        "/preset/create": {
-            "post": {
-                "description": "创建一个新的预设，包含名称、描述和引用的会话ID",
+        "/manage/model/{model_id}/health": {
+            "get": {
+                "description": "获取模型的连通性检测结果（由定时任务 detect_model_connection 定期检测）",
                 "consumes": [
                     "application/json"
                 ],
@@ -2427,33 +2524,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preset"
+                    "Model"
                 ],
-                "summary": "创建预设",
+                "summary": "获取模型健康状态",
                 "parameters": [
                     {
-                        "description": "预设信息",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Preset"
-                        }
+                        "type": "integer",
+                        "description": "Model ID",
+                        "name": "model_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回的记录数，默认 50，最大 500",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建的预设",
+                        "description": "健康状态",
                         "schema": {
-                            "$ref": "#/definitions/schema.Preset"
+                            "$ref": "#/definitions/entity.CommonResponse-manage_ModelHealthResponse"
                         }
                     }
                 }
             }
         },
-        "/preset/list": {
+        "/manage/permission/list": {
             "get": {
-                "description": "获取所有预设的列表",
+                "description": "批量分页获取权限",
                 "consumes": [
                     "application/json"
                 ],
@@ -2461,25 +2562,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preset"
+                    "Permission"
+                ],
+                "summary": "批量分页获取权限",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取预设列表",
                 "responses": {
                     "200": {
-                        "description": "预设列表",
+                        "description": "权限列表",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/schema.Preset"
-                            }
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Permission"
                         }
                     }
                 }
             }
         },
-        "/preset/{id}": {
+        "/manage/permission/{id}": {
             "get": {
-                "description": "根据ID获取指定的预设信息",
+                "description": "获取权限",
                 "consumes": [
                     "application/json"
                 ],
@@ -2487,13 +2613,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preset"
+                    "Permission"
                 ],
-                "summary": "获取预设",
+                "summary": "获取权限",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "预设ID",
+                        "description": "权限 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2501,17 +2627,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "预设信息",
+                        "description": "权限",
                         "schema": {
-                            "$ref": "#/definitions/schema.Preset"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Permission"
                         }
                     }
                 }
             }
         },
-        "/preset/{id}/delete": {
+        "/manage/permission/{name}/update": {
             "post": {
-                "description": "删除指定ID的预设",
+                "description": "更新权限",
                 "consumes": [
                     "application/json"
                 ],
@@ -2519,31 +2645,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preset"
+                    "Permission"
                 ],
-                "summary": "删除预设",
+                "summary": "更新权限",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "预设ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "权限 name",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "权限参数",
+                        "name": "permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Permission"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "更新成功与否",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/preset/{id}/update": {
+        "/manage/plan/assign/role": {
             "post": {
-                "description": "更新指定ID的预设信息",
+                "description": "为角色分配套餐，拥有多个角色的用户取优先级最高的套餐，额度变更在下次重置时生效",
                 "consumes": [
                     "application/json"
                 ],
@@ -2551,40 +2686,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preset"
+                    "Plan"
                 ],
-                "summary": "更新预设",
+                "summary": "为角色分配套餐",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "预设ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新的预设信息",
-                        "name": "role",
+                        "description": "分配参数",
+                        "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Preset"
+                            "$ref": "#/definitions/manage.AssignRolePlan.assignRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新后的预设信息",
+                        "description": "分配成功与否",
                         "schema": {
-                            "$ref": "#/definitions/schema.Preset"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/course/create": {
+        "/manage/plan/assign/user": {
             "post": {
-                "description": "创建课程基础参数，绑定或创建题目、资源",
+                "description": "为用户分配套餐，优先于角色套餐，立即按新套餐设置本月额度",
                 "consumes": [
                     "application/json"
                 ],
@@ -2592,33 +2720,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Course"
+                    "Plan"
                 ],
-                "summary": "创建课程",
+                "summary": "为用户分配套餐",
                 "parameters": [
                     {
-                        "description": "课程内容",
+                        "description": "分配参数",
                         "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Course"
+                            "$ref": "#/definitions/manage.AssignUserPlan.assignRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "分配成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/course/list": {
+        "/manage/plan/create": {
+            "post": {
+                "description": "创建订阅套餐，model_collections 为空表示可使用全部模型集合",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plan"
+                ],
+                "summary": "创建套餐",
+                "parameters": [
+                    {
+                        "description": "套餐参数",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Plan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建的套餐",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Plan"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/plan/list": {
             "get": {
-                "description": "获取课程列表",
+                "description": "分页获取订阅套餐",
                 "consumes": [
                     "application/json"
                 ],
@@ -2626,9 +2788,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Course"
+                    "Plan"
                 ],
-                "summary": "获取课程列表",
+                "summary": "分页获取套餐",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2659,17 +2821,40 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "套餐列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Course"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Plan"
                         }
                     }
                 }
             }
         },
-        "/tue/course/update": {
+        "/manage/plan/role/list": {
+            "get": {
+                "description": "获取全部角色的套餐分配",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plan"
+                ],
+                "summary": "获取角色套餐",
+                "responses": {
+                    "200": {
+                        "description": "角色套餐列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_schema_RolePlan"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/plan/unassign/role": {
             "post": {
-                "description": "更新课程基础参数，增量更新 题目、资源",
+                "description": "取消分配给角色的套餐",
                 "consumes": [
                     "application/json"
                 ],
@@ -2677,33 +2862,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Course"
+                    "Plan"
                 ],
-                "summary": "更新课程",
+                "summary": "取消角色套餐",
                 "parameters": [
                     {
-                        "description": "课程内容",
+                        "description": "角色",
                         "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Course"
+                            "$ref": "#/definitions/manage.UnassignRolePlan.unassignRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "取消成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/course/{id}": {
+        "/manage/plan/unassign/user": {
+            "post": {
+                "description": "取消直接分配给用户的套餐，下月起按角色套餐或默认套餐重置额度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plan"
+                ],
+                "summary": "取消用户套餐",
+                "parameters": [
+                    {
+                        "description": "用户",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/manage.UnassignUserPlan.unassignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/plan/user/{id}": {
             "get": {
-                "description": "获取单个课程",
+                "description": "获取用户当前生效的套餐，未配置任何套餐时为空",
                 "consumes": [
                     "application/json"
                 ],
@@ -2711,13 +2930,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Course"
+                    "Plan"
                 ],
-                "summary": "获取单个课程",
+                "summary": "获取用户套餐",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "课程 ID",
+                        "type": "integer",
+                        "description": "用户 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2725,17 +2944,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "当前套餐",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Plan"
                         }
                     }
                 }
             }
         },
-        "/tue/course/{id}/delete": {
+        "/manage/plan/{id}/delete": {
             "post": {
-                "description": "删除课程",
+                "description": "删除订阅套餐，使用该套餐的用户及角色回退到其它套餐",
                 "consumes": [
                     "application/json"
                 ],
@@ -2743,13 +2962,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Course"
+                    "Plan"
                 ],
-                "summary": "删除课程",
+                "summary": "删除套餐",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "课程 ID",
+                        "type": "integer",
+                        "description": "套餐 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2757,17 +2976,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-any"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/exam-record/list": {
+        "/manage/plan/{id}/update": {
             "post": {
-                "description": "分页获取用户的考试评分结果",
+                "description": "更新订阅套餐，月度额度的变更在下次重置时生效",
                 "consumes": [
                     "application/json"
                 ],
@@ -2775,9 +2994,107 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "考试"
+                    "Plan"
                 ],
-                "summary": "分页获取考试结果",
+                "summary": "更新套餐",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "套餐 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "套餐参数",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Plan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/provider/all": {
+            "get": {
+                "description": "获取所有 API 提供商",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Provider"
+                ],
+                "summary": "获取所有 API 提供商",
+                "responses": {
+                    "200": {
+                        "description": "API 提供商列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_schema_Provider"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/provider/create": {
+            "post": {
+                "description": "创建 API 提供商",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Provider"
+                ],
+                "summary": "创建 API 提供商",
+                "parameters": [
+                    {
+                        "description": "API 提供商参数",
+                        "name": "provider",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Provider"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建的 API 提供商",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/provider/list": {
+            "get": {
+                "description": "批量获取 API 提供商",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Provider"
+                ],
+                "summary": "批量获取 API 提供商",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2804,29 +3121,21 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "start_time",
                         "in": "query"
-                    },
-                    {
-                        "description": "查询信息",
-                        "name": "search",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/course.GetExamResults.Search"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "API 提供商列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Provider"
                         }
                     }
                 }
             }
         },
-        "/tue/exam-record/{id}": {
-            "get": {
-                "description": "获取用户的考试评分结果",
+        "/manage/provider/{id}/delete": {
+            "post": {
+                "description": "删除 API 提供商",
                 "consumes": [
                     "application/json"
                 ],
@@ -2834,13 +3143,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "考试"
+                    "Provider"
                 ],
-                "summary": "获取考试结果",
+                "summary": "删除 API 提供商",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "考试记录ID",
+                        "description": "API 提供商 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2848,17 +3157,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_ExamUserRecord"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/exam-record/{id}/rescore": {
+        "/manage/provider/{id}/update": {
             "post": {
-                "description": "管理员重新评分考试",
+                "description": "更新 API 提供商",
                 "consumes": [
                     "application/json"
                 ],
@@ -2866,24 +3175,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "考试"
+                    "Provider"
                 ],
-                "summary": "重新评分考试",
+                "summary": "更新 API 提供商",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "考试记录ID",
+                        "description": "API 提供商 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "API 提供商参数",
+                        "name": "provider",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Provider"
+                        }
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "更新成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
             }
         },
-        "/tue/exam/create": {
-            "post": {
-                "description": "保存单个测验",
+        "/manage/provider/{provider_id}": {
+            "get": {
+                "description": "获取 API 提供商",
                 "consumes": [
                     "application/json"
                 ],
@@ -2891,33 +3216,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exam"
+                    "Provider"
                 ],
-                "summary": "保存单个测验",
+                "summary": "获取 API 提供商",
                 "parameters": [
                     {
-                        "description": "测验内容",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Exam"
-                        }
+                        "type": "integer",
+                        "description": "API 提供商 ID",
+                        "name": "provider_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "API 提供商",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/random": {
+        "/manage/role/create": {
             "post": {
-                "description": "随机测验",
+                "description": "创建角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -2925,22 +3248,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exam"
+                    "Role"
+                ],
+                "summary": "创建角色",
+                "parameters": [
+                    {
+                        "description": "角色参数",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Role"
+                        }
+                    }
                 ],
-                "summary": "随机测验",
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "成功创建的角色",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/single-problem-record/list": {
-            "post": {
-                "description": "获取单个问题结果列表",
+        "/manage/role/list": {
+            "get": {
+                "description": "批量分页获取角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -2948,9 +3282,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exam"
+                    "Role"
                 ],
-                "summary": "获取单个问题结果列表",
+                "summary": "批量分页获取角色",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2977,29 +3311,21 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "start_time",
                         "in": "query"
-                    },
-                    {
-                        "description": "查询信息",
-                        "name": "search",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/course.GetProblemResults.Search"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "角色列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ProblemUserRecord"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Role"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/single-problem/submit": {
-            "post": {
-                "description": "提交单个问题并验证答案",
+        "/manage/role/{id}": {
+            "get": {
+                "description": "获取角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -3007,33 +3333,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exam"
+                    "Role"
                 ],
-                "summary": "提交单个问题并验证答案",
+                "summary": "获取角色",
                 "parameters": [
                     {
-                        "description": "提交信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/course.SubmitExamRequest"
-                        }
+                        "type": "integer",
+                        "description": "角色 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "角色",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-course_SubmitProblemResponse"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Role"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/{id}": {
-            "get": {
-                "description": "获取单个测验",
+        "/manage/role/{id}/delete": {
+            "post": {
+                "description": "删除角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -3041,13 +3365,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exam"
+                    "Role"
                 ],
-                "summary": "获取单个测验",
+                "summary": "删除角色",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "测验 ID",
+                        "type": "integer",
+                        "description": "角色 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3055,17 +3379,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "删除成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/{id}/my-records": {
-            "get": {
-                "description": "分页获取用户的考试评分结果（单个考试）",
+        "/manage/role/{id}/update": {
+            "post": {
+                "description": "更新角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -3073,17 +3397,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "考试"
+                    "Role"
                 ],
-                "summary": "分页获取考试结果（单个考试）",
+                "summary": "更新角色",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "考试 ID",
+                        "type": "integer",
+                        "description": "角色 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "角色参数",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/manage/schedule/list": {
+            "get": {
+                "description": "批量获取 定时任务",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "批量获取 定时任务",
+                "parameters": [
                     {
                         "type": "integer",
                         "name": "end_time",
@@ -3113,17 +3471,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "定时任务列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Schedule"
                         }
                     }
                 }
             }
         },
-        "/tue/exam/{id}/submit": {
-            "post": {
-                "description": "提交用户的考试答案并进行评分",
+        "/manage/schedule/{name}": {
+            "get": {
+                "description": "获取 定时任务",
                 "consumes": [
                     "application/json"
                 ],
@@ -3131,40 +3489,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "考试"
+                    "Schedule"
                 ],
-                "summary": "提交考试答案",
+                "summary": "获取 定时任务",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "考试ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "定时任务 name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "提交信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "定时任务",
                         "schema": {
-                            "$ref": "#/definitions/course.SubmitExamRequest"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Schedule"
                         }
                     }
+                }
+            }
+        },
+        "/manage/schedule/{name}/run": {
+            "post": {
+                "description": "立即运行 定时任务",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "立即运行 定时任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "定时任务 name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-course_SubmitExamResponse"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/create": {
+        "/manage/schedule/{name}/update": {
             "post": {
-                "description": "创建单个题目",
+                "description": "更新 定时任务",
                 "consumes": [
                     "application/json"
                 ],
@@ -3172,33 +3553,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "Schedule"
                 ],
-                "summary": "创建单个题目",
+                "summary": "更新 定时任务",
                 "parameters": [
                     {
-                        "description": "题目结构",
-                        "name": "req",
+                        "type": "string",
+                        "description": "定时任务 name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "定时任务参数",
+                        "name": "schedule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.Problem"
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Schedule"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "更新成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/list": {
+        "/manage/usage/records": {
             "get": {
-                "description": "分页获取题目列表",
+                "description": "分页获取全部用户的用量流水，可按用户及来源筛选",
                 "consumes": [
                     "application/json"
                 ],
@@ -3206,9 +3594,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "Usage"
                 ],
-                "summary": "分页获取题目列表",
+                "summary": "分页获取用量流水",
                 "parameters": [
                     {
                         "type": "integer",
@@ -3235,21 +3623,33 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "start_time",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户 ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "来源：chat/openai/preset",
+                        "name": "source",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "用量流水",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Problem"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_UsageRecord"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/make": {
+        "/manage/user/create": {
             "post": {
-                "description": "创建题目",
+                "description": "创建用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3257,33 +3657,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "User"
                 ],
-                "summary": "创建题目",
+                "summary": "创建用户",
                 "parameters": [
                     {
-                        "description": "题目要求",
-                        "name": "req",
+                        "description": "用户参数",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/course.MakeQuestionRequest"
+                            "$ref": "#/definitions/schema.User"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "生成的题目",
+                        "description": "成功创建的用户",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Provider"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/{id}": {
+        "/manage/user/list": {
             "get": {
-                "description": "获取单个题目",
+                "description": "批量分页获取用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3291,31 +3691,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "User"
                 ],
-                "summary": "获取单个题目",
+                "summary": "批量分页获取用户",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "题目 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回数据",
+                        "description": "用户列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_User"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/{id}/delete": {
-            "post": {
-                "description": "删除题目",
+        "/manage/user/{id}": {
+            "get": {
+                "description": "获取用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3323,13 +3742,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "User"
                 ],
-                "summary": "删除题目",
+                "summary": "获取用户",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目 ID",
+                        "description": "用户 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3337,17 +3756,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功与否",
+                        "description": "用户",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
                         }
                     }
                 }
             }
         },
-        "/tue/problem/{id}/update": {
+        "/manage/user/{id}/delete": {
             "post": {
-                "description": "更新题目",
+                "description": "删除用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3355,30 +3774,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Problem"
+                    "User"
                 ],
-                "summary": "更新题目",
+                "summary": "删除用户",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "题目 ID",
+                        "description": "用户 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "题目参数",
-                        "name": "problem",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Problem"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功与否",
+                        "description": "删除成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
@@ -3386,9 +3796,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/backdoor/login": {
+        "/manage/user/{id}/logout": {
             "post": {
-                "description": "后门登录",
+                "description": "强制登出用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3398,31 +3808,29 @@ const docTemplate = `{
                 "tags": [
                     "User"
                 ],
-                "summary": "后门登录",
+                "summary": "强制登出用户",
                 "parameters": [
                     {
-                        "description": "登录请求",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.Login.loginRequest"
-                        }
+                        "type": "integer",
+                        "description": "用户 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "login successfully",
+                        "description": "成功登出的设备数量",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-int"
                         }
                     }
                 }
             }
         },
-        "/user/current": {
-            "get": {
-                "description": "获取当前用户信息",
+        "/manage/user/{id}/update": {
+            "post": {
+                "description": "更新用户",
                 "consumes": [
                     "application/json"
                 ],
@@ -3432,20 +3840,38 @@ const docTemplate = `{
                 "tags": [
                     "User"
                 ],
-                "summary": "获取当前用户信息",
+                "summary": "更新用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "用户参数",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.User"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "get current user info successfully",
+                        "description": "更新成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/user/login": {
+        "/manage/voucher/batch/create": {
             "post": {
-                "description": "用户登录",
+                "description": "批量生成兑换码，每个兑换码可被不同用户兑换 max_redemptions 次",
                 "consumes": [
                     "application/json"
                 ],
@@ -3453,33 +3879,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Voucher"
                 ],
-                "summary": "用户登录",
+                "summary": "生成兑换码批次",
                 "parameters": [
                     {
-                        "description": "登录请求",
+                        "description": "批次参数",
                         "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.Login.loginRequest"
+                            "$ref": "#/definitions/manage.CreateVoucherBatch.createRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "login successfully",
+                        "description": "生成的批次及兑换码",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-manage_CreateVoucherBatchResponse"
                         }
                     }
                 }
             }
         },
-        "/user/logout": {
-            "post": {
-                "description": "用户登出",
+        "/manage/voucher/batch/list": {
+            "get": {
+                "description": "分页获取兑换码批次",
                 "consumes": [
                     "application/json"
                 ],
@@ -3487,15 +3913,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Voucher"
                 ],
-                "summary": "用户登出",
-                "responses": {}
+                "summary": "分页获取兑换码批次",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "批次列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_VoucherBatch"
+                        }
+                    }
+                }
             }
         },
-        "/user/ping": {
-            "post": {
-                "description": "检测客户端登录态",
+        "/manage/voucher/batch/{id}/codes": {
+            "get": {
+                "description": "分页获取批次内的兑换码及兑换次数",
                 "consumes": [
                     "application/json"
                 ],
@@ -3503,54 +3964,152 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Voucher"
+                ],
+                "summary": "分页获取批次内的兑换码",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "批次 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
                 ],
-                "summary": "检测客户端登录态",
                 "responses": {
                     "200": {
-                        "description": "user is online",
+                        "description": "兑换码列表",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Voucher"
                         }
-                    },
-                    "404": {
-                        "description": "user not found",
+                    }
+                }
+            }
+        },
+        "/manage/voucher/batch/{id}/disable": {
+            "post": {
+                "description": "停用批次内的全部兑换码，已兑换的额度不受影响",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Voucher"
+                ],
+                "summary": "停用兑换码批次",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "批次 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "停用成功与否",
                         "schema": {
-                            "$ref": "#/definitions/entity.CommonResponse-any"
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
         },
-        "/user/refresh": {
+        "/manage/voucher/redemptions": {
             "get": {
-                "description": "刷新登录态",
+                "description": "分页获取兑换记录，可按用户及批次筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "User"
+                    "Voucher"
                 ],
-                "summary": "刷新登录态",
+                "summary": "分页获取兑换记录",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "刷新用 Token",
-                        "name": "X-Refresh-Token",
-                        "in": "header",
-                        "required": true
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户 ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "批次 ID",
+                        "name": "batch_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "nothing",
+                        "description": "兑换记录",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_VoucherRedemption"
                         }
                     }
                 }
             }
         },
-        "/user/register": {
+        "/manage/voucher/{id}/disable": {
             "post": {
-                "description": "用户注册",
+                "description": "停用单个兑换码，已兑换的额度不受影响",
                 "consumes": [
                     "application/json"
                 ],
@@ -3558,188 +4117,3034 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Voucher"
                 ],
-                "summary": "用户注册",
+                "summary": "停用兑换码",
                 "parameters": [
                     {
-                        "description": "注册请求",
-                        "name": "req",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.Register.registerRequest"
-                        }
+                        "type": "integer",
+                        "description": "兑换码 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "register successfully",
+                        "description": "停用成功与否",
                         "schema": {
                             "$ref": "#/definitions/entity.CommonResponse-bool"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "chat.ChatMessageListResponse": {
-            "type": "object",
-            "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Message"
-                    }
-                },
-                "model_map": {
-                    "description": "模型 ID -\u003e 模型名称",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "next_page": {
-                    "type": "integer"
-                }
-            }
         },
-        "chat.CompletionStream.userInput": {
+        "/preset/create": {
+            "post": {
+                "description": "创建一个新的预设，包含名称、描述和引用的会话ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preset"
+                ],
+                "summary": "创建预设",
+                "parameters": [
+                    {
+                        "description": "预设信息",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Preset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建的预设",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Preset"
+                        }
+                    }
+                }
+            }
+        },
+        "/preset/list": {
+            "get": {
+                "description": "获取所有预设的列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preset"
+                ],
+                "summary": "获取预设列表",
+                "responses": {
+                    "200": {
+                        "description": "预设列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schema.Preset"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/preset/{id}": {
+            "get": {
+                "description": "根据ID获取指定的预设信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preset"
+                ],
+                "summary": "获取预设",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "预设ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "预设信息",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Preset"
+                        }
+                    }
+                }
+            }
+        },
+        "/preset/{id}/delete": {
+            "post": {
+                "description": "删除指定ID的预设",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preset"
+                ],
+                "summary": "删除预设",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "预设ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    }
+                }
+            }
+        },
+        "/preset/{id}/update": {
+            "post": {
+                "description": "更新指定ID的预设信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preset"
+                ],
+                "summary": "更新预设",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "预设ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新的预设信息",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Preset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新后的预设信息",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Preset"
+                        }
+                    }
+                }
+            }
+        },
+        "/storage/object/{bucket_id}/{key}": {
+            "get": {
+                "description": "通过本地储存的签名链接读取文件内容，链接由文件接口签发",
+                "tags": [
+                    "File"
+                ],
+                "summary": "读取本地储存对象",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "储存桶 ID",
+                        "name": "bucket_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "对象路径",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名方法",
+                        "name": "method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "过期时间",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "description": "通过本地储存的预签名上传链接上传文件内容，上传后需调用确认接口",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "写入本地储存对象",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "储存桶 ID",
+                        "name": "bucket_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "对象路径",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名方法",
+                        "name": "method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "过期时间",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上传成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/course/create": {
+            "post": {
+                "description": "创建课程基础参数，绑定或创建题目、资源",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Course"
+                ],
+                "summary": "创建课程",
+                "parameters": [
+                    {
+                        "description": "课程内容",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Course"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/course/list": {
+            "get": {
+                "description": "获取课程列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Course"
+                ],
+                "summary": "获取课程列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Course"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/course/update": {
+            "post": {
+                "description": "更新课程基础参数，增量更新 题目、资源",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Course"
+                ],
+                "summary": "更新课程",
+                "parameters": [
+                    {
+                        "description": "课程内容",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Course"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/course/{id}": {
+            "get": {
+                "description": "获取单个课程",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Course"
+                ],
+                "summary": "获取单个课程",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "课程 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Course"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/course/{id}/delete": {
+            "post": {
+                "description": "删除课程",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Course"
+                ],
+                "summary": "删除课程",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "课程 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam-record/list": {
+            "post": {
+                "description": "分页获取用户的考试评分结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "考试"
+                ],
+                "summary": "分页获取考试结果",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "description": "查询信息",
+                        "name": "search",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/course.GetExamResults.Search"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam-record/{id}": {
+            "get": {
+                "description": "获取用户的考试评分结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "考试"
+                ],
+                "summary": "获取考试结果",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "考试记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_ExamUserRecord"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam-record/{id}/rescore": {
+            "post": {
+                "description": "管理员重新评分考试",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "考试"
+                ],
+                "summary": "重新评分考试",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "考试记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tue/exam/create": {
+            "post": {
+                "description": "保存单个测验",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exam"
+                ],
+                "summary": "保存单个测验",
+                "parameters": [
+                    {
+                        "description": "测验内容",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Exam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/random": {
+            "post": {
+                "description": "随机测验",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exam"
+                ],
+                "summary": "随机测验",
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/single-problem-record/list": {
+            "post": {
+                "description": "获取单个问题结果列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exam"
+                ],
+                "summary": "获取单个问题结果列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "description": "查询信息",
+                        "name": "search",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/course.GetProblemResults.Search"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ProblemUserRecord"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/single-problem/submit": {
+            "post": {
+                "description": "提交单个问题并验证答案",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exam"
+                ],
+                "summary": "提交单个问题并验证答案",
+                "parameters": [
+                    {
+                        "description": "提交信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/course.SubmitExamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-course_SubmitProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/{id}": {
+            "get": {
+                "description": "获取单个测验",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exam"
+                ],
+                "summary": "获取单个测验",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "测验 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Exam"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/{id}/my-records": {
+            "get": {
+                "description": "分页获取用户的考试评分结果（单个考试）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "考试"
+                ],
+                "summary": "分页获取考试结果（单个考试）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "考试 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/exam/{id}/submit": {
+            "post": {
+                "description": "提交用户的考试答案并进行评分",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "考试"
+                ],
+                "summary": "提交考试答案",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "考试ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "提交信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/course.SubmitExamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-course_SubmitExamResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/create": {
+            "post": {
+                "description": "创建单个题目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "创建单个题目",
+                "parameters": [
+                    {
+                        "description": "题目结构",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/list": {
+            "get": {
+                "description": "分页获取题目列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "分页获取题目列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/make": {
+            "post": {
+                "description": "创建题目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "创建题目",
+                "parameters": [
+                    {
+                        "description": "题目要求",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/course.MakeQuestionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "生成的题目",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/{id}": {
+            "get": {
+                "description": "获取单个题目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "获取单个题目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "题目 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回数据",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/{id}/delete": {
+            "post": {
+                "description": "删除题目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "删除题目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/tue/problem/{id}/update": {
+            "post": {
+                "description": "更新题目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Problem"
+                ],
+                "summary": "更新题目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "题目 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "题目参数",
+                        "name": "problem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReqUpdateBody-schema_Problem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/backdoor/login": {
+            "post": {
+                "description": "后门登录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "后门登录",
+                "parameters": [
+                    {
+                        "description": "登录请求",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.Login.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "login successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/current": {
+            "get": {
+                "description": "获取当前用户信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "获取当前用户信息",
+                "responses": {
+                    "200": {
+                        "description": "get current user info successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "用户登录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录请求",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.Login.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "login successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logout": {
+            "post": {
+                "description": "用户登出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "用户登出",
+                "responses": {}
+            }
+        },
+        "/user/ping": {
+            "post": {
+                "description": "检测客户端登录态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "检测客户端登录态",
+                "responses": {
+                    "200": {
+                        "description": "user is online",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_User"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/redeem": {
+            "post": {
+                "description": "兑换兑换码，额度计入当前用户余额；同一兑换码每个用户只能兑换一次，短时间内多次输入无效兑换码将被限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Voucher"
+                ],
+                "summary": "兑换兑换码",
+                "parameters": [
+                    {
+                        "description": "兑换码",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RedeemVoucher.redeemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "兑换记录",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-schema_VoucherRedemption"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/refresh": {
+            "get": {
+                "description": "刷新登录态",
+                "tags": [
+                    "User"
+                ],
+                "summary": "刷新登录态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "刷新用 Token",
+                        "name": "X-Refresh-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "nothing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "用户注册",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册请求",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.Register.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "register successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/token/create": {
+            "post": {
+                "description": "创建用于脚本及第三方集成的访问令牌，权限范围须为当前用户已拥有的权限路径，令牌明文仅返回一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccessToken"
+                ],
+                "summary": "创建个人访问令牌",
+                "parameters": [
+                    {
+                        "description": "令牌信息",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateAccessToken.createRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-user_CreateAccessTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/token/list": {
+            "get": {
+                "description": "获取当前用户的全部访问令牌，不包含令牌明文",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccessToken"
+                ],
+                "summary": "获取个人访问令牌列表",
+                "responses": {
+                    "200": {
+                        "description": "令牌列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_schema_AccessToken"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/token/scopes": {
+            "get": {
+                "description": "获取当前用户可授予访问令牌的权限路径",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccessToken"
+                ],
+                "summary": "获取可授予令牌的权限",
+                "responses": {
+                    "200": {
+                        "description": "权限路径列表",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-array_string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/token/{id}/revoke": {
+            "post": {
+                "description": "撤销当前用户的访问令牌，撤销后立即失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccessToken"
+                ],
+                "summary": "撤销个人访问令牌",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "令牌 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功与否",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/usage/quota": {
+            "get": {
+                "description": "获取当前用户的余额，以及每日、每月额度的使用情况和重置时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "获取剩余额度",
+                "responses": {
+                    "200": {
+                        "description": "额度信息",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-services_QuotaStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/usage/records": {
+            "get": {
+                "description": "分页获取当前用户的用量流水",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "分页获取用量流水",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页参数",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort_expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用量流水",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CommonResponse-entity_PaginatedTotalResponse-schema_UsageRecord"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/chat/completions": {
+            "post": {
+                "description": "接受 OpenAI 格式的请求，model 为模型集合名称，stream 为 true 时以 chat.completion.chunk 格式流式输出，用量计入当前用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "OpenAI"
+                ],
+                "summary": "OpenAI 兼容的补全接口",
+                "parameters": [
+                    {
+                        "description": "OpenAI 格式的补全请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.openAIChatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chat.openAIChatCompletion"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models": {
+            "get": {
+                "description": "列出可用的模型集合，id 可作为补全接口的 model 参数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenAI"
+                ],
+                "summary": "OpenAI 兼容的模型列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chat.openAIModelList"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "base.PresignUpload.presignRequest": {
+            "type": "object",
+            "required": [
+                "module",
+                "name",
+                "size",
+                "type"
+            ],
+            "properties": {
+                "module": {
+                    "description": "文件所属模块，如 chat",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "文件名",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "文件大小（字节）",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "文件的 MIME 类型",
+                    "type": "string"
+                }
+            }
+        },
+        "chat.ChatMessageListResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Message"
+                    }
+                },
+                "model_map": {
+                    "description": "模型 ID -\u003e 模型名称",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "next_page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.CompletionStream.userInput": {
+            "type": "object",
+            "required": [
+                "model_name",
+                "question"
+            ],
+            "properties": {
+                "bot_id": {
+                    "type": "integer"
+                },
+                "enable_context": {
+                    "type": "boolean"
+                },
+                "enable_search": {
+                    "description": "是否启用搜索",
+                    "type": "boolean"
+                },
+                "file_ids": {
+                    "description": "附件文件 ID",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "model_name": {
+                    "description": "模型集合名称",
+                    "type": "string"
+                },
+                "question": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "description": "系统提示词",
+                    "type": "string"
+                },
+                "tools": {
+                    "description": "本次可用的工具名称，不传则使用会话配置",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "chat.CreateKnowledgeBase.createRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "chunk_overlap": {
+                    "description": "相邻分段的重叠大小（token），默认为分段大小的 1/10",
+                    "type": "integer"
+                },
+                "chunk_size": {
+                    "description": "分段大小（token），默认 500，最大 2000",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "embedding_collection": {
+                    "description": "向量化模型集合，为空时使用默认集合",
+                    "type": "string"
+                },
+                "min_score": {
+                    "description": "余弦相似度阈值",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "top_k": {
+                    "description": "每次检索的分段数量，默认 5",
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.EditMessageStream.editInput": {
+            "type": "object",
+            "required": [
+                "model_name",
+                "question"
+            ],
+            "properties": {
+                "bot_id": {
+                    "type": "integer"
+                },
+                "enable_context": {
+                    "type": "boolean"
+                },
+                "enable_search": {
+                    "description": "是否启用搜索",
+                    "type": "boolean"
+                },
+                "file_ids": {
+                    "description": "编辑后的附件文件 ID，不传则沿用原消息附件",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "model_name": {
+                    "description": "模型集合名称",
+                    "type": "string"
+                },
+                "question": {
+                    "description": "编辑后的提问内容",
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "description": "系统提示词",
+                    "type": "string"
+                },
+                "tools": {
+                    "description": "本次可用的工具名称，不传则使用会话配置",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "chat.SearchKnowledgeBase.searchRequest": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.ShareSession.ShareRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "share_info": {
+                    "$ref": "#/definitions/schema.SessionShareInfo"
+                }
+            }
+        },
+        "chat.SwitchSessionBranch.BranchRequest": {
+            "type": "object",
+            "required": [
+                "message_id"
+            ],
+            "properties": {
+                "message_id": {
+                    "description": "分支上的任意消息 ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.completionParams": {
+            "type": "object",
+            "required": [
+                "model_name"
+            ],
+            "properties": {
+                "bot_id": {
+                    "type": "integer"
+                },
+                "enable_context": {
+                    "type": "boolean"
+                },
+                "enable_search": {
+                    "description": "是否启用搜索",
+                    "type": "boolean"
+                },
+                "model_name": {
+                    "description": "模型集合名称",
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "description": "系统提示词",
+                    "type": "string"
+                },
+                "tools": {
+                    "description": "本次可用的工具名称，不传则使用会话配置",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "chat.openAIChatCompletion": {
+            "type": "object",
+            "properties": {
+                "choices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.openAIChoice"
+                    }
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/chat.openAIUsage"
+                }
+            }
+        },
+        "chat.openAIChatMessage": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.openAIChatRequest": {
+            "type": "object",
+            "required": [
+                "messages",
+                "model"
+            ],
+            "properties": {
+                "max_completion_tokens": {
+                    "type": "integer"
+                },
+                "max_tokens": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/chat.openAIChatMessage"
+                    }
+                },
+                "model": {
+                    "type": "string"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "stream_options": {
+                    "type": "object",
+                    "properties": {
+                        "include_usage": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "temperature": {
+                    "type": "number"
+                }
+            }
+        },
+        "chat.openAIChoice": {
+            "type": "object",
+            "properties": {
+                "finish_reason": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "message": {
+                    "$ref": "#/definitions/chat.openAIResponseMessage"
+                }
+            }
+        },
+        "chat.openAIModel": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "owned_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.openAIModelList": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.openAIModel"
+                    }
+                },
+                "object": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.openAIResponseMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "reasoning_content": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.openAIUsage": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "course.ExamRecordSearch": {
+            "type": "object",
+            "properties": {
+                "everything": {
+                    "type": "string"
+                }
+            }
+        },
+        "course.GetExamResults.Search": {
+            "type": "object",
+            "properties": {
+                "search_data": {
+                    "$ref": "#/definitions/course.ExamRecordSearch"
+                }
+            }
+        },
+        "course.GetProblemResults.Search": {
+            "type": "object",
+            "properties": {
+                "search_data": {
+                    "$ref": "#/definitions/course.ProblemRecordSearch"
+                }
+            }
+        },
+        "course.MakeQuestionRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/schema.ProblemType"
+                }
+            }
+        },
+        "course.ProblemRecordSearch": {
+            "type": "object",
+            "properties": {
+                "everything": {
+                    "type": "string"
+                }
+            }
+        },
+        "course.SubmitExamRequest": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "description": "答案列表",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/course.SubmitExamRequestAnswer"
+                    }
+                },
+                "time_spent": {
+                    "description": "答题用时（秒）",
+                    "type": "integer"
+                }
+            }
+        },
+        "course.SubmitExamRequestAnswer": {
+            "type": "object",
+            "properties": {
+                "answer": {},
+                "problem_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "course.SubmitExamResponse": {
+            "type": "object",
+            "properties": {
+                "record_id": {
+                    "description": "记录ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "course.SubmitProblemResponse": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "datatypes.JSONType-array_string": {
+            "type": "object"
+        },
+        "entity.CommonResponse-any": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据"
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_entity_ConfigChatModel": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ConfigChatModel"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_AccessToken": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.AccessToken"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_KnowledgeBase": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.KnowledgeBase"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_KnowledgeDocument": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.KnowledgeDocument"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_Preset": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Preset"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_Provider": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Provider"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_schema_RolePlan": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.RolePlan"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_services_RetrievedChunk": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RetrievedChunk"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_services_ToolInfo": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ToolInfo"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-array_string": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-bool": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "type": "boolean"
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-chat_ChatMessageListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/chat.ChatMessageListResponse"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-course_SubmitExamResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/course.SubmitExamResponse"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-course_SubmitProblemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/course.SubmitProblemResponse"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedContinuationResponse-schema_UserSession": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedContinuationResponse-schema_UserSession"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedSyncListResponse-schema_UserSession": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedSyncListResponse-schema_UserSession"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_APIKey": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_APIKey"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Bucket": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Bucket"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Course": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Course"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ExamUserRecord"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_File": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_File"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_KnowledgeBase": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_KnowledgeBase"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Model"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ModelCollection": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ModelCollection"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Permission": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Permission"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Plan": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Plan"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Problem"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ProblemUserRecord": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ProblemUserRecord"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Provider": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Provider"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Role": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Role"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Schedule": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Schedule"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_UsageRecord": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_UsageRecord"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_User": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_User"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Voucher": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Voucher"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_VoucherBatch": {
             "type": "object",
-            "required": [
-                "model_name",
-                "question"
-            ],
             "properties": {
-                "bot_id": {
+                "code": {
+                    "description": "代码",
                     "type": "integer"
                 },
-                "enable_context": {
-                    "type": "boolean"
-                },
-                "enable_search": {
-                    "description": "是否启用搜索",
-                    "type": "boolean"
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_VoucherBatch"
+                        }
+                    ]
                 },
-                "model_name": {
-                    "description": "模型集合名称",
+                "msg": {
+                    "description": "消息",
                     "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_VoucherRedemption": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
                 },
-                "question": {
-                    "type": "string"
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_VoucherRedemption"
+                        }
+                    ]
                 },
-                "system_prompt": {
-                    "description": "系统提示词",
+                "msg": {
+                    "description": "消息",
                     "type": "string"
                 }
             }
         },
-        "chat.ShareSession.ShareRequest": {
+        "entity.CommonResponse-int": {
             "type": "object",
             "properties": {
-                "active": {
-                    "type": "boolean"
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
                 },
-                "share_info": {
-                    "$ref": "#/definitions/schema.SessionShareInfo"
+                "data": {
+                    "description": "数据",
+                    "type": "integer"
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.ExamRecordSearch": {
+        "entity.CommonResponse-manage_CreateVoucherBatchResponse": {
             "type": "object",
             "properties": {
-                "everything": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/manage.CreateVoucherBatchResponse"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
                     "type": "string"
                 }
             }
         },
-        "course.GetExamResults.Search": {
+        "entity.CommonResponse-manage_ModelHealthResponse": {
             "type": "object",
             "properties": {
-                "search_data": {
-                    "$ref": "#/definitions/course.ExamRecordSearch"
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/manage.ModelHealthResponse"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.GetProblemResults.Search": {
+        "entity.CommonResponse-map_string_services_UploadPolicy": {
             "type": "object",
             "properties": {
-                "search_data": {
-                    "$ref": "#/definitions/course.ProblemRecordSearch"
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/map_string_services.UploadPolicy"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.MakeQuestionRequest": {
+        "entity.CommonResponse-map_uint64_redis_ModelStats": {
             "type": "object",
-            "required": [
-                "description",
-                "type"
-            ],
             "properties": {
-                "description": {
-                    "type": "string"
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
                 },
-                "type": {
-                    "$ref": "#/definitions/schema.ProblemType"
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/map_uint64_redis.ModelStats"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.ProblemRecordSearch": {
+        "entity.CommonResponse-schema_APIKey": {
             "type": "object",
             "properties": {
-                "everything": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.APIKey"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
                     "type": "string"
                 }
             }
         },
-        "course.SubmitExamRequest": {
+        "entity.CommonResponse-schema_Bucket": {
             "type": "object",
             "properties": {
-                "answers": {
-                    "description": "答案列表",
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/course.SubmitExamRequestAnswer"
-                    }
-                },
-                "time_spent": {
-                    "description": "答题用时（秒）",
+                "code": {
+                    "description": "代码",
                     "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Bucket"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.SubmitExamRequestAnswer": {
+        "entity.CommonResponse-schema_Course": {
             "type": "object",
             "properties": {
-                "answer": {},
-                "problem_id": {
+                "code": {
+                    "description": "代码",
                     "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Course"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.SubmitExamResponse": {
+        "entity.CommonResponse-schema_Exam": {
             "type": "object",
             "properties": {
-                "record_id": {
-                    "description": "记录ID",
+                "code": {
+                    "description": "代码",
                     "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Exam"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "course.SubmitProblemResponse": {
+        "entity.CommonResponse-schema_ExamUserRecord": {
             "type": "object",
             "properties": {
-                "comment": {
-                    "type": "string"
-                },
-                "score": {
+                "code": {
+                    "description": "代码",
                     "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ExamUserRecord"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
                 }
             }
         },
-        "entity.CommonResponse-any": {
+        "entity.CommonResponse-schema_File": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3747,7 +7152,12 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "数据"
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.File"
+                        }
+                    ]
                 },
                 "msg": {
                     "description": "消息",
@@ -3755,7 +7165,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-array_entity_ConfigChatModel": {
+        "entity.CommonResponse-schema_KnowledgeBase": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3764,10 +7174,32 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/entity.ConfigChatModel"
-                    }
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.KnowledgeBase"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CommonResponse-schema_KnowledgeDocument": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "代码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.KnowledgeDocument"
+                        }
+                    ]
                 },
                 "msg": {
                     "description": "消息",
@@ -3775,7 +7207,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-array_schema_Preset": {
+        "entity.CommonResponse-schema_Message": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3784,10 +7216,11 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Preset"
-                    }
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Message"
+                        }
+                    ]
                 },
                 "msg": {
                     "description": "消息",
@@ -3795,7 +7228,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-array_schema_Provider": {
+        "entity.CommonResponse-schema_Model": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3804,10 +7237,11 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Provider"
-                    }
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Model"
+                        }
+                    ]
                 },
                 "msg": {
                     "description": "消息",
@@ -3815,7 +7249,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-bool": {
+        "entity.CommonResponse-schema_ModelCollection": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3824,7 +7258,11 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "type": "boolean"
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ModelCollection"
+                        }
+                    ]
                 },
                 "msg": {
                     "description": "消息",
@@ -3832,7 +7270,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-chat_ChatMessageListResponse": {
+        "entity.CommonResponse-schema_Permission": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3843,7 +7281,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/chat.ChatMessageListResponse"
+                            "$ref": "#/definitions/schema.Permission"
                         }
                     ]
                 },
@@ -3853,7 +7291,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-course_SubmitExamResponse": {
+        "entity.CommonResponse-schema_Plan": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3864,7 +7302,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/course.SubmitExamResponse"
+                            "$ref": "#/definitions/schema.Plan"
                         }
                     ]
                 },
@@ -3874,7 +7312,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-course_SubmitProblemResponse": {
+        "entity.CommonResponse-schema_Problem": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3885,7 +7323,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/course.SubmitProblemResponse"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     ]
                 },
@@ -3895,7 +7333,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedContinuationResponse-schema_UserSession": {
+        "entity.CommonResponse-schema_Provider": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3906,7 +7344,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedContinuationResponse-schema_UserSession"
+                            "$ref": "#/definitions/schema.Provider"
                         }
                     ]
                 },
@@ -3916,7 +7354,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedSyncListResponse-schema_UserSession": {
+        "entity.CommonResponse-schema_Role": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3927,7 +7365,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedSyncListResponse-schema_UserSession"
+                            "$ref": "#/definitions/schema.Role"
                         }
                     ]
                 },
@@ -3937,7 +7375,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_APIKey": {
+        "entity.CommonResponse-schema_Schedule": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3948,7 +7386,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_APIKey"
+                            "$ref": "#/definitions/schema.Schedule"
                         }
                     ]
                 },
@@ -3958,7 +7396,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Bucket": {
+        "entity.CommonResponse-schema_Session": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3969,7 +7407,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Bucket"
+                            "$ref": "#/definitions/schema.Session"
                         }
                     ]
                 },
@@ -3979,7 +7417,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Course": {
+        "entity.CommonResponse-schema_User": {
             "type": "object",
             "properties": {
                 "code": {
@@ -3990,7 +7428,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Course"
+                            "$ref": "#/definitions/schema.User"
                         }
                     ]
                 },
@@ -4000,7 +7438,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ExamUserRecord": {
+        "entity.CommonResponse-schema_UserSession": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4011,7 +7449,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ExamUserRecord"
+                            "$ref": "#/definitions/schema.UserSession"
                         }
                     ]
                 },
@@ -4021,7 +7459,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Model": {
+        "entity.CommonResponse-schema_VoucherRedemption": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4032,7 +7470,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Model"
+                            "$ref": "#/definitions/schema.VoucherRedemption"
                         }
                     ]
                 },
@@ -4042,7 +7480,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ModelCollection": {
+        "entity.CommonResponse-services_PresignedUpload": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4053,7 +7491,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ModelCollection"
+                            "$ref": "#/definitions/services.PresignedUpload"
                         }
                     ]
                 },
@@ -4063,7 +7501,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Permission": {
+        "entity.CommonResponse-services_QuotaStatus": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4074,7 +7512,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Permission"
+                            "$ref": "#/definitions/services.QuotaStatus"
                         }
                     ]
                 },
@@ -4084,7 +7522,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Problem": {
+        "entity.CommonResponse-string": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4093,11 +7531,7 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Problem"
-                        }
-                    ]
+                    "type": "string"
                 },
                 "msg": {
                     "description": "消息",
@@ -4105,7 +7539,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_ProblemUserRecord": {
+        "entity.CommonResponse-uint64": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4114,11 +7548,7 @@ const docTemplate = `{
                 },
                 "data": {
                     "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_ProblemUserRecord"
-                        }
-                    ]
+                    "type": "integer"
                 },
                 "msg": {
                     "description": "消息",
@@ -4126,7 +7556,7 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Provider": {
+        "entity.CommonResponse-user_CreateAccessTokenResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -4137,7 +7567,7 @@ const docTemplate = `{
                     "description": "数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Provider"
+                            "$ref": "#/definitions/user.CreateAccessTokenResponse"
                         }
                     ]
                 },
@@ -4147,1447 +7577,2089 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Role": {
+        "entity.ConfigChatModel": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedContinuationResponse-schema_UserSession": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.UserSession"
+                    }
+                },
+                "next_page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedSyncListResponse-schema_UserSession": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.UserSession"
+                    }
+                },
+                "next_page": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.UserSession"
+                    }
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_APIKey": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.APIKey"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_Bucket": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Bucket"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_Course": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Course"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_ExamUserRecord": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ExamUserRecord"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_File": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.File"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_KnowledgeBase": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.KnowledgeBase"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_Model": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Model"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_ModelCollection": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ModelCollection"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PaginatedTotalResponse-schema_Permission": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Role"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Permission"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_Schedule": {
+        "entity.PaginatedTotalResponse-schema_Plan": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_Schedule"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Plan"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-entity_PaginatedTotalResponse-schema_User": {
+        "entity.PaginatedTotalResponse-schema_Problem": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/entity.PaginatedTotalResponse-schema_User"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Problem"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-int": {
+        "entity.PaginatedTotalResponse-schema_ProblemUserRecord": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ProblemUserRecord"
+                    }
                 },
-                "data": {
-                    "description": "数据",
+                "total": {
                     "type": "integer"
-                },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
                 }
             }
         },
-        "entity.CommonResponse-schema_APIKey": {
+        "entity.PaginatedTotalResponse-schema_Provider": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.APIKey"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Provider"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Bucket": {
+        "entity.PaginatedTotalResponse-schema_Role": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Bucket"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Role"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Course": {
+        "entity.PaginatedTotalResponse-schema_Schedule": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Course"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Schedule"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Exam": {
+        "entity.PaginatedTotalResponse-schema_UsageRecord": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Exam"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.UsageRecord"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_ExamUserRecord": {
+        "entity.PaginatedTotalResponse-schema_User": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.ExamUserRecord"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.User"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Message": {
+        "entity.PaginatedTotalResponse-schema_Voucher": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Message"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Voucher"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Model": {
+        "entity.PaginatedTotalResponse-schema_VoucherBatch": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Model"
-                        }
-                    ]
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.VoucherBatch"
+                    }
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_ModelCollection": {
+        "entity.PaginatedTotalResponse-schema_VoucherRedemption": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.ModelCollection"
-                        }
-                    ]
-                },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.VoucherRedemption"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.CommonResponse-schema_Permission": {
+        "entity.ReqUpdateBody-schema_Bucket": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Permission"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Bucket"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_Problem": {
+        "entity.ReqUpdateBody-schema_KnowledgeBase": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Problem"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.KnowledgeBase"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_Provider": {
+        "entity.ReqUpdateBody-schema_ModelCollection": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Provider"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.ModelCollection"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_Role": {
+        "entity.ReqUpdateBody-schema_Plan": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Role"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Plan"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_Schedule": {
+        "entity.ReqUpdateBody-schema_Problem": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Schedule"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Problem"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_Session": {
+        "entity.ReqUpdateBody-schema_Role": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Session"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Role"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_User": {
+        "entity.ReqUpdateBody-schema_Schedule": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.User"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Schedule"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-schema_UserSession": {
+        "entity.ReqUpdateBody-schema_Session": {
             "type": "object",
+            "required": [
+                "data",
+                "updates"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
-                    "type": "integer"
-                },
                 "data": {
-                    "description": "数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.UserSession"
-                        }
-                    ]
+                    "$ref": "#/definitions/schema.Session"
                 },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "updates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.CommonResponse-string": {
+        "manage.AssignRolePlan.assignRequest": {
             "type": "object",
+            "required": [
+                "plan_id",
+                "role_id"
+            ],
             "properties": {
-                "code": {
-                    "description": "代码",
+                "plan_id": {
                     "type": "integer"
                 },
-                "data": {
-                    "description": "数据",
-                    "type": "string"
-                },
-                "msg": {
-                    "description": "消息",
-                    "type": "string"
+                "role_id": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.ConfigChatModel": {
+        "manage.AssignUserPlan.assignRequest": {
             "type": "object",
+            "required": [
+                "plan_id",
+                "user_id"
+            ],
             "properties": {
-                "display_name": {
-                    "type": "string"
-                },
-                "icon": {
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
                     "type": "string"
                 },
-                "is_default": {
-                    "type": "boolean"
-                },
-                "name": {
-                    "type": "string"
+                "plan_id": {
+                    "type": "integer"
                 },
-                "order": {
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedContinuationResponse-schema_UserSession": {
+        "manage.CreateVoucherBatch.createRequest": {
             "type": "object",
+            "required": [
+                "count",
+                "name",
+                "value"
+            ],
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.UserSession"
-                    }
+                "count": {
+                    "description": "生成数量",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
                 },
-                "next_page": {
-                    "type": "integer"
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
+                    "type": "string"
+                },
+                "max_redemptions": {
+                    "description": "每个兑换码可兑换次数，默认 1",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "value": {
+                    "description": "每次兑换增加的额度",
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
-        "entity.PaginatedSyncListResponse-schema_UserSession": {
+        "manage.CreateVoucherBatchResponse": {
             "type": "object",
             "properties": {
-                "deleted": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.UserSession"
-                    }
-                },
-                "next_page": {
-                    "type": "integer"
+                "batch": {
+                    "$ref": "#/definitions/schema.VoucherBatch"
                 },
-                "updated": {
+                "vouchers": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.UserSession"
+                        "$ref": "#/definitions/schema.Voucher"
                     }
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_APIKey": {
+        "manage.ModelHealthResponse": {
             "type": "object",
             "properties": {
-                "list": {
+                "history": {
+                    "description": "最近的检测记录，按时间倒序",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.APIKey"
+                        "$ref": "#/definitions/schema.ModelHealthCheck"
                     }
                 },
-                "total": {
+                "model_id": {
                     "type": "integer"
+                },
+                "success_rate": {
+                    "description": "返回记录中的成功率",
+                    "type": "number"
+                },
+                "unhealthy": {
+                    "description": "是否已被标记为不健康，不健康的模型不参与集合路由",
+                    "type": "boolean"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Bucket": {
+        "manage.UnassignRolePlan.unassignRequest": {
             "type": "object",
+            "required": [
+                "role_id"
+            ],
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Bucket"
-                    }
-                },
-                "total": {
+                "role_id": {
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Course": {
+        "manage.UnassignUserPlan.unassignRequest": {
             "type": "object",
+            "required": [
+                "user_id"
+            ],
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Course"
-                    }
-                },
-                "total": {
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_ExamUserRecord": {
+        "manage.UpdateSystemConfigParams": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ExamUserRecord"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "value": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Model": {
+        "map_string_services.UploadPolicy": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/services.UploadPolicy"
+            }
+        },
+        "map_uint64_redis.ModelStats": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/redis.ModelStats"
+            }
+        },
+        "redis.ModelStats": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Model"
-                    }
+                "error_rate": {
+                    "description": "错误率（指数滑动平均）",
+                    "type": "number"
                 },
-                "total": {
+                "errors": {
+                    "description": "失败次数",
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "description": "首个响应的延迟（指数滑动平均）",
+                    "type": "number"
+                },
+                "requests": {
+                    "description": "请求次数",
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_ModelCollection": {
+        "schema.APIKey": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ModelCollection"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "total": {
+                "disabled": {
+                    "description": "是否已停用，鉴权失败时自动停用",
+                    "type": "boolean"
+                },
+                "disabled_reason": {
+                    "description": "停用原因",
+                    "type": "string"
+                },
+                "health": {
+                    "description": "健康状态，按需组装",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.APIKeyHealth"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "API 密钥",
+                    "type": "string"
+                },
+                "provider_id": {
+                    "description": "外键，指向 Provider",
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Permission": {
+        "schema.APIKeyHealth": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Permission"
+                "cooldown_until": {
+                    "description": "冷却结束时间",
+                    "type": "string"
+                },
+                "counts": {
+                    "description": "各类失败的累计次数，如 401/403/429/timeout",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
                     }
                 },
-                "total": {
+                "failures": {
+                    "description": "连续失败次数",
                     "type": "integer"
+                },
+                "last_error": {
+                    "description": "最后一次失败的错误信息",
+                    "type": "string"
+                },
+                "last_failed_at": {
+                    "description": "最后一次失败的时间",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态，见 APIKeyStatus* 常量",
+                    "type": "string"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Problem": {
+        "schema.AccessToken": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Problem"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "total": {
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "description": "最近使用时间",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "令牌名称",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "令牌前若干位，用于辨认令牌",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "可访问的权限路径（形如：POST:/user/create）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/datatypes.JSONType-array_string"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_ProblemUserRecord": {
+        "schema.Bucket": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ProblemUserRecord"
-                    }
+                "access_key_id": {
+                    "type": "string"
                 },
-                "total": {
+                "bucket_name": {
+                    "description": "本地储存时为根目录下的子目录",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "endpoint_url": {
+                    "description": "S3 服务地址，本地储存时为根目录",
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "secret_access_key": {
+                    "description": "本地储存时用于签名临时链接",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "储存类型：s3/local",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Provider": {
+        "schema.Course": {
             "type": "object",
             "properties": {
-                "list": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "课程描述",
+                    "type": "string"
+                },
+                "exams": {
+                    "description": "课程考试",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.Provider"
+                        "$ref": "#/definitions/schema.CourseExam"
                     }
                 },
-                "total": {
+                "id": {
+                    "description": "原始数据",
                     "type": "integer"
-                }
-            }
-        },
-        "entity.PaginatedTotalResponse-schema_Role": {
-            "type": "object",
-            "properties": {
-                "list": {
+                },
+                "name": {
+                    "description": "课程名称",
+                    "type": "string"
+                },
+                "resources": {
+                    "description": "关联数据",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.Role"
+                        "$ref": "#/definitions/schema.CourseResource"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "sorted_data": {
+                    "description": "排好序的数据",
+                    "type": "array",
+                    "items": {}
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_Schedule": {
+        "schema.CourseExam": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Schedule"
-                    }
+                "course_id": {
+                    "description": "关联课程ID",
+                    "type": "integer"
                 },
-                "total": {
+                "exam": {
+                    "description": "考试详细信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Exam"
+                        }
+                    ]
+                },
+                "exam_id": {
+                    "description": "关联考试ID",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "description": "考试排序",
                     "type": "integer"
                 }
             }
         },
-        "entity.PaginatedTotalResponse-schema_User": {
+        "schema.CourseResource": {
             "type": "object",
             "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.User"
-                    }
+                "course_id": {
+                    "description": "关联课程ID",
+                    "type": "integer"
                 },
-                "total": {
+                "id": {
                     "type": "integer"
-                }
-            }
-        },
-        "entity.ReqUpdateBody-schema_Bucket": {
-            "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.Bucket"
                 },
-                "updates": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "resource": {
+                    "description": "资源详细信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Resource"
+                        }
+                    ]
+                },
+                "resource_id": {
+                    "description": "关联资源ID",
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "description": "资源排序",
+                    "type": "integer"
                 }
             }
         },
-        "entity.ReqUpdateBody-schema_ModelCollection": {
+        "schema.Exam": {
             "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.ModelCollection"
+                "created_at": {
+                    "type": "string"
                 },
-                "updates": {
+                "description": {
+                    "description": "考试描述",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "limit_time": {
+                    "description": "考试限时（单位：秒）",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "problems": {
+                    "description": "考试包含的大题",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/schema.ExamProblem"
                     }
+                },
+                "subjects": {
+                    "description": "所属科目分类",
+                    "type": "string"
+                },
+                "total_score": {
+                    "description": "考试总分（单位：0.01分）",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "entity.ReqUpdateBody-schema_Problem": {
+        "schema.ExamProblem": {
             "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.Problem"
+                "exam_id": {
+                    "description": "关联考试ID",
+                    "type": "integer"
                 },
-                "updates": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "problem": {
+                    "description": "题目详细信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    ]
+                },
+                "problem_id": {
+                    "description": "关联题目ID",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "题目分值（1表示0.01分）",
+                    "type": "integer"
+                },
+                "sort_order": {
+                    "description": "题目排序",
+                    "type": "integer"
                 }
             }
         },
-        "entity.ReqUpdateBody-schema_Role": {
+        "schema.ExamUserRecord": {
             "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.Role"
-                },
-                "updates": {
+                "answers": {
+                    "description": "用户答案（关联表储存）",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/schema.ExamUserRecordAnswer"
                     }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "exam": {
+                    "description": "组装字段",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Exam"
+                        }
+                    ]
+                },
+                "exam_id": {
+                    "description": "考试ID",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "普通字段",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "总评分状态",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ScoreStatus"
+                        }
+                    ]
+                },
+                "time_spent": {
+                    "description": "答题用时（单位：秒）",
+                    "type": "integer"
+                },
+                "total_score": {
+                    "description": "总得分（单位：0.01分）",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "用户ID",
+                    "type": "integer"
                 }
             }
         },
-        "entity.ReqUpdateBody-schema_Schedule": {
+        "schema.ExamUserRecordAnswer": {
             "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.Schedule"
+                "answer": {
+                    "description": "用户答案"
                 },
-                "updates": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "comments": {
+                    "description": "评语/反馈",
+                    "type": "string"
+                },
+                "exam_id": {
+                    "description": "测验 ID",
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "description": "题目 ID",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "得分（单位：0.01分）",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "评分状态",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ScoreStatus"
+                        }
+                    ]
                 }
             }
         },
-        "entity.ReqUpdateBody-schema_Session": {
+        "schema.File": {
             "type": "object",
-            "required": [
-                "data",
-                "updates"
-            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/schema.Session"
+                "bucket": {
+                    "$ref": "#/definitions/schema.Bucket"
                 },
-                "updates": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "bucket_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "module": {
+                    "description": "文件所属模块",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "文件名",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "文件所有者ID（可选）",
+                    "type": "integer"
+                },
+                "s3_path": {
+                    "description": "S3 存储路径（如 \"uploads/abc123.jpg\"）",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "文件大小（字节）",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "上传状态，见 FileStatus* 常量",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "文件类型（如 image/jpeg）",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "临时访问链接，按需组装",
+                    "type": "string"
                 }
             }
         },
-        "manage.UpdateSystemConfigParams": {
+        "schema.KnowledgeBase": {
             "type": "object",
             "properties": {
+                "chunk_count": {
+                    "description": "分段数量",
+                    "type": "integer"
+                },
+                "chunk_overlap": {
+                    "description": "相邻分段的重叠大小（token）",
+                    "type": "integer"
+                },
+                "chunk_size": {
+                    "description": "分段大小（token）",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_count": {
+                    "description": "文档数量",
+                    "type": "integer"
+                },
+                "embedding_collection": {
+                    "description": "向量化使用的模型集合，创建后不可修改",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_public": {
+                    "description": "是否对所有用户可见，仅管理员可设置",
+                    "type": "boolean"
+                },
+                "min_score": {
+                    "description": "余弦相似度阈值，低于该值的分段不使用",
+                    "type": "number"
+                },
                 "name": {
                     "type": "string"
                 },
-                "value": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "owner_id": {
+                    "description": "创建者用户 ID",
+                    "type": "integer"
+                },
+                "top_k": {
+                    "description": "每次检索的分段数量",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "schema.APIKey": {
+        "schema.KnowledgeDocument": {
             "type": "object",
             "properties": {
+                "chunk_count": {
+                    "description": "分段数量",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "error": {
+                    "description": "处理失败的原因",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "key": {
-                    "description": "API 密钥",
+                "knowledge_base_id": {
+                    "type": "integer"
+                },
+                "mime_type": {
+                    "description": "上传时的文件类型",
                     "type": "string"
                 },
-                "provider_id": {
-                    "description": "外键，指向 Provider",
+                "name": {
+                    "description": "文件名",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "文件大小（字节）",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "处理状态，见 KnowledgeDocumentStatus* 常量",
+                    "type": "string"
+                },
+                "tokens": {
+                    "description": "正文的估算 token 数",
                     "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "schema.Bucket": {
+        "schema.Message": {
             "type": "object",
             "properties": {
-                "access_key_id": {
+                "content": {
                     "type": "string"
                 },
-                "bucket_name": {
+                "created_at": {
+                    "type": "string"
+                },
+                "extra": {
+                    "type": "object"
+                },
+                "file_ids": {
+                    "description": "附件文件 ID 列表",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "files": {
+                    "description": "附件文件，按需组装",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.File"
+                    }
+                },
+                "id": {
+                    "description": "默认结构",
+                    "type": "integer"
+                },
+                "model": {
+                    "description": "组装结构",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Model"
+                        }
+                    ]
+                },
+                "model_id": {
+                    "description": "回复所使用的模型",
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "description": "父消息 ID，构成消息树，0 表示根消息",
+                    "type": "integer"
+                },
+                "preset": {
+                    "$ref": "#/definitions/schema.Preset"
+                },
+                "preset_id": {
+                    "description": "回复所使用的预设",
+                    "type": "integer"
+                },
+                "reasoning_content": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "user/assistant/system",
+                    "type": "string"
+                },
+                "session_id": {
                     "type": "string"
                 },
+                "token_usage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.Model": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "是否启用",
+                    "type": "boolean"
+                },
+                "config": {
+                    "description": "使用 JSON 储存配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ModelConfig"
+                        }
+                    ]
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "description": "额外模型描述",
+                    "type": "string"
+                },
                 "display_name": {
+                    "description": "对外展示模型名称",
                     "type": "string"
                 },
-                "endpoint_url": {
+                "embedding": {
+                    "description": "是否为向量化模型，仅参与向量化路由，不可用于对话",
+                    "type": "boolean"
+                },
+                "icon": {
+                    "description": "模型图标",
                     "type": "string"
                 },
                 "id": {
+                    "description": "原始数据",
                     "type": "integer"
                 },
-                "region": {
+                "name": {
+                    "description": "模型名称",
                     "type": "string"
                 },
-                "secret_access_key": {
-                    "type": "string"
+                "provider": {
+                    "description": "组装数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Provider"
+                        }
+                    ]
+                },
+                "provider_id": {
+                    "description": "关联的 Provider ID",
+                    "type": "integer"
+                },
+                "unhealthy": {
+                    "description": "连通性检测连续失败，暂不参与集合路由",
+                    "type": "boolean"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.Course": {
+        "schema.ModelCollection": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
-                    "description": "课程描述",
+                    "description": "额外描述",
                     "type": "string"
                 },
-                "exams": {
-                    "description": "课程考试",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.CourseExam"
-                    }
+                "display_name": {
+                    "description": "展示名称",
+                    "type": "string"
+                },
+                "icon": {
+                    "description": "图标",
+                    "type": "string"
                 },
                 "id": {
-                    "description": "原始数据",
                     "type": "integer"
                 },
-                "name": {
-                    "description": "课程名称",
-                    "type": "string"
-                },
-                "resources": {
-                    "description": "关联数据",
+                "model_settings": {
+                    "description": "集合内各模型的负载均衡参数",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.CourseResource"
+                        "$ref": "#/definitions/schema.ModelCollectionModel"
                     }
                 },
-                "sorted_data": {
-                    "description": "排好序的数据",
+                "models": {
+                    "description": "关联的模型",
                     "type": "array",
-                    "items": {}
+                    "items": {
+                        "$ref": "#/definitions/schema.Model"
+                    }
                 },
-                "updated_at": {
+                "name": {
+                    "description": "唯一标识名称",
+                    "type": "string"
+                },
+                "strategy": {
+                    "description": "负载均衡策略，见 BalanceStrategy* 常量",
                     "type": "string"
                 }
             }
         },
-        "schema.CourseExam": {
+        "schema.ModelCollectionModel": {
             "type": "object",
             "properties": {
-                "course_id": {
-                    "description": "关联课程ID",
+                "model_collection_id": {
                     "type": "integer"
                 },
-                "exam": {
-                    "description": "考试详细信息",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Exam"
-                        }
-                    ]
+                "model_id": {
+                    "type": "integer"
                 },
-                "exam_id": {
-                    "description": "关联考试ID",
+                "priority": {
+                    "description": "优先级，数值越小越优先",
                     "type": "integer"
                 },
-                "id": {
+                "weight": {
+                    "description": "权重，用于加权随机，为 0 时仅作为备用模型",
                     "type": "integer"
+                }
+            }
+        },
+        "schema.ModelConfig": {
+            "type": "object",
+            "properties": {
+                "allow_system_prompt": {
+                    "description": "是否允许用户自行修改系统提示",
+                    "type": "boolean"
                 },
-                "sort_order": {
-                    "description": "考试排序",
+                "context_length": {
+                    "description": "上下文长度（token）",
+                    "type": "integer"
+                },
+                "default_temperature": {
+                    "description": "默认温度",
+                    "type": "number"
+                },
+                "embedding_batch_size": {
+                    "description": "向量化单次请求的最大文本数，0 表示使用提供商的上限",
+                    "type": "integer"
+                },
+                "embedding_dimensions": {
+                    "description": "向量化输出维度，0 表示使用模型默认维度",
+                    "type": "integer"
+                },
+                "file_input": {
+                    "description": "是否支持文件（如 PDF）输入",
+                    "type": "boolean"
+                },
+                "frequency_penalty": {
+                    "type": "number"
+                },
+                "input_price": {
+                    "description": "输入价格（每百万 token）",
+                    "type": "number"
+                },
+                "max_tokens": {
                     "type": "integer"
+                },
+                "output_price": {
+                    "description": "输出价格（每百万 token）",
+                    "type": "number"
+                },
+                "presence_penalty": {
+                    "type": "number"
+                },
+                "system_prompt": {
+                    "description": "预设系统提示",
+                    "type": "string"
+                },
+                "top_p": {
+                    "type": "number"
+                },
+                "vision": {
+                    "description": "是否支持图片输入",
+                    "type": "boolean"
                 }
             }
         },
-        "schema.CourseResource": {
+        "schema.ModelHealthCheck": {
             "type": "object",
             "properties": {
-                "course_id": {
-                    "description": "关联课程ID",
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "失败时的错误信息",
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "resource": {
-                    "description": "资源详细信息",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Resource"
-                        }
-                    ]
-                },
-                "resource_id": {
-                    "description": "关联资源ID",
+                "latency_ms": {
+                    "description": "请求耗时",
                     "type": "integer"
                 },
-                "sort_order": {
-                    "description": "资源排序",
+                "model_id": {
+                    "description": "检测的模型",
                     "type": "integer"
+                },
+                "success": {
+                    "description": "是否成功",
+                    "type": "boolean"
                 }
             }
         },
-        "schema.Exam": {
+        "schema.Permission": {
             "type": "object",
             "properties": {
+                "active": {
+                    "description": "是否启用（唯一可设置的字段）",
+                    "type": "boolean"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
-                    "description": "考试描述",
+                    "description": "权限描述",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "limit_time": {
-                    "description": "考试限时（单位：秒）",
-                    "type": "integer"
+                "module": {
+                    "description": "所属模块（handler名称）",
+                    "type": "string"
                 },
                 "name": {
+                    "description": "权限名称",
                     "type": "string"
                 },
-                "problems": {
-                    "description": "考试包含的大题",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ExamProblem"
-                    }
-                },
-                "subjects": {
-                    "description": "所属科目分类",
+                "path": {
+                    "description": "权限路径（形如：POST:/user/create）",
                     "type": "string"
                 },
-                "total_score": {
-                    "description": "考试总分（单位：0.01分）",
-                    "type": "integer"
-                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.ExamProblem": {
+        "schema.Plan": {
             "type": "object",
             "properties": {
-                "exam_id": {
-                    "description": "关联考试ID",
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "套餐描述",
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "展示名称",
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "problem": {
-                    "description": "题目详细信息",
+                "is_default": {
+                    "description": "未分配套餐的用户使用的默认套餐",
+                    "type": "boolean"
+                },
+                "model_collections": {
+                    "description": "可用的模型集合名称，为空表示不限制",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.Problem"
+                            "$ref": "#/definitions/datatypes.JSONType-array_string"
                         }
                     ]
                 },
-                "problem_id": {
-                    "description": "关联题目ID",
+                "monthly_tokens": {
+                    "description": "每月额度，每月初重置",
                     "type": "integer"
                 },
-                "score": {
-                    "description": "题目分值（1表示0.01分）",
-                    "type": "integer"
+                "name": {
+                    "description": "套餐标识，如 free/pro/team",
+                    "type": "string"
                 },
-                "sort_order": {
-                    "description": "题目排序",
+                "priority": {
+                    "description": "用户经多个角色获得多个套餐时取优先级最高者",
                     "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "schema.ExamUserRecord": {
+        "schema.Preset": {
             "type": "object",
             "properties": {
-                "answers": {
-                    "description": "用户答案（关联表储存）",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.ExamUserRecordAnswer"
-                    }
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "exam": {
-                    "description": "组装字段",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Exam"
-                        }
-                    ]
+                "description": {
+                    "description": "角色描述",
+                    "type": "string"
                 },
-                "exam_id": {
-                    "description": "考试ID",
+                "id": {
+                    "description": "原始数据",
                     "type": "integer"
                 },
-                "id": {
-                    "description": "普通字段",
+                "knowledge_base_id": {
+                    "description": "挂载的知识库 ID，非空时优先于会话的知识库",
                     "type": "integer"
                 },
-                "status": {
-                    "description": "总评分状态",
+                "name": {
+                    "description": "角色名称",
+                    "type": "string"
+                },
+                "prompt_session": {
+                    "description": "组装数据",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.ScoreStatus"
+                            "$ref": "#/definitions/schema.Session"
                         }
                     ]
                 },
-                "time_spent": {
-                    "description": "答题用时（单位：秒）",
-                    "type": "integer"
+                "prompt_session_id": {
+                    "description": "引用一个 session 中的对话作为 prompt",
+                    "type": "string"
                 },
-                "total_score": {
-                    "description": "总得分（单位：0.01分）",
-                    "type": "integer"
+                "tools": {
+                    "description": "预设开放的工具名称，为 null 时沿用请求或会话的工具配置",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "角色所属模块（chat、tue 等）",
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "description": "用户ID",
+                "version": {
+                    "description": "预设版本号，可能被用于标记是否需要强制更新",
                     "type": "integer"
                 }
             }
         },
-        "schema.ExamUserRecordAnswer": {
+        "schema.Problem": {
             "type": "object",
             "properties": {
                 "answer": {
-                    "description": "用户答案"
+                    "description": "答案（JSON存储ProblemAnswer）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.ProblemAnswer"
+                        }
+                    ]
                 },
-                "comments": {
-                    "description": "评语/反馈",
+                "created_at": {
                     "type": "string"
                 },
-                "exam_id": {
-                    "description": "测验 ID",
-                    "type": "integer"
+                "description": {
+                    "description": "支持HTML/Markdown",
+                    "type": "string"
                 },
-                "problem_id": {
-                    "description": "题目 ID",
-                    "type": "integer"
+                "difficulty": {
+                    "description": "难度等级 1-5",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
                 },
-                "score": {
-                    "description": "得分（单位：0.01分）",
+                "explanation": {
+                    "description": "答案解析",
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "status": {
-                    "description": "评分状态",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.ScoreStatus"
-                        }
-                    ]
+                "options": {
+                    "description": "选项（JSON存储ProblemOption数组）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ProblemOption"
+                    }
+                },
+                "subject": {
+                    "description": "所属科目/分类",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/schema.ProblemType"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "schema.Message": {
+        "schema.ProblemAnswer": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "description": "选择题：存储正确选项ID []uint\n填空题：存储多个填空关键词 []string\n判断题：true/false\n简答题：文本答案 string"
+                }
+            }
+        },
+        "schema.ProblemOption": {
             "type": "object",
             "properties": {
                 "content": {
                     "type": "string"
                 },
-                "created_at": {
+                "correct": {
+                    "description": "是否正确答案",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.ProblemType": {
+            "type": "string",
+            "enum": [
+                "single_choice",
+                "multiple_choice",
+                "fill_blank",
+                "short_answer",
+                "true_false",
+                "any"
+            ],
+            "x-enum-comments": {
+                "AnyProblemType": "任意，仅内部使用"
+            },
+            "x-enum-varnames": [
+                "SingleChoice",
+                "MultipleChoice",
+                "FillBlank",
+                "ShortAnswer",
+                "TrueFalse",
+                "AnyProblemType"
+            ]
+        },
+        "schema.ProblemUserRecord": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "$ref": "#/definitions/schema.ProblemAnswer"
+                },
+                "comment": {
                     "type": "string"
                 },
-                "extra": {
-                    "type": "object"
+                "created_at": {
+                    "type": "string"
                 },
                 "id": {
-                    "description": "默认结构",
                     "type": "integer"
                 },
-                "model": {
-                    "description": "组装结构",
+                "problem": {
+                    "description": "组装",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.Model"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     ]
                 },
-                "model_id": {
-                    "description": "回复所使用的模型",
+                "problem_id": {
                     "type": "integer"
                 },
-                "preset": {
-                    "$ref": "#/definitions/schema.Preset"
-                },
-                "preset_id": {
-                    "description": "回复所使用的预设",
+                "score": {
                     "type": "integer"
                 },
-                "reasoning_content": {
-                    "type": "string"
-                },
-                "role": {
-                    "description": "user/assistant/system",
-                    "type": "string"
-                },
-                "session_id": {
-                    "type": "string"
-                },
-                "token_usage": {
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "schema.Model": {
+        "schema.Provider": {
             "type": "object",
             "properties": {
-                "active": {
-                    "description": "是否启用",
-                    "type": "boolean"
+                "api_keys": {
+                    "description": "一对多关系，与 APIKey 模型关联",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.APIKey"
+                    }
                 },
-                "config": {
-                    "description": "使用 JSON 储存配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.ModelConfig"
-                        }
-                    ]
+                "base_url": {
+                    "description": "API 的基本 URL",
+                    "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
-                    "description": "额外模型描述",
+                    "description": "额外提供商描述",
                     "type": "string"
                 },
                 "display_name": {
-                    "description": "对外展示模型名称",
+                    "description": "对外展示提供商名称",
                     "type": "string"
                 },
                 "icon": {
-                    "description": "模型图标",
+                    "description": "供应商图标",
                     "type": "string"
                 },
                 "id": {
-                    "description": "原始数据",
                     "type": "integer"
                 },
+                "models": {
+                    "description": "一对多关系，与 Model 模型关联",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Model"
+                    }
+                },
                 "name": {
-                    "description": "模型名称",
+                    "description": "提供商名称",
                     "type": "string"
                 },
-                "provider": {
-                    "description": "组装数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Provider"
-                        }
-                    ]
-                },
-                "provider_id": {
-                    "description": "关联的 Provider ID",
-                    "type": "integer"
+                "type": {
+                    "description": "协议类型：openai/anthropic/gemini/ollama",
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.ModelCollection": {
+        "schema.Resource": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
-                    "description": "额外描述",
+                    "description": "资源描述",
                     "type": "string"
                 },
-                "display_name": {
-                    "description": "展示名称",
+                "file_key": {
+                    "description": "文件的 uuid key",
                     "type": "string"
                 },
-                "icon": {
-                    "description": "图标",
+                "file_name": {
+                    "description": "OSS 中的文件名",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "models": {
-                    "description": "关联的模型",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Model"
-                    }
+                "origin_file_name": {
+                    "description": "原始文件名",
+                    "type": "string"
                 },
-                "name": {
-                    "description": "唯一标识名称",
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.ModelConfig": {
+        "schema.Role": {
             "type": "object",
             "properties": {
-                "allow_system_prompt": {
-                    "description": "是否允许用户自行修改系统提示",
+                "active": {
+                    "description": "是否启用",
                     "type": "boolean"
                 },
-                "default_temperature": {
-                    "description": "默认温度",
-                    "type": "number"
+                "created_at": {
+                    "type": "string"
                 },
-                "frequency_penalty": {
-                    "type": "number"
+                "description": {
+                    "description": "角色描述",
+                    "type": "string"
                 },
-                "max_tokens": {
-                    "type": "integer"
+                "display_name": {
+                    "description": "角色名称",
+                    "type": "string"
                 },
-                "presence_penalty": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "system_prompt": {
-                    "description": "预设系统提示",
+                "name": {
+                    "description": "角色名称",
                     "type": "string"
                 },
-                "top_p": {
-                    "type": "number"
+                "permissions": {
+                    "description": "多对多关联",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Permission"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "schema.Permission": {
+        "schema.RolePlan": {
             "type": "object",
             "properties": {
-                "active": {
-                    "description": "是否启用（唯一可设置的字段）",
-                    "type": "boolean"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
-                    "description": "权限描述",
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "module": {
-                    "description": "所属模块（handler名称）",
-                    "type": "string"
+                "plan": {
+                    "$ref": "#/definitions/schema.Plan"
                 },
-                "name": {
-                    "description": "权限名称",
-                    "type": "string"
+                "plan_id": {
+                    "type": "integer"
                 },
-                "path": {
-                    "description": "权限路径（形如：POST:/user/create）",
-                    "type": "string"
+                "role_id": {
+                    "type": "integer"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.Preset": {
+        "schema.Schedule": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
-                    "description": "角色描述",
                     "type": "string"
                 },
+                "duration": {
+                    "description": "执行间隔，单位：秒",
+                    "type": "integer"
+                },
                 "id": {
-                    "description": "原始数据",
+                    "type": "integer"
+                },
+                "last_run_time": {
                     "type": "integer"
                 },
                 "name": {
-                    "description": "角色名称",
                     "type": "string"
                 },
-                "prompt_session": {
-                    "description": "组装数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Session"
-                        }
-                    ]
+                "status": {
+                    "$ref": "#/definitions/schema.ScheduleStatus"
+                }
+            }
+        },
+        "schema.ScheduleStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "ScheduleStatusStopped",
+                "ScheduleStatusRunning",
+                "ScheduleStatusPending"
+            ]
+        },
+        "schema.ScoreStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "scoring",
+                "completed",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "StatusCompleted": "评分完成",
+                "StatusFailed": "评分失败",
+                "StatusPending": "待评分",
+                "StatusScoring": "评分中"
+            },
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusScoring",
+                "StatusCompleted",
+                "StatusFailed"
+            ]
+        },
+        "schema.Session": {
+            "type": "object",
+            "properties": {
+                "context_size": {
+                    "description": "上下文大小",
+                    "type": "integer"
                 },
-                "prompt_session_id": {
-                    "description": "引用一个 session 中的对话作为 prompt",
+                "created_at": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "角色所属模块（chat、tue 等）",
-                    "type": "string"
+                "current_message_id": {
+                    "description": "当前选中分支的末端消息 ID",
+                    "type": "integer"
                 },
-                "updated_at": {
+                "enable_context": {
+                    "description": "上下文开关",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "原始数据",
                     "type": "string"
                 },
-                "version": {
-                    "description": "预设版本号，可能被用于标记是否需要强制更新",
+                "knowledge_base_id": {
+                    "description": "挂载的知识库 ID，为空时不检索",
                     "type": "integer"
-                }
-            }
-        },
-        "schema.Problem": {
-            "type": "object",
-            "properties": {
-                "answer": {
-                    "description": "答案（JSON存储ProblemAnswer）",
+                },
+                "last_active": {
+                    "type": "string"
+                },
+                "messages": {
+                    "description": "组装数据",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Message"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_type": {
+                    "description": "标题来源",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.ProblemAnswer"
+                            "$ref": "#/definitions/schema.SessionNameType"
                         }
                     ]
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "description": "支持HTML/Markdown",
+                "summary": {
+                    "description": "早期对话的滚动摘要",
                     "type": "string"
                 },
-                "difficulty": {
-                    "description": "难度等级 1-5",
-                    "type": "integer",
-                    "maximum": 5,
-                    "minimum": 1
+                "summary_message_id": {
+                    "description": "摘要覆盖到的最后一条消息 ID",
+                    "type": "integer"
                 },
-                "explanation": {
-                    "description": "答案解析",
+                "system_prompt": {
+                    "description": "系统提示词",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "options": {
-                    "description": "选项（JSON存储ProblemOption数组）",
+                "tools": {
+                    "description": "会话可用的工具名称，为 null 时按提问内容自动选择",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.ProblemOption"
+                        "type": "string"
                     }
                 },
-                "subject": {
-                    "description": "所属科目/分类",
-                    "type": "string"
-                },
-                "type": {
-                    "$ref": "#/definitions/schema.ProblemType"
-                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "schema.ProblemAnswer": {
+        "schema.SessionFlagInfo": {
             "type": "object",
             "properties": {
-                "answer": {
-                    "description": "选择题：存储正确选项ID []uint\n填空题：存储多个填空关键词 []string\n判断题：true/false\n简答题：文本答案 string"
+                "star": {
+                    "description": "标星",
+                    "type": "boolean"
                 }
             }
         },
-        "schema.ProblemOption": {
+        "schema.SessionNameType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "SessionNameTypeNone",
+                "SessionNameTypeTemp",
+                "SessionNameTypeSystem"
+            ]
+        },
+        "schema.SessionShareInfo": {
             "type": "object",
             "properties": {
-                "content": {
+                "code": {
+                    "description": "邀请码（可选）",
                     "type": "string"
                 },
-                "correct": {
-                    "description": "是否正确答案",
+                "expired_at": {
+                    "description": "邀请过期时间",
+                    "type": "integer"
+                },
+                "permanent": {
+                    "description": "是否永久分享",
                     "type": "boolean"
                 },
-                "id": {
-                    "type": "integer"
+                "title": {
+                    "description": "分享标题",
+                    "type": "string"
                 }
             }
         },
-        "schema.ProblemType": {
-            "type": "string",
-            "enum": [
-                "single_choice",
-                "multiple_choice",
-                "fill_blank",
-                "short_answer",
-                "true_false"
-            ],
-            "x-enum-varnames": [
-                "SingleChoice",
-                "MultipleChoice",
-                "FillBlank",
-                "ShortAnswer",
-                "TrueFalse"
-            ]
-        },
-        "schema.ProblemUserRecord": {
+        "schema.UsageRecord": {
             "type": "object",
             "properties": {
-                "answer": {
-                    "$ref": "#/definitions/schema.ProblemAnswer"
+                "api_key_id": {
+                    "type": "integer"
                 },
-                "comment": {
-                    "type": "string"
+                "charge": {
+                    "description": "从用户余额中扣除的额度",
+                    "type": "integer"
+                },
+                "completion_tokens": {
+                    "description": "包含思考内容的 token 数",
+                    "type": "integer"
+                },
+                "cost": {
+                    "description": "按模型价格计算的费用",
+                    "type": "number"
                 },
                 "created_at": {
                     "type": "string"
@@ -5595,373 +9667,486 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "problem": {
-                    "description": "组装",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Problem"
-                        }
-                    ]
+                "input_price": {
+                    "description": "记录时的输入价格（每百万 token）",
+                    "type": "number"
                 },
-                "problem_id": {
+                "message_id": {
                     "type": "integer"
                 },
-                "score": {
+                "model": {
+                    "description": "模型名称，模型被删除后仍可追溯",
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "integer"
+                },
+                "output_price": {
+                    "description": "记录时的输出价格（每百万 token）",
+                    "type": "number"
+                },
+                "preset_id": {
+                    "type": "integer"
+                },
+                "preset_record_id": {
+                    "description": "内置预设的调用记录 ID",
                     "type": "integer"
                 },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider_id": {
+                    "type": "integer"
+                },
+                "reasoning_tokens": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/schema.UsageRecordSource"
+                },
                 "user_id": {
+                    "description": "用户 ID，内置预设调用为 0",
                     "type": "integer"
                 }
             }
         },
-        "schema.Provider": {
+        "schema.UsageRecordSource": {
+            "type": "string",
+            "enum": [
+                "chat",
+                "openai",
+                "preset",
+                "embedding"
+            ],
+            "x-enum-comments": {
+                "UsageRecordSourceChat": "对话",
+                "UsageRecordSourceEmbedding": "文本向量化",
+                "UsageRecordSourceOpenAI": "OpenAI 兼容接口",
+                "UsageRecordSourcePreset": "内置预设调用"
+            },
+            "x-enum-varnames": [
+                "UsageRecordSourceChat",
+                "UsageRecordSourceOpenAI",
+                "UsageRecordSourcePreset",
+                "UsageRecordSourceEmbedding"
+            ]
+        },
+        "schema.User": {
             "type": "object",
             "properties": {
-                "api_keys": {
-                    "description": "一对多关系，与 APIKey 模型关联",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.APIKey"
-                    }
-                },
-                "base_url": {
-                    "description": "API 的基本 URL",
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
-                    "description": "额外提供商描述",
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "display_name": {
-                    "description": "对外展示提供商名称",
+                "nickname": {
                     "type": "string"
                 },
-                "icon": {
-                    "description": "供应商图标",
+                "password": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "models": {
-                    "description": "一对多关系，与 Model 模型关联",
+                "roles": {
+                    "description": "用户与角色之间的多对多关系",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/schema.Model"
+                        "$ref": "#/definitions/schema.Role"
                     }
                 },
-                "name": {
-                    "description": "提供商名称",
-                    "type": "string"
+                "type": {
+                    "$ref": "#/definitions/schema.UserType"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "schema.Resource": {
+        "schema.UserSession": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
-                    "description": "资源描述",
+                "flag_info": {
+                    "$ref": "#/definitions/schema.SessionFlagInfo"
+                },
+                "session": {
+                    "description": "组装数据",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Session"
+                        }
+                    ]
+                },
+                "session_id": {
                     "type": "string"
                 },
-                "file_key": {
-                    "description": "文件的 uuid key",
+                "share_info": {
+                    "description": "分享字段",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.SessionShareInfo"
+                        }
+                    ]
+                },
+                "type": {
+                    "$ref": "#/definitions/schema.UserSessionType"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "file_name": {
-                    "description": "OSS 中的文件名",
+                "user_id": {
+                    "description": "原始数据",
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.UserSessionType": {
+            "type": "integer",
+            "enum": [
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "UserSessionTypeOwner",
+                "UserSessionTypeInvitee"
+            ]
+        },
+        "schema.UserType": {
+            "type": "string",
+            "enum": [
+                "normal",
+                "third_party"
+            ],
+            "x-enum-comments": {
+                "UserTypeNormal": "使用普通注册（后续绑定第三方，类型不变）",
+                "UserTypeThirdParty": "使用第三方登录（后续可通过设置密码来转为普通）"
+            },
+            "x-enum-varnames": [
+                "UserTypeNormal",
+                "UserTypeThirdParty"
+            ]
+        },
+        "schema.Voucher": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "origin_file_name": {
-                    "description": "原始文件名",
-                    "type": "string"
+                "max_redemptions": {
+                    "type": "integer"
+                },
+                "redeemed": {
+                    "description": "已兑换次数",
+                    "type": "integer"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "value": {
+                    "type": "integer"
                 }
             }
         },
-        "schema.Role": {
+        "schema.VoucherBatch": {
             "type": "object",
             "properties": {
-                "active": {
-                    "description": "是否启用",
-                    "type": "boolean"
+                "count": {
+                    "description": "生成的兑换码数量",
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
-                    "description": "角色描述",
-                    "type": "string"
+                "created_by": {
+                    "description": "创建者用户 ID",
+                    "type": "integer"
                 },
-                "display_name": {
-                    "description": "角色名称",
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "max_redemptions": {
+                    "description": "每个兑换码可被兑换的次数（不同用户）",
+                    "type": "integer"
+                },
                 "name": {
-                    "description": "角色名称",
+                    "description": "批次名称，如活动名",
                     "type": "string"
                 },
-                "permissions": {
-                    "description": "多对多关联",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Permission"
-                    }
-                },
                 "updated_at": {
                     "type": "string"
+                },
+                "value": {
+                    "description": "每次兑换增加的额度",
+                    "type": "integer"
                 }
             }
         },
-        "schema.Schedule": {
+        "schema.VoucherRedemption": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "balance": {
+                    "description": "兑换后的余额",
+                    "type": "integer"
                 },
-                "description": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "code": {
                     "type": "string"
                 },
-                "duration": {
-                    "description": "执行间隔，单位：秒",
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "last_run_time": {
+                "user_id": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "value": {
+                    "description": "本次增加的额度",
+                    "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/schema.ScheduleStatus"
+                "voucher_id": {
+                    "type": "integer"
                 }
             }
         },
-        "schema.ScheduleStatus": {
-            "type": "integer",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "ScheduleStatusStopped",
-                "ScheduleStatusRunning",
-                "ScheduleStatusPending"
-            ]
-        },
-        "schema.ScoreStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "scoring",
-                "completed",
-                "failed"
-            ],
-            "x-enum-comments": {
-                "StatusCompleted": "评分完成",
-                "StatusFailed": "评分失败",
-                "StatusPending": "待评分",
-                "StatusScoring": "评分中"
-            },
-            "x-enum-varnames": [
-                "StatusPending",
-                "StatusScoring",
-                "StatusCompleted",
-                "StatusFailed"
-            ]
-        },
-        "schema.Session": {
+        "services.PresignedUpload": {
             "type": "object",
             "properties": {
-                "context_size": {
-                    "description": "上下文大小",
-                    "type": "integer"
-                },
-                "created_at": {
+                "expires_at": {
                     "type": "string"
                 },
-                "enable_context": {
-                    "description": "上下文开关",
-                    "type": "boolean"
+                "file": {
+                    "$ref": "#/definitions/schema.File"
                 },
-                "id": {
-                    "description": "原始数据",
+                "method": {
                     "type": "string"
                 },
-                "last_active": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "services.QuotaPeriod": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
                 },
-                "messages": {
-                    "description": "组装数据",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Message"
-                    }
+                "remaining": {
+                    "type": "integer"
                 },
-                "name": {
+                "reset_at": {
+                    "description": "下次重置时间",
                     "type": "string"
                 },
-                "name_type": {
-                    "description": "标题来源",
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.QuotaStatus": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "可用额度，含套餐额度",
+                    "type": "integer"
+                },
+                "daily": {
+                    "description": "每日额度，未限制时为空",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.SessionNameType"
+                            "$ref": "#/definitions/services.QuotaPeriod"
                         }
                     ]
                 },
-                "system_prompt": {
-                    "description": "系统提示词",
-                    "type": "string"
+                "monthly": {
+                    "description": "每月额度，未限制时为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.QuotaPeriod"
+                        }
+                    ]
                 },
-                "updated_at": {
+                "plan": {
+                    "description": "当前套餐，未配置套餐时为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/schema.Plan"
+                        }
+                    ]
+                },
+                "plan_balance": {
+                    "description": "套餐当月剩余额度",
+                    "type": "integer"
+                },
+                "plan_reset_at": {
+                    "description": "套餐额度上次重置时间",
                     "type": "string"
+                },
+                "unlimited": {
+                    "description": "是否不受余额及额度限制",
+                    "type": "boolean"
                 }
             }
         },
-        "schema.SessionFlagInfo": {
+        "services.RetrievedChunk": {
             "type": "object",
             "properties": {
-                "star": {
-                    "description": "标星",
-                    "type": "boolean"
+                "chunk_id": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_name": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "余弦相似度",
+                    "type": "number"
                 }
             }
         },
-        "schema.SessionNameType": {
-            "type": "integer",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "SessionNameTypeNone",
-                "SessionNameTypeTemp",
-                "SessionNameTypeSystem"
-            ]
-        },
-        "schema.SessionShareInfo": {
+        "services.ToolInfo": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "邀请码（可选）",
+                "description": {
                     "type": "string"
                 },
-                "expired_at": {
-                    "description": "邀请过期时间",
-                    "type": "integer"
+                "display_name": {
+                    "type": "string"
                 },
-                "permanent": {
-                    "description": "是否永久分享",
+                "enabled": {
+                    "description": "管理员是否启用",
                     "type": "boolean"
                 },
-                "title": {
-                    "description": "分享标题",
+                "name": {
                     "type": "string"
+                },
+                "parameters": {
+                    "description": "JSON Schema 格式的参数定义",
+                    "type": "object",
+                    "additionalProperties": {}
                 }
             }
         },
-        "schema.User": {
+        "services.UploadPolicy": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "allowed_types": {
+                    "description": "允许的 MIME 类型，为空时不限制",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
+                "max_size": {
+                    "description": "单个文件的最大字节数",
                     "type": "integer"
-                },
-                "nickname": {
+                }
+            }
+        },
+        "user.CreateAccessToken.createRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
                 },
-                "roles": {
-                    "description": "用户与角色之间的多对多关系",
+                "scopes": {
+                    "description": "权限路径，形如 POST:/v1/chat/completions",
                     "type": "array",
+                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/schema.Role"
+                        "type": "string"
                     }
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
                 }
             }
         },
-        "schema.UserSession": {
+        "user.CreateAccessTokenResponse": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "flag_info": {
-                    "$ref": "#/definitions/schema.SessionFlagInfo"
+                "expires_at": {
+                    "description": "过期时间，为空表示永不过期",
+                    "type": "string"
                 },
-                "session": {
-                    "description": "组装数据",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/schema.Session"
-                        }
-                    ]
+                "id": {
+                    "type": "integer"
                 },
-                "session_id": {
+                "last_used_at": {
+                    "description": "最近使用时间",
                     "type": "string"
                 },
-                "share_info": {
-                    "description": "分享字段",
+                "name": {
+                    "description": "令牌名称",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "令牌前若干位，用于辨认令牌",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "可访问的权限路径（形如：POST:/user/create）",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/schema.SessionShareInfo"
+                            "$ref": "#/definitions/datatypes.JSONType-array_string"
                         }
                     ]
                 },
-                "type": {
-                    "$ref": "#/definitions/schema.UserSessionType"
+                "token": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
                 "user_id": {
-                    "description": "原始数据",
                     "type": "integer"
                 }
             }
         },
-        "schema.UserSessionType": {
-            "type": "integer",
-            "enum": [
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "UserSessionTypeOwner",
-                "UserSessionTypeInvitee"
-            ]
-        },
         "user.Login.loginRequest": {
             "type": "object",
             "required": [
@@ -5970,7 +10155,8 @@ const docTemplate = `{
             ],
             "properties": {
                 "password": {
-                    "type": "string"
+                    "type": "string",
+                    "minLength": 1
                 },
                 "username": {
                     "type": "string"
@@ -5992,6 +10178,18 @@ const docTemplate = `{
                 }
             }
         },
+        "user.RedeemVoucher.redeemRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
         "user.Register.registerRequest": {
             "type": "object",
             "required": [
@@ -8,3 +8,4 @@ const RefreshTokenExpire = time.Hour * 24 * 7
 
 var AuthIgnoredKey = "auth_middleware_ignore"
 var PermissionSuperAdminKey = "permission_is_super_admin"
+var AccessTokenScopesKey = "access_token_scopes"
@@ -13,7 +13,16 @@ func (e BizError) Error() string {
 }
 
 var (
-	BizErrNoPermission = BizError{HttpCode: 400, BizCode: 10001, Msg: "no permission"}
-	BizErrNoRecord     = BizError{HttpCode: 400, BizCode: 10002, Msg: "no record"}
-	BizErrOutdated     = BizError{HttpCode: 400, BizCode: 10003, Msg: "outdated"}
+	BizErrNoPermission           = BizError{HttpCode: 400, BizCode: 10001, Msg: "no permission"}
+	BizErrNoRecord               = BizError{HttpCode: 400, BizCode: 10002, Msg: "no record"}
+	BizErrOutdated               = BizError{HttpCode: 400, BizCode: 10003, Msg: "outdated"}
+	BizErrInsufficientBalance    = BizError{HttpCode: 402, BizCode: 10004, Msg: "insufficient balance"}
+	BizErrDailyQuotaExceeded     = BizError{HttpCode: 429, BizCode: 10005, Msg: "daily quota exceeded"}
+	BizErrMonthlyQuotaExceeded   = BizError{HttpCode: 429, BizCode: 10006, Msg: "monthly quota exceeded"}
+	BizErrVoucherInvalid         = BizError{HttpCode: 400, BizCode: 10007, Msg: "invalid voucher code"}
+	BizErrVoucherExpired         = BizError{HttpCode: 400, BizCode: 10008, Msg: "voucher expired"}
+	BizErrVoucherExhausted       = BizError{HttpCode: 400, BizCode: 10009, Msg: "voucher fully redeemed"}
+	BizErrVoucherRedeemed        = BizError{HttpCode: 400, BizCode: 10010, Msg: "voucher already redeemed"}
+	BizErrVoucherTooManyAttempts = BizError{HttpCode: 429, BizCode: 10011, Msg: "too many redeem attempts"}
+	BizErrPlanModelNotAllowed    = BizError{HttpCode: 403, BizCode: 10012, Msg: "model not available in current plan"}
 )
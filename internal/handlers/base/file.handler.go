@@ -0,0 +1,404 @@
+package base
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/handlers"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/fcraft/open-chat/internal/utils/storage_utils"
+	"github.com/gin-gonic/gin"
+)
+
+type FileHandler struct {
+	handlers.BaseHandler
+}
+
+func NewFileHandler(handler *handlers.BaseHandler) *FileHandler {
+	return &FileHandler{
+		BaseHandler: *handler,
+	}
+}
+
+// inlineContentTypes 本地储存对象可在浏览器中直接打开的类型，不含可执行脚本的 image/svg+xml
+var inlineContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+}
+
+// storageError 将储存服务的错误转换为响应
+func storageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrStorageFileNotFound):
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+	case errors.Is(err, services.ErrStorageFileTooLarge):
+		ctx_utils.CustomError(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrStorageTypeNotAllowed):
+		ctx_utils.CustomError(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, services.ErrStorageModuleNotAllowed),
+		errors.Is(err, services.ErrStorageUploadCompleted),
+		errors.Is(err, services.ErrStorageObjectMissing):
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrStorageNoBucket):
+		ctx_utils.CustomError(c, http.StatusServiceUnavailable, err.Error())
+	default:
+		ctx_utils.HttpError(c, constants.ErrInternal)
+	}
+}
+
+// getOwnedFile 读取路径参数中当前用户上传的文件
+//
+// 失败时已写入响应，返回 nil
+func (h *FileHandler) getOwnedFile(c *gin.Context) *schema.File {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return nil
+	}
+	file, err := services.GetStorageService().GetOwnedFile(ctx_utils.GetUserId(c), uri.ID)
+	if err != nil {
+		storageError(c, err)
+		return nil
+	}
+	return file
+}
+
+// withDownloadURL 组装临时下载链接，并去掉储存桶凭据
+func withDownloadURL(file *schema.File) *schema.File {
+	if file.Status == schema.FileStatusReady && file.Bucket != nil {
+		file.URL, _ = services.GetStorageService().PresignDownload(file, 0)
+	}
+	file.Bucket = nil
+	return file
+}
+
+// UploadFile
+//
+//	@Summary		上传文件
+//	@Description	通过接口直接上传文件，文件类型以内容嗅探结果为准，大小及类型限制见 /file/upload/policy
+//	@Tags			File
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file								true	"文件"
+//	@Param			module	formData	string								true	"文件所属模块，如 chat"
+//	@Success		200		{object}	entity.CommonResponse[schema.File]	"上传的文件"
+//	@Router			/file/upload [post]
+func (h *FileHandler) UploadFile(c *gin.Context) {
+	header, err := c.FormFile("file")
+	module := c.PostForm("module")
+	if err != nil || module == "" {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	reader, err := header.Open()
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	file, err := services.GetStorageService().Upload(
+		c.Request.Context(), ctx_utils.GetUserId(c), module, header.Filename, header.Size, reader,
+	)
+	if err != nil {
+		storageError(c, err)
+		return
+	}
+	ctx_utils.Success(c, withDownloadURL(file))
+}
+
+// GetUploadPolicy
+//
+//	@Summary		获取上传限制
+//	@Description	返回允许上传的模块及各模块的文件大小、类型限制，未列出的模块不允许上传
+//	@Tags			File
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[map[string]services.UploadPolicy]	"模块 -> 上传限制"
+//	@Router			/file/upload/policy [get]
+func (h *FileHandler) GetUploadPolicy(c *gin.Context) {
+	ctx_utils.Success(c, services.GetStorageService().GetUploadPolicies())
+}
+
+// PresignUpload
+//
+//	@Summary		获取预签名上传链接
+//	@Description	校验声明的文件大小及类型后签发上传链接，客户端上传后需调用确认接口，超过 24 小时未确认的上传会被清理
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		base.PresignUpload.presignRequest				true	"文件信息"
+//	@Success		200	{object}	entity.CommonResponse[services.PresignedUpload]	"上传链接"
+//	@Router			/file/upload/presign [post]
+func (h *FileHandler) PresignUpload(c *gin.Context) {
+	type presignRequest struct {
+		Name   string `json:"name" binding:"required"`      // 文件名
+		Size   int64  `json:"size" binding:"required,gt=0"` // 文件大小（字节）
+		Type   string `json:"type" binding:"required"`      // 文件的 MIME 类型
+		Module string `json:"module" binding:"required"`    // 文件所属模块，如 chat
+	}
+	var req presignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	upload, err := services.GetStorageService().CreateUpload(ctx_utils.GetUserId(c), req.Module, req.Name, req.Size, req.Type)
+	if err != nil {
+		storageError(c, err)
+		return
+	}
+	ctx_utils.Success(c, upload)
+}
+
+// CompleteUpload
+//
+//	@Summary		确认预签名上传
+//	@Description	以实际上传的内容校验文件大小及类型，不符合限制时文件会被删除
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64								true	"文件 ID"
+//	@Success		200	{object}	entity.CommonResponse[schema.File]	"上传完成的文件"
+//	@Router			/file/{id}/complete [post]
+func (h *FileHandler) CompleteUpload(c *gin.Context) {
+	file := h.getOwnedFile(c)
+	if file == nil {
+		return
+	}
+	if err := services.GetStorageService().CompleteUpload(c.Request.Context(), file); err != nil {
+		storageError(c, err)
+		return
+	}
+	ctx_utils.Success(c, withDownloadURL(file))
+}
+
+// GetFiles
+//
+//	@Summary		获取文件列表
+//	@Description	获取当前用户上传完成的文件
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			req		query		entity.ParamPagingSort												true	"分页参数"
+//	@Param			module	query		string																false	"文件所属模块"
+//	@Success		200		{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.File]]	"文件列表"
+//	@Router			/file/list [get]
+func (h *FileHandler) GetFiles(c *gin.Context) {
+	type fileFilter struct {
+		Module string `form:"module"`
+	}
+	var param entity.ParamPagingSort
+	var filter fileFilter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	tx := h.Db.Preload("Bucket").Where("owner_id = ? AND status = ?", ctx_utils.GetUserId(c), schema.FileStatusReady)
+	if filter.Module != "" {
+		tx = tx.Where("module = ?", filter.Module)
+	}
+	files, total, err := gorm_utils.GetByPageTotal[schema.File](tx, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	for i := range files {
+		withDownloadURL(&files[i])
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.File]{
+			List:  files,
+			Total: total,
+		},
+	)
+}
+
+// GetFile
+//
+//	@Summary		获取文件
+//	@Description	获取当前用户上传的文件及临时下载链接
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64								true	"文件 ID"
+//	@Success		200	{object}	entity.CommonResponse[schema.File]	"文件"
+//	@Router			/file/{id} [get]
+func (h *FileHandler) GetFile(c *gin.Context) {
+	file := h.getOwnedFile(c)
+	if file == nil {
+		return
+	}
+	ctx_utils.Success(c, withDownloadURL(file))
+}
+
+// DownloadFile
+//
+//	@Summary		下载文件
+//	@Description	重定向到文件的临时下载链接
+//	@Tags			File
+//	@Param			id	path	uint64	true	"文件 ID"
+//	@Success		302
+//	@Router			/file/{id}/download [get]
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	file := h.getOwnedFile(c)
+	if file == nil {
+		return
+	}
+	if file.Status != schema.FileStatusReady {
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+		return
+	}
+	presigned, err := services.GetStorageService().PresignDownload(file, 0)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	c.Redirect(http.StatusFound, presigned)
+}
+
+// DeleteFile
+//
+//	@Summary		删除文件
+//	@Description	删除当前用户上传的文件，引用该文件的消息将不再显示附件
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"文件 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"删除成功与否"
+//	@Router			/file/{id}/delete [post]
+func (h *FileHandler) DeleteFile(c *gin.Context) {
+	file := h.getOwnedFile(c)
+	if file == nil {
+		return
+	}
+	if err := services.GetStorageService().DeleteFile(file); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// getLocalBucket 校验本地储存签名链接，返回储存桶及对象路径
+//
+// 失败时已写入响应，返回 nil
+func (h *FileHandler) getLocalBucket(c *gin.Context, method string) (*schema.Bucket, string) {
+	bucketId, err := strconv.ParseUint(c.Param("bucket_id"), 10, 64)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return nil, ""
+	}
+	key, err := storage_utils.CleanKey(c.Param("key"))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return nil, ""
+	}
+	bucket, err := gorm_utils.GetByID[schema.Bucket](h.Db, bucketId)
+	if err != nil || bucket.Type != schema.BucketTypeLocal {
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+		return nil, ""
+	}
+	if c.Query("method") != method ||
+		storage_utils.VerifyLocalURL(bucket, method, key, c.Query("expires"), c.Query("signature")) != nil {
+		ctx_utils.CustomError(c, http.StatusForbidden, "invalid or expired signature")
+		return nil, ""
+	}
+	return bucket, key
+}
+
+// GetLocalObject
+//
+//	@Summary		读取本地储存对象
+//	@Description	通过本地储存的签名链接读取文件内容，链接由文件接口签发
+//	@Tags			File
+//	@Param			bucket_id	path	uint64	true	"储存桶 ID"
+//	@Param			key			path	string	true	"对象路径"
+//	@Param			method		query	string	true	"签名方法"
+//	@Param			expires		query	int		true	"过期时间"
+//	@Param			signature	query	string	true	"签名"
+//	@Success		200
+//	@Router			/storage/object/{bucket_id}/{key} [get]
+func (h *FileHandler) GetLocalObject(c *gin.Context) {
+	bucket, key := h.getLocalBucket(c, http.MethodGet)
+	if bucket == nil {
+		return
+	}
+	backend, err := storage_utils.GetBackend(bucket)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	size, err := backend.Stat(c.Request.Context(), bucket, key)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+		return
+	}
+	reader, err := backend.Get(c.Request.Context(), bucket, key)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	contentType := "application/octet-stream"
+	disposition := "attachment"
+	var file schema.File
+	if err := h.Db.Where("bucket_id = ? AND s3_path = ?", bucket.ID, key).First(&file).Error; err == nil {
+		contentType = file.Type
+		// 仅允许安全的图片及 PDF 在浏览器中直接打开，其余类型（如 text/html）一律下载，避免存储型 XSS
+		if inlineContentTypes[contentType] {
+			disposition = "inline"
+		}
+		disposition += "; filename*=UTF-8''" + url.PathEscape(file.Name)
+	}
+	headers := map[string]string{
+		"Content-Disposition":     disposition,
+		"Content-Security-Policy": "sandbox",
+		"X-Content-Type-Options":  "nosniff",
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, reader, headers)
+}
+
+// PutLocalObject
+//
+//	@Summary		写入本地储存对象
+//	@Description	通过本地储存的预签名上传链接上传文件内容，上传后需调用确认接口
+//	@Tags			File
+//	@Accept			octet-stream
+//	@Produce		json
+//	@Param			bucket_id	path		uint64						true	"储存桶 ID"
+//	@Param			key			path		string						true	"对象路径"
+//	@Param			method		query		string						true	"签名方法"
+//	@Param			expires		query		int							true	"过期时间"
+//	@Param			signature	query		string						true	"签名"
+//	@Success		200			{object}	entity.CommonResponse[bool]	"上传成功与否"
+//	@Router			/storage/object/{bucket_id}/{key} [put]
+func (h *FileHandler) PutLocalObject(c *gin.Context) {
+	bucket, key := h.getLocalBucket(c, http.MethodPut)
+	if bucket == nil {
+		return
+	}
+	if err := services.GetStorageService().ReceiveLocalUpload(c.Request.Context(), bucket, key, c.Request.Body); err != nil {
+		storageError(c, err)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
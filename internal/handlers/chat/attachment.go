@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+)
+
+const (
+	maxMessageAttachments = 10               // 单条消息最多附件数
+	maxInlineFileSize     = 20 << 20         // 以 data URL 内联给模型的文件大小上限
+	maxInlineTotalSize    = 40 << 20         // 单次请求内联文件的总大小上限
+	inlineContextMessages = 4                // 仅内联最近几条上下文消息的附件，更早消息的附件以文本提示
+	attachmentURLExpire   = 1 * time.Hour    // 附件预签名链接有效期
+	attachmentURLForModel = 30 * time.Minute // 提供给模型拉取的预签名链接有效期
+)
+
+var errInvalidAttachments = errors.New("invalid attachments")
+
+// loadMessageFiles 读取消息列表中引用的所有附件文件
+//
+//	Returns:
+//		map[uint64]schema.File 文件 ID -> 文件
+func (h *Handler) loadMessageFiles(messages []schema.Message) (map[uint64]schema.File, error) {
+	var fileIds []uint64
+	for _, m := range messages {
+		fileIds = append(fileIds, m.FileIDs...)
+	}
+	files, err := h.Store.GetFilesByIDs(slice.Unique(fileIds))
+	if err != nil {
+		return nil, err
+	}
+	fileMap := make(map[uint64]schema.File, len(files))
+	for _, f := range files {
+		fileMap[f.ID] = f
+	}
+	return fileMap, nil
+}
+
+// checkAttachments 校验用户本次提交的附件存在且属于该用户
+func (h *Handler) checkAttachments(userId uint64, fileIds []uint64) error {
+	if len(fileIds) == 0 {
+		return nil
+	}
+	if len(fileIds) > maxMessageAttachments {
+		return fmt.Errorf("%w: at most %d files", errInvalidAttachments, maxMessageAttachments)
+	}
+	files, err := h.Store.GetFilesByIDs(fileIds)
+	if err != nil {
+		return err
+	}
+	if len(files) != len(slice.Unique(fileIds)) {
+		return fmt.Errorf("%w: file not found", errInvalidAttachments)
+	}
+	for _, f := range files {
+		if f.OwnerID != userId {
+			return fmt.Errorf("%w: file not found", errInvalidAttachments)
+		}
+	}
+	return nil
+}
+
+// fillMessageFiles 为消息组装附件信息及临时访问链接，用于历史消息展示
+func (h *Handler) fillMessageFiles(messages []schema.Message) {
+	fileMap, err := h.loadMessageFiles(messages)
+	if err != nil || len(fileMap) == 0 {
+		return
+	}
+	for i, m := range messages {
+		for _, fileId := range m.FileIDs {
+			f, ok := fileMap[fileId]
+			if !ok {
+				continue
+			}
+			f.URL, _ = services.GetStorageService().PresignDownload(&f, attachmentURLExpire)
+			f.Bucket = nil // 不返回储存桶凭据
+			messages[i].Files = append(messages[i].Files, f)
+		}
+	}
+}
+
+// buildAttachments 将消息附件转换为补全请求的附件，模型不支持的类型以文本形式提示
+//
+// 内联的文件从 inlineBudget 中扣减大小，剩余额度不足时不再内联，以文本提示代替；
+// presignImages 为 false 时图片同样内联，由服务端读取储存内容，不依赖提供商或适配器能否访问预签名链接
+//
+//	Returns:
+//		[]chat_utils.Attachment 可直接发送给模型的附件
+//		string 需追加到消息正文的附件说明
+func buildAttachments(ctx context.Context, fileIds []uint64, fileMap map[uint64]schema.File, config schema.ModelConfig, presignImages bool, inlineBudget *int64) ([]chat_utils.Attachment, string) {
+	var attachments []chat_utils.Attachment
+	var notes string
+	for _, fileId := range fileIds {
+		f, ok := fileMap[fileId]
+		if !ok {
+			continue
+		}
+		attachment := chat_utils.Attachment{
+			Name:     f.Name,
+			MimeType: f.Type,
+		}
+		readable := attachment.IsImage() && config.Vision || !attachment.IsImage() && config.FileInput
+		switch {
+		case presignImages && attachment.IsImage() && config.Vision && f.Bucket != nil && f.Bucket.Type != schema.BucketTypeLocal:
+			// 图片使用预签名链接，由提供商自行拉取
+			url, err := services.GetStorageService().PresignDownload(&f, attachmentURLForModel)
+			if err != nil {
+				break
+			}
+			attachment.URL = url
+			attachments = append(attachments, attachment)
+			continue
+		case readable && f.Size <= maxInlineFileSize && f.Size <= *inlineBudget:
+			// 文件及本地储存的图片以 data URL 内联，本地储存的链接提供商无法访问
+			data, err := services.GetStorageService().ReadFile(ctx, &f, maxInlineFileSize)
+			if err != nil {
+				break
+			}
+			*inlineBudget -= int64(len(data))
+			attachment.URL = fmt.Sprintf("data:%s;base64,%s", f.Type, base64.StdEncoding.EncodeToString(data))
+			attachments = append(attachments, attachment)
+			continue
+		case readable:
+			notes += fmt.Sprintf("\n[附件: %s（未随本次请求发送）]", f.Name)
+			continue
+		}
+		notes += fmt.Sprintf("\n[附件: %s（当前模型无法读取）]", f.Name)
+	}
+	return attachments, notes
+}
+
+// canPresignImages 判断候选模型的提供商是否都能直接拉取图片的预签名链接
+//
+// 仅 OpenAI 兼容提供商由提供商自行拉取；其余适配器需在服务端下载链接，无法访问内网储存，且每轮请求都会重复下载
+func canPresignImages(candidates []schema.Model) bool {
+	for _, model := range candidates {
+		if model.Provider == nil {
+			continue
+		}
+		if model.Provider.Type != "" && model.Provider.Type != chat_utils.ProviderTypeOpenAI {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,9 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/duke-git/lancet/v2/slice"
 	"github.com/fcraft/open-chat/internal/constants"
@@ -11,16 +13,38 @@ import (
 	"github.com/fcraft/open-chat/internal/services"
 	"github.com/fcraft/open-chat/internal/utils/chat_utils"
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/search_utils"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"gorm.io/datatypes"
 	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
-	"strconv"
 	"strings"
 	"time"
 )
 
+// completionParams 对话补全的公共参数
+type completionParams struct {
+	ModelName     string    `json:"model_name" binding:"required"` // 模型集合名称
+	EnableContext *bool     `json:"enable_context" binding:"-"`
+	EnableSearch  *bool     `json:"enable_search" binding:"-"` // 是否启用搜索
+	BotID         *uint64   `json:"bot_id" binding:"-"`
+	SystemPrompt  *string   `json:"system_prompt" binding:"-"` // 系统提示词
+	Tools         *[]string `json:"tools" binding:"-"`         // 本次可用的工具名称，不传则使用会话配置
+}
+
+// completionTask 一次流式补全任务
+type completionTask struct {
+	completionParams
+	Session       *schema.Session
+	Question      string           // 用户提问内容
+	FileIDs       []uint64         // 用户提问的附件文件 ID
+	ContextLeafID uint64           // 上下文所在分支的末端消息 ID（不含本次提问）
+	ReplyParentID uint64           // 预插入消息挂载的父消息 ID
+	Messages      []schema.Message // 预插入的消息，最后一条为 assistant 回复
+}
+
 // CompletionStream
 //
 //	@Summary		流式输出聊天
@@ -35,12 +59,9 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 	// 从 path 和 body 中获取用户输入
 	var uri PathParamSessionId
 	type userInput struct {
-		Question      string  `json:"question" binding:"required"`
-		ModelName     string  `json:"model_name" binding:"required"` // 模型集合名称
-		EnableContext *bool   `json:"enable_context" binding:"-"`
-		EnableSearch  *bool   `json:"enable_search" binding:"-"` // 是否启用搜索
-		BotID         *uint64 `json:"bot_id" binding:"-"`
-		SystemPrompt  *string `json:"system_prompt" binding:"-"` // 系统提示词
+		Question string   `json:"question" binding:"required"`
+		FileIDs  []uint64 `json:"file_ids" binding:"-"` // 附件文件 ID
+		completionParams
 	}
 	var req userInput
 	if err := c.BindUri(&uri); err != nil || uri.SessionId == "" {
@@ -57,12 +78,134 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 		return
 	}
 
+	// 获取会话配置
+	var session schema.Session
+	if err := h.Db.First(&session, "id = ?", uri.SessionId).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "session not found")
+		return
+	}
+	// 新消息接在当前选中分支的末端
+	leafId, err := h.Store.LinkSessionMessages(&session)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+
+	h.streamCompletion(
+		c, &completionTask{
+			completionParams: req.completionParams,
+			Session:          &session,
+			Question:         req.Question,
+			FileIDs:          req.FileIDs,
+			ContextLeafID:    leafId,
+			ReplyParentID:    leafId,
+			Messages: []schema.Message{
+				{SessionID: session.ID, Role: "user", FileIDs: req.FileIDs},
+				{SessionID: session.ID, Role: "assistant"},
+			},
+		},
+	)
+}
+
+// RegenerateStream
+//
+//	@Summary		重新生成回复
+//	@Description	在同一条用户消息下生成新的 assistant 回复分支，传入 assistant 消息或其对应的 user 消息 ID 均可
+//	@Tags			Chat
+//	@Accept			json
+//	@Produce		text/event-stream
+//	@Param			id		path	uint64					true	"消息 ID"
+//	@Param			request	body	chat.completionParams	true	"补全参数"
+//	@Router			/chat/completion/regenerate/{id} [post]
+func (h *Handler) RegenerateStream(c *gin.Context) {
+	var uri PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	var req completionParams
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+
+	// 查消息
+	message, err := h.Store.GetMessage(uri.ID)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "message not found")
+		return
+	}
+	// 验证用户对会话的所有权
+	if !h.Helper.CheckUserSession(ctx_utils.GetUserId(c), message.SessionID) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return
+	}
+
+	// 获取会话配置，旧版线性会话需要先串联为消息树
+	var session schema.Session
+	if err := h.Db.First(&session, "id = ?", message.SessionID).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "session not found")
+		return
+	}
+	if _, err := h.Store.LinkSessionMessages(&session); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	// 串联后重新读取父消息
+	if message, err = h.Store.GetMessage(uri.ID); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+
+	// 找到需要重新回答的用户消息
+	questionId := message.ID
+	if message.Role == "assistant" {
+		questionId = message.ParentID
+	}
+	question, err := h.Store.GetMessage(questionId)
+	if err != nil || question.Role != "user" || question.SessionID != session.ID {
+		ctx_utils.CustomError(c, http.StatusBadRequest, "no user message to regenerate")
+		return
+	}
+
+	h.streamCompletion(
+		c, &completionTask{
+			completionParams: req,
+			Session:          &session,
+			Question:         question.Content,
+			FileIDs:          question.FileIDs,
+			ContextLeafID:    question.ParentID,
+			ReplyParentID:    question.ID,
+			Messages: []schema.Message{
+				{SessionID: session.ID, Role: "assistant"},
+			},
+		},
+	)
+}
+
+// streamCompletion 执行流式补全任务：组装上下文、预插入消息、流式输出并在结束后保存结果
+func (h *Handler) streamCompletion(c *gin.Context, task *completionTask) {
+	session := task.Session
+	req := task.completionParams
+
+	// 检查套餐是否可使用该模型集合
+	allowed, err := services.GetPlanService().CanUseCollection(ctx_utils.GetUserId(c), req.ModelName)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	if !allowed {
+		ctx_utils.BizError(c, constants.BizErrPlanModelNotAllowed)
+		return
+	}
+
 	// 读取模型信息
-	modelInfo, err := services.GetModelCollectionService().GetRandomModelFromCollection(req.ModelName)
-	if err != nil || modelInfo == nil || modelInfo.Provider == nil {
+	candidates, err := services.GetModelCollectionService().GetModelCandidatesFromCollection(req.ModelName)
+	if err != nil || len(candidates) == 0 || candidates[0].Provider == nil {
 		ctx_utils.CustomError(c, 404, "model not found")
 		return
 	}
+	modelInfo := &candidates[0]
 	modelConfig := modelInfo.Config
 	// 获取供应商 base_url 和 api_key
 	providerInfo := h.Redis.FindProviderByName(modelInfo.Provider.Name)
@@ -71,16 +214,9 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 		return
 	}
 	providerBaseUrl := providerInfo.BaseURL
-	providerKey, idx := slice.Random(providerInfo.APIKeys)
-	if idx == -1 {
-		ctx_utils.HttpError(c, constants.ErrInternal)
-		return
-	}
-
-	// 获取会话配置
-	var session schema.Session
-	if err := h.Db.First(&session, "id = ?", uri.SessionId).Error; err != nil {
-		ctx_utils.CustomError(c, http.StatusNotFound, "session not found")
+	providerKey, err := services.GetAPIKeyService().PickAPIKey(providerInfo.APIKeys)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
@@ -105,12 +241,17 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 	}
 	var contextMessages []schema.Message
 	if enableContext {
-		contextSize := 50
+		// 条数上限，实际上下文由 token 预算裁剪
+		contextSize := maxContextMessages
+		if session.ContextSize > 0 {
+			contextSize = session.ContextSize
+		}
 		if bot != nil && bot.PromptSession.ContextSize > 0 {
 			// bot 上下文窗口配置优先
 			contextSize = bot.PromptSession.ContextSize
 		}
-		messages, err := h.Store.GetLatestMessages(session.ID, contextSize)
+		// 仅沿当前分支回溯上下文
+		messages, err := h.Store.GetBranchMessages(task.ContextLeafID, contextSize)
 		if err != nil {
 			ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to load context")
 			return
@@ -118,6 +259,58 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 		contextMessages = messages
 	}
 
+	// 滚动摘要：已被摘要覆盖的消息不再作为上下文，摘要以记忆的形式注入
+	var memory string
+	if enableContext && session.Summary != "" && session.SummaryMessageID > 0 {
+		idx := slice.IndexOf(
+			slice.Map(contextMessages, func(_ int, m schema.Message) uint64 { return m.ID }),
+			session.SummaryMessageID,
+		)
+		if idx >= 0 {
+			contextMessages = contextMessages[idx+1:]
+			memory = session.Summary
+		} else if onBranch, err := h.Store.IsMessageOnBranch(session.SummaryMessageID, task.ContextLeafID); err == nil && onBranch {
+			// 摘要覆盖的消息早于本次加载的上下文
+			memory = session.Summary
+		}
+		if memory != "" {
+			memory = "以下是本次对话早期内容的摘要，供回答时参考：\n" + memory
+		}
+	}
+
+	// 工具：bot 配置优先，其次为请求参数、会话配置
+	var toolNames *[]string
+	switch {
+	case bot != nil && bot.Tools != nil:
+		toolNames = (*[]string)(&bot.Tools)
+	case req.Tools != nil:
+		toolNames = req.Tools
+	case session.Tools != nil:
+		toolNames = (*[]string)(&session.Tools)
+	}
+
+	// 知识库：bot 配置优先，其次为会话配置
+	var knowledgeBaseId uint64
+	var knowledgeFromPreset bool
+	switch {
+	case bot != nil && bot.KnowledgeBaseID != nil:
+		knowledgeBaseId = *bot.KnowledgeBaseID
+		knowledgeFromPreset = true
+	case session.KnowledgeBaseID != nil:
+		knowledgeBaseId = *session.KnowledgeBaseID
+	}
+
+	// 附件
+	if err := h.checkAttachments(ctx_utils.GetUserId(c), task.FileIDs); err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	fileMap, err := h.loadMessageFiles(append(contextMessages, schema.Message{FileIDs: task.FileIDs}))
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to load attachments")
+		return
+	}
+
 	// 系统提示
 	var systemPrompt = ""
 	if session.SystemPrompt != "" {
@@ -149,122 +342,317 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 			)...,
 		)
 	}
-	// 标准格式消息 - 上下文消息
-	chatMessages = append(
-		chatMessages, slice.Map(
+	// 按模型上下文长度裁剪最早的上下文消息，附件按数量估算，避免为被裁剪的消息读取附件
+	budget := chat_utils.ContextBudget{
+		ContextLength: modelConfig.ContextLength,
+		ReserveTokens: getCompletionModelConfig(modelConfig).MaxTokens,
+	}
+	// 提问中链接的正文在裁剪后注入，按 token 预算预留
+	budget.ExtraTokens += services.GetURLFetchService().QuestionTokens(task.Question)
+	// 联网搜索结果在裁剪后注入，按搜索结果的 token 预算预留
+	if req.EnableSearch != nil && *req.EnableSearch {
+		budget.ExtraTokens += services.GetSearchService().TokenBudget()
+	}
+	// 知识库检索结果在裁剪后注入，按最大检索量预留
+	if knowledgeBaseId > 0 {
+		if kb, err := services.GetKnowledgeService().GetKnowledgeBase(knowledgeBaseId); err == nil {
+			budget.ExtraTokens += services.GetKnowledgeService().RetrievalTokens(kb)
+		}
+	}
+	if budget.ContextLength <= 0 {
+		budget.ContextLength = schema.DefaultModelConfig.ContextLength
+	}
+	toBudgetMessage := func(m schema.Message, content string) chat_utils.Message {
+		return chat_utils.Message{
+			Role:        m.Role,
+			Content:     content,
+			Attachments: make([]chat_utils.Attachment, len(m.FileIDs)),
+		}
+	}
+	fixedMessages := chatMessages
+	if memory != "" {
+		fixedMessages = append([]chat_utils.Message{chat_utils.SystemMessage(memory)}, chatMessages...)
+	}
+	keptMessages, promptTokens := chat_utils.FitContext(
+		budget, systemPrompt, fixedMessages,
+		slice.Map(
 			contextMessages, func(_ int, m schema.Message) chat_utils.Message {
-				return chat_utils.Message{
-					Role:    m.Role,
-					Content: m.Content,
-				}
+				return toBudgetMessage(m, m.Content)
 			},
-		)...,
+		),
+		toBudgetMessage(schema.Message{Role: "user", FileIDs: task.FileIDs}, task.Question),
 	)
+	contextMessages = contextMessages[len(contextMessages)-len(keptMessages):]
+
+	// 检查余额及额度是否足够支付提示词及预留的输出
+	usageService := services.GetUsageService()
+	if err := usageService.CheckQuota(ctx_utils.GetUserId(c), usageService.EstimateCharge(candidates, promptTokens+budget.ExtraTokens, budget.ReserveTokens)); err != nil {
+		var bizErr constants.BizError
+		if errors.As(err, &bizErr) {
+			ctx_utils.BizError(c, bizErr)
+		} else {
+			ctx_utils.HttpError(c, constants.ErrInternal)
+		}
+		return
+	}
+	// 附件按用户输入、由近及远的上下文消息的顺序占用内联额度，较早消息的附件不再内联
+	inlineBudget := int64(maxInlineTotalSize)
+	presignImages := canPresignImages(candidates)
+	inputAttachments, inputNotes := buildAttachments(c.Request.Context(), task.FileIDs, fileMap, modelConfig, presignImages, &inlineBudget)
+	contextChatMessages := make([]chat_utils.Message, len(contextMessages))
+	for i := len(contextMessages) - 1; i >= 0; i-- {
+		m := contextMessages[i]
+		messageBudget := &inlineBudget
+		if len(contextMessages)-i > inlineContextMessages {
+			messageBudget = new(int64)
+		}
+		attachments, notes := buildAttachments(c.Request.Context(), m.FileIDs, fileMap, modelConfig, presignImages, messageBudget)
+		contextChatMessages[i] = chat_utils.Message{
+			Role:        m.Role,
+			Content:     m.Content + notes,
+			Attachments: attachments,
+		}
+	}
+	// 标准格式消息 - 上下文消息
+	chatMessages = append(chatMessages, contextChatMessages...)
 	// 标准格式消息 - 用户输入
-	chatMessages = append(chatMessages, chat_utils.UserMessage(req.Question))
+	chatMessages = append(
+		chatMessages, chat_utils.Message{
+			Role:        "user",
+			Content:     task.Question + inputNotes,
+			Attachments: inputAttachments,
+		},
+	)
 
 	// 预先插入新对话，获取消息 ID
-	messages := []schema.Message{
-		{SessionID: session.ID, Role: "user", ModelID: modelInfo.ID},
-		{SessionID: session.ID, Role: "assistant", ModelID: modelInfo.ID},
+	messages := task.Messages
+	for i := range messages {
+		messages[i].ModelID = modelInfo.ID
 	}
-	if err := h.Store.CreateMessages(&messages); err != nil {
+	if err := h.Store.CreateMessageChain(task.ReplyParentID, &messages); err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create messages")
 		return
 	}
+	answer := &messages[len(messages)-1]
 
-	// 设置流式响应头
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-
-	chatEventChan := make(chan chat_utils.StreamEvent, 10)
-
+	// 生成过程与请求解耦：事件先写入 Redis 事件流，再转发给客户端，断线后可通过 ResumeStream 续传
+	run := &completionRun{
+		completionTask:      task,
+		UserID:              ctx_utils.GetUserId(c),
+		Bot:                 bot,
+		ToolNames:           toolNames,
+		KnowledgeBaseID:     knowledgeBaseId,
+		KnowledgeFromPreset: knowledgeFromPreset,
+		PromptTokens:        promptTokens,
+		Options: chat_utils.CompletionOptions{
+			Provider: chat_utils.Provider{
+				Type:     providerInfo.Type,
+				BaseUrl:  providerBaseUrl,
+				ApiKey:   providerKey.Key,
+				ApiKeyID: providerKey.ID,
+			},
+			ModelID:               modelInfo.ID,
+			Model:                 modelInfo.Name,
+			Fallbacks:             services.GetChatService().GetFallbackTargets(candidates[1:], getCompletionModelConfig),
+			OnAttempt:             services.GetModelCollectionService().RecordAttempt,
+			Messages:              chatMessages,
+			SystemPrompt:          systemPrompt,
+			Memory:                memory,
+			CompletionModelConfig: getCompletionModelConfig(modelConfig),
+			MaxToolSteps:          services.GetChatService().GetMaxToolSteps(),
+		},
+	}
+	// 在客户端获得消息 ID 前登记，确保随后的 StopCompletion 能够停止生成
+	run.stopCtx, run.stop = context.WithCancelCause(context.Background())
+	run.unregister = services.GetCompletionCancelService().Register(answer.ID, run.stop, completionStreamExpire)
 	// 发送事件 - ID
-	chatEventChan <- chat_utils.StreamEvent{
-		Type:    chat_utils.CommandEventType,
-		Content: "ID",
-		Metadata: map[string]uint64{
-			"q": messages[0].ID,
-			"a": messages[1].ID,
+	h.publishStreamEvent(
+		answer.ID, chat_utils.StreamEvent{
+			Type:    chat_utils.CommandEventType,
+			Content: "ID",
+			Metadata: map[string]uint64{
+				"q": answer.ParentID,
+				"a": answer.ID,
+			},
 		},
+	)
+	go h.runCompletion(run)
+
+	// 流式输出
+	h.relayCompletionStream(c, answer.ID, "0")
+}
+
+// ResumeStream
+//
+//	@Summary		续传流式输出
+//	@Description	客户端断线后重新连接回复消息的事件流，重放 Last-Event-ID 请求头（或 last_event_id 参数）之后的事件，并继续输出后续事件
+//	@Tags			Chat
+//	@Accept			json
+//	@Produce		text/event-stream
+//	@Param			id				path	uint64	true	"assistant 消息 ID（即 ID 事件中的 a）"
+//	@Param			Last-Event-ID	header	string	false	"最后收到的事件 ID"
+//	@Param			last_event_id	query	string	false	"最后收到的事件 ID，请求头优先"
+//	@Router			/chat/completion/resume/{id} [get]
+func (h *Handler) ResumeStream(c *gin.Context) {
+	var uri PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	lastEventId := c.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = c.Query("last_event_id")
 	}
 
-	// 执行结束后，根据是否有回答进行操作
-	var doneResp *chat_utils.DoneResponse
-	defer func() {
-		if doneResp != nil && (len(doneResp.Extra) > 0 || doneResp.Content != "") {
-			// 完成响应，记录消息
-			messages[0].Content = req.Question
-			messages[0].TokenUsage = doneResp.Usage.PromptTokens
-			messages[1].Content = doneResp.Content
-			messages[1].ReasoningContent = doneResp.ReasoningContent
-			messages[1].TokenUsage = doneResp.Usage.CompletionTokens
-			messages[1].Extra = datatypes.NewJSONType[map[string]any](doneResp.Extra)
-			messages[1].CreatedAt = time.Now()
-			if bot != nil {
-				messages[1].PresetID = bot.ID
-			}
-			// 更新预插入了的消息
-			if err := h.Store.UpdateMessages(
-				&messages,
-				"content",
-				"token_usage",
-				"reasoning_content",
-				"preset_id",
-				"extra",
-				"created_at",
-			); err != nil {
-				// do nothing
-			}
-			// 更新 session
-			if err := h.Db.Model(&schema.Session{}).Where("id = ?", session.ID).Update(
-				"last_active", time.Now(),
-			); err != nil {
-				// do nothing
-			}
+	// 查消息
+	message, err := h.Store.GetMessage(uri.ID)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "message not found")
+		return
+	}
+	// 验证用户对会话的所有权
+	if !h.Helper.CheckUserSession(ctx_utils.GetUserId(c), message.SessionID) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return
+	}
+	if !h.Redis.ExistsCompletionStream(message.ID) {
+		ctx_utils.CustomError(c, http.StatusNotFound, "stream not found")
+		return
+	}
 
-			// 更新用户 usage
-			usageTokens := doneResp.Usage.PromptTokens + doneResp.Usage.CompletionTokens*4
-			if err := h.Store.UpdateUserUsage(ctx_utils.GetUserId(c), -usageTokens); err != nil {
-				// do nothing
-			}
+	h.relayCompletionStream(c, message.ID, lastEventId)
+}
 
-			if session.NameType == schema.SessionNameTypeNone {
-				// 1. 首次对话，更新标题为用户输入，并限制长度为 25，若大于 25，加上 ...
-				if len(req.Question) > 25 {
-					session.Name = req.Question[:25] + "..."
-				} else {
-					session.Name = req.Question
-				}
-				if err := h.Db.Model(&session).Updates(
-					map[string]any{
-						"name":      session.Name,
-						"name_type": schema.SessionNameTypeTemp,
+// StopCompletion
+//
+//	@Summary		停止生成
+//	@Description	停止正在生成的回复，已输出的内容会被保存，并在消息 extra 中标记 stopped
+//	@Tags			Chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			message_id	path		uint64	true	"assistant 消息 ID（即 ID 事件中的 a）"
+//	@Success		200			{object}	entity.CommonResponse[bool]
+//	@Router			/chat/completion/stop/{message_id} [post]
+func (h *Handler) StopCompletion(c *gin.Context) {
+	var uri struct {
+		MessageID uint64 `uri:"message_id" binding:"required"`
+	}
+	if err := c.BindUri(&uri); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+
+	// 查消息
+	message, err := h.Store.GetMessage(uri.MessageID)
+	if err != nil || message.Role != "assistant" {
+		ctx_utils.CustomError(c, http.StatusNotFound, "message not found")
+		return
+	}
+	// 验证用户对会话的所有权
+	if !h.Helper.CheckUserSession(ctx_utils.GetUserId(c), message.SessionID) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return
+	}
+	if !services.GetCompletionCancelService().IsRunning(message.ID) {
+		ctx_utils.CustomError(c, http.StatusNotFound, "completion not running")
+		return
+	}
+
+	// 生成过程可能运行在其他实例上，由服务负责广播
+	if err := services.GetCompletionCancelService().Stop(message.ID); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to stop completion")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+const (
+	maxContextMessages        = 200              // 上下文消息条数上限
+	completionTimeout         = 10 * time.Minute // 单次补全的最长执行时间
+	completionStreamExpire    = 1 * time.Hour    // 生成过程中事件流的过期时间
+	completionStreamRetention = 10 * time.Minute // 生成结束后事件流的保留时间，用于断线续传
+)
+
+// completionRun 脱离请求上下文执行的补全过程
+type completionRun struct {
+	*completionTask
+	UserID              uint64
+	Bot                 *schema.Preset
+	ToolNames           *[]string // 指定的工具名称，nil 表示自动选择
+	KnowledgeBaseID     uint64    // 检索的知识库 ID，0 表示不检索
+	KnowledgeFromPreset bool      // 知识库由预设绑定，不校验用户的访问权限
+	PromptTokens        int64     // 组装上下文时估算的 prompt token 数
+	Options             chat_utils.CompletionOptions
+
+	stopCtx    context.Context         // 用户停止生成时取消
+	stop       context.CancelCauseFunc // 停止生成
+	unregister func()                  // 注销进行中的补全
+}
+
+// runCompletion 执行补全，将事件写入 Redis 事件流，并在结束后保存结果
+func (h *Handler) runCompletion(run *completionRun) {
+	answer := &run.Messages[len(run.Messages)-1]
+	stopCtx := run.stopCtx
+	defer run.stop(nil)
+	ctx, cancel := context.WithTimeout(stopCtx, completionTimeout)
+	defer cancel()
+
+	chatEventChan := make(chan chat_utils.StreamEvent, 10)
+	var citations []search_utils.Citation           // 联网搜索引用的来源，在发送 citations 事件前写入
+	var fetchedURLs []services.FetchedURL           // 读取的提问中的链接，在发送 fetched_urls 事件前写入
+	var knowledgeSources []services.KnowledgeSource // 知识库引用的分段，在发送 knowledge 事件前写入
+	go func() {
+		err := func() error {
+			// 读取提问中的链接
+			if len(services.ExtractURLs(run.Question, 1)) > 0 {
+				chatEventChan <- chat_utils.StreamEvent{
+					Type:    chat_utils.CommandEventType,
+					Content: "tooltip",
+					Metadata: map[string]string{
+						"tooltip": "读取链接中...",
 					},
-				); err != nil {
-					// do nothing
 				}
-				// 2. 执行标题生成
-				go func() {
-					err := services.GetChatService().GenerateTitleForSession(session.ID, 0, 1)
-					if err != nil {
-						// TODO: 记录错误
+				fetched := services.GetURLFetchService().FetchForQuestion(ctx, run.Question)
+				if fetched != nil {
+					if fetched.Prompt != "" {
+						run.Options.Messages = append(run.Options.Messages, chat_utils.UserMessage(fetched.Prompt))
 					}
-				}()
+					fetchedURLs = fetched.Sources
+					// 发送 cmd：读取的链接
+					chatEventChan <- chat_utils.StreamEvent{
+						Type:     chat_utils.CommandEventType,
+						Content:  "fetched_urls",
+						Metadata: fetched,
+					}
+				}
 			}
-		} else {
-			// 无响应，删除预插入的消息
-			if err := h.Store.DeleteMessages(session.ID, []uint64{messages[0].ID, messages[1].ID}); err != nil {
-				// do nothing
+
+			// 检索知识库
+			if run.KnowledgeBaseID > 0 {
+				chatEventChan <- chat_utils.StreamEvent{
+					Type:    chat_utils.CommandEventType,
+					Content: "tooltip",
+					Metadata: map[string]string{
+						"tooltip": "检索知识库中...",
+					},
+				}
+				knowledge, err := services.GetKnowledgeService().RetrieveForQuestion(ctx, run.UserID, run.KnowledgeBaseID, run.KnowledgeFromPreset, run.Question)
+				if err != nil {
+					slog.Default().Warn("failed to retrieve knowledge base", "knowledge_base_id", run.KnowledgeBaseID, "error", err.Error())
+				} else if knowledge != nil {
+					run.Options.Messages = append(run.Options.Messages, chat_utils.UserMessage(knowledge.Prompt))
+					knowledgeSources = knowledge.Sources
+					// 发送 cmd：引用的知识库分段
+					chatEventChan <- chat_utils.StreamEvent{
+						Type:     chat_utils.CommandEventType,
+						Content:  "knowledge",
+						Metadata: knowledge,
+					}
+				}
 			}
-		}
-	}()
 
-	go func() {
-		err := func() error {
 			// 搜索
-			if req.EnableSearch != nil && *req.EnableSearch == true {
+			if run.EnableSearch != nil && *run.EnableSearch == true {
 				chatEventChan <- chat_utils.StreamEvent{
 					Type:    chat_utils.CommandEventType,
 					Content: "tooltip",
@@ -272,122 +660,366 @@ func (h *Handler) CompletionStream(c *gin.Context) {
 						"tooltip": "联网搜索中...",
 					},
 				}
-				result, err := searchFromInternet(req.Question)
-				if err == nil && result != "" {
-					chatMessages = append(
-						chatMessages,
-						chat_utils.UserMessage("通过联网查询，你获得了这些信息："+result+"也许你可以参考这些信息解答我的问题"),
-					)
+				search, err := services.GetSearchService().SearchForQuestion(ctx, run.Question)
+				if err == nil && search != nil {
+					run.Options.Messages = append(run.Options.Messages, chat_utils.UserMessage(search.Prompt))
+					citations = search.Citations
+					// 发送 cmd：引用的来源
+					chatEventChan <- chat_utils.StreamEvent{
+						Type:     chat_utils.CommandEventType,
+						Content:  "citations",
+						Metadata: search,
+					}
 				}
 			}
 
-			// 意图识别引入工具
-			var tools []chat_utils.CompletionTool
-			if slice.Some(
-				[]string{"考", "测", "验", "题"},
-				func(i int, s string) bool { return strings.Contains(req.Question, s) },
-			) {
-				tools = append(tools, services.GetQuestionTools()...)
+			// 引入工具，未指定时按提问内容进行意图识别
+			if run.ToolNames == nil {
+				run.Options.Tools = append(run.Options.Tools, services.GetToolRegistryService().RouteTools(run.Question)...)
+			} else {
+				run.Options.Tools = append(run.Options.Tools, services.GetToolRegistryService().ResolveTools(*run.ToolNames)...)
 			}
 
-			err = chat_utils.CompletionStream(
-				c.Request.Context(), chat_utils.CompletionOptions{
-					Provider: chat_utils.Provider{
-						BaseUrl: providerBaseUrl,
-						ApiKey:  providerKey.Key,
-					},
-					Model:                 modelInfo.Name,
-					Messages:              chatMessages,
-					SystemPrompt:          systemPrompt,
-					CompletionModelConfig: getCompletionModelConfig(modelConfig),
-					Tools:                 tools,
-				},
-				chatEventChan,
-			)
-			if err != nil {
-				return err
-			}
-			return nil
+			return chat_utils.CompletionStream(ctx, run.Options, chatEventChan)
 		}()
 		if err != nil {
+			// 流式处理未启动，由此处关闭通道
 			chatEventChan <- chat_utils.StreamEvent{
 				Type:  chat_utils.ErrorEventType,
 				Error: err,
 			}
+			close(chatEventChan)
 		}
 	}()
 
-	// 流式输出
+	var doneResp *chat_utils.DoneResponse
+	var failedResp *chat_utils.DoneResponse // 出错前已产生的用量
+	var partialContent, partialReasoning strings.Builder
+	for event := range chatEventChan {
+		if errors.Is(context.Cause(stopCtx), services.ErrCompletionStopped) {
+			// 已停止生成，丢弃剩余事件，直到通道关闭
+			continue
+		}
+		switch event.Type {
+		case chat_utils.ContentEventType:
+			partialContent.WriteString(event.Content)
+		case chat_utils.ReasoningContentEventType:
+			partialReasoning.WriteString(event.Content)
+		case chat_utils.CommandEventType:
+			if metadata, ok := event.Metadata.(map[string]any); ok && event.Content == "model" {
+				// 故障转移后实际回答的模型
+				if modelId, ok := metadata["model_id"].(uint64); ok && modelId > 0 {
+					for i := range run.Messages {
+						run.Messages[i].ModelID = modelId
+					}
+				}
+			}
+		case chat_utils.ErrorEventType:
+			if resp, ok := event.Metadata.(chat_utils.DoneResponse); ok {
+				failedResp = &resp
+			}
+		case chat_utils.DoneEventType:
+			resp, ok := event.Metadata.(chat_utils.DoneResponse)
+			if ok {
+				resp.Usage.EstimatedPromptTokens = run.PromptTokens
+				if len(citations) > 0 {
+					if resp.Extra == nil {
+						resp.Extra = map[string]any{}
+					}
+					resp.Extra["citations"] = citations
+				}
+				if len(fetchedURLs) > 0 {
+					if resp.Extra == nil {
+						resp.Extra = map[string]any{}
+					}
+					resp.Extra["fetched_urls"] = fetchedURLs
+				}
+				if len(knowledgeSources) > 0 {
+					if resp.Extra == nil {
+						resp.Extra = map[string]any{}
+					}
+					resp.Extra["knowledge_sources"] = knowledgeSources
+				}
+				event.Metadata = resp
+				doneResp = &resp
+			}
+		}
+		h.publishStreamEvent(answer.ID, event)
+	}
+
+	if errors.Is(context.Cause(stopCtx), services.ErrCompletionStopped) && doneResp == nil {
+		// 用户停止生成，保存已输出的部分内容，用量按估算记录
+		doneResp = &chat_utils.DoneResponse{
+			Content:          partialContent.String(),
+			ReasoningContent: partialReasoning.String(),
+			Extra: map[string]any{
+				"stopped": true,
+			},
+			Usage: chat_utils.DoneResponseUsage{
+				PromptTokens: run.PromptTokens,
+				CompletionTokens: chat_utils.EstimateTokens(partialContent.String()) +
+					chat_utils.EstimateTokens(partialReasoning.String()),
+				EstimatedPromptTokens: run.PromptTokens,
+			},
+			ModelID: answer.ModelID,
+		}
+		if answer.ModelID == run.Options.ModelID {
+			doneResp.APIKeyID = run.Options.Provider.ApiKeyID
+		}
+		h.publishStreamEvent(
+			answer.ID, chat_utils.StreamEvent{
+				Type:    chat_utils.CommandEventType,
+				Content: "stopped",
+				Metadata: map[string]uint64{
+					"a": answer.ID,
+				},
+			},
+		)
+		h.publishStreamEvent(answer.ID, chat_utils.StreamEvent{Type: chat_utils.DoneEventType, Metadata: *doneResp})
+	}
+
+	// 结果已确定，此后不再接受停止请求
+	run.unregister()
+	h.saveCompletion(run, doneResp)
+	if doneResp == nil && failedResp != nil {
+		// 工具调用的后续请求失败，回答不保存，但之前的请求仍需计费
+		failedResp.Usage.EstimatedPromptTokens = run.PromptTokens
+		h.recordCompletionUsage(run, 0, *failedResp)
+	}
+	if err := h.Redis.ExpireCompletionStream(answer.ID, completionStreamRetention); err != nil {
+		// do nothing
+	}
+}
+
+// saveCompletion 根据是否有回答，保存或删除预插入的消息
+func (h *Handler) saveCompletion(run *completionRun, doneResp *chat_utils.DoneResponse) {
+	session := run.Session
+	messages := run.Messages
+	answer := &messages[len(messages)-1]
+	if doneResp != nil && (len(doneResp.Extra) > 0 || doneResp.Content != "") {
+		// 完成响应，记录消息
+		if len(messages) > 1 {
+			// 本次新建的提问消息
+			messages[0].Content = run.Question
+			messages[0].TokenUsage = doneResp.Usage.PromptTokens
+		}
+		answer.Content = doneResp.Content
+		answer.ReasoningContent = doneResp.ReasoningContent
+		answer.TokenUsage = doneResp.Usage.CompletionTokens
+		answer.Extra = datatypes.NewJSONType[map[string]any](doneResp.Extra)
+		answer.CreatedAt = time.Now()
+		if run.Bot != nil {
+			answer.PresetID = run.Bot.ID
+		}
+		// 更新预插入了的消息
+		if err := h.Store.UpdateMessages(
+			&messages,
+			"content",
+			"token_usage",
+			"reasoning_content",
+			"preset_id",
+			"model_id",
+			"extra",
+			"created_at",
+		); err != nil {
+			// do nothing
+		}
+		// 更新 session，并将当前分支切换到新的回复
+		if err := h.Db.Model(&schema.Session{}).Where("id = ?", session.ID).Updates(
+			map[string]any{
+				"last_active":        time.Now(),
+				"current_message_id": answer.ID,
+			},
+		); err != nil {
+			// do nothing
+		}
+
+		// 记录用量并扣减用户余额
+		h.recordCompletionUsage(run, answer.ID, *doneResp)
+
+		if session.NameType == schema.SessionNameTypeNone {
+			// 1. 首次对话，更新标题为用户输入，并限制长度为 25，若大于 25，加上 ...
+			if len(run.Question) > 25 {
+				session.Name = run.Question[:25] + "..."
+			} else {
+				session.Name = run.Question
+			}
+			if err := h.Db.Model(session).Updates(
+				map[string]any{
+					"name":      session.Name,
+					"name_type": schema.SessionNameTypeTemp,
+				},
+			); err != nil {
+				// do nothing
+			}
+			// 2. 执行标题生成
+			go func() {
+				err := services.GetChatService().GenerateTitleForSession(session.ID, 0, 1)
+				if err != nil {
+					// TODO: 记录错误
+				}
+			}()
+		}
+
+		// 分支过长时更新滚动摘要
+		go func() {
+			if err := services.GetChatService().UpdateSessionSummary(session.ID, answer.ID); err != nil {
+				slog.Default().Warn("failed to update session summary", "session", session.ID, "error", err.Error())
+			}
+		}()
+	} else {
+		// 无响应，删除预插入的消息
+		if err := h.Store.DeleteMessages(
+			session.ID, slice.Map(
+				messages, func(_ int, m schema.Message) uint64 {
+					return m.ID
+				},
+			),
+		); err != nil {
+			// do nothing
+		}
+	}
+}
+
+// recordCompletionUsage 记录补全用量并扣减用户余额，messageId 为 0 表示回答未保存
+func (h *Handler) recordCompletionUsage(run *completionRun, messageId uint64, doneResp chat_utils.DoneResponse) {
+	answer := &run.Messages[len(run.Messages)-1]
+	modelId := doneResp.ModelID
+	if modelId == 0 {
+		modelId = answer.ModelID
+	}
+	var presetId uint64
+	if run.Bot != nil {
+		presetId = run.Bot.ID
+	}
+	if _, err := services.GetUsageService().RecordUsage(
+		services.UsageRecordParams{
+			UserID:    run.UserID,
+			Source:    schema.UsageRecordSourceChat,
+			SessionID: run.Session.ID,
+			MessageID: messageId,
+			PresetID:  presetId,
+			ModelID:   modelId,
+			APIKeyID:  doneResp.APIKeyID,
+			Usage:     doneResp.Usage,
+		},
+	); err != nil {
+		// do nothing
+	}
+}
+
+// publishStreamEvent 将流式事件编码为 SSE 事件并写入回复消息的事件流
+func (h *Handler) publishStreamEvent(messageId uint64, event chat_utils.StreamEvent) {
+	for _, e := range encodeStreamEvent(event) {
+		data, ok := e.Data.(string)
+		if !ok {
+			dataBytes, err := json.Marshal(e.Data)
+			if err != nil {
+				continue
+			}
+			data = string(dataBytes)
+		}
+		if _, err := h.Redis.AppendCompletionEvent(messageId, e.Event, data, completionStreamExpire); err != nil {
+			// do nothing
+		}
+	}
+}
+
+// relayCompletionStream 从 Redis 事件流中读取 lastEventId 之后的事件并转发给客户端，直到流结束或客户端断开
+func (h *Handler) relayCompletionStream(c *gin.Context, messageId uint64, lastEventId string) {
+	// 设置流式响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
 	c.Stream(
 		func(w io.Writer) bool {
-			event, ok := <-chatEventChan
-			if !ok {
+			events, err := h.Redis.ReadCompletionEvents(c.Request.Context(), messageId, lastEventId, 30*time.Second)
+			if err != nil {
+				// 客户端断开或读取失败，生成过程不受影响
 				return false
 			}
-
-			switch event.Type {
-			case chat_utils.CommandEventType:
-				sendStreamCommandEvent(c, event.Content, event.Metadata)
-				return true
-			case chat_utils.ContentEventType:
-				// 消息内容
-				sendStreamMessageEvent(c, event.Content, false)
-				return true
-			case chat_utils.ReasoningContentEventType:
-				// 思考内容
-				sendStreamMessageEvent(c, event.Content, true)
-				return true
-			case chat_utils.ErrorEventType:
-				// 错误信息（记录日志并终止流）
-				c.SSEvent(
-					"error", (&entity.CommonResponse[any]{}).WithError(event.Error).WithCode(500),
-				)
-				return false
-			case chat_utils.DoneEventType:
-				// 用量信息
-				c.SSEvent("usage", event.Metadata)
-				resp, ok := event.Metadata.(chat_utils.DoneResponse)
-				if ok {
-					doneResp = &resp
+			if len(events) == 0 {
+				// 等待超时，事件流已过期则结束
+				return h.Redis.ExistsCompletionStream(messageId)
+			}
+			for _, e := range events {
+				c.Render(-1, sse.Event{Id: e.ID, Event: e.Event, Data: e.Data})
+				lastEventId = e.ID
+				if e.Event == streamEventDone || e.Event == streamEventError {
+					// 结束标记或错误信息，终止流
+					return false
 				}
-				// 结束标记
-				c.SSEvent("done", "[DONE]")
-				return false
-			default:
-				return true
 			}
+			return true
 		},
 	)
 }
 
-func sendStreamMessageEvent(c *gin.Context, msg string, thinking bool) {
+const (
+	streamEventDone  = "done"
+	streamEventError = "error"
+)
+
+// encodeStreamEvent 将流式事件转换为发送给客户端的 SSE 事件
+func encodeStreamEvent(event chat_utils.StreamEvent) []sse.Event {
+	switch event.Type {
+	case chat_utils.CommandEventType:
+		return []sse.Event{encodeStreamCommandEvent(event.Content, event.Metadata)}
+	case chat_utils.ContentEventType:
+		// 消息内容
+		return []sse.Event{encodeStreamMessageEvent(event.Content, false)}
+	case chat_utils.ReasoningContentEventType:
+		// 思考内容
+		return []sse.Event{encodeStreamMessageEvent(event.Content, true)}
+	case chat_utils.ErrorEventType:
+		// 错误信息
+		return []sse.Event{
+			{
+				Event: streamEventError,
+				Data:  (&entity.CommonResponse[any]{}).WithError(event.Error).WithCode(500),
+			},
+		}
+	case chat_utils.DoneEventType:
+		// 用量信息及结束标记
+		return []sse.Event{
+			{Event: "usage", Data: event.Metadata},
+			{Event: streamEventDone, Data: "[DONE]"},
+		}
+	default:
+		return nil
+	}
+}
+
+func encodeStreamMessageEvent(msg string, thinking bool) sse.Event {
 	var name string
 	if thinking {
 		name = "think"
 	} else {
 		name = "msg"
 	}
-	c.SSEvent(
-		name, gin.H{
+	return sse.Event{
+		Event: name,
+		Data: gin.H{
 			"content": msg,
 		},
-	)
+	}
 }
 
-// SendStreamCommandEvent 发送流式命令事件
-func sendStreamCommandEvent(c *gin.Context, cmd string, data interface{}) {
+// encodeStreamCommandEvent 编码流式命令事件
+func encodeStreamCommandEvent(cmd string, data interface{}) sse.Event {
 	dataString, ok := data.(string)
 	if ok {
 		// 字符串格式
-		c.SSEvent("cmd", fmt.Sprintf("[%s,%s]", cmd, dataString))
-	} else {
-		// JSON 格式
-		c.SSEvent(
-			"cmd", gin.H{
-				"name": cmd,
-				"data": data,
-			},
-		)
+		return sse.Event{Event: "cmd", Data: fmt.Sprintf("[%s,%s]", cmd, dataString)}
+	}
+	// JSON 格式
+	return sse.Event{
+		Event: "cmd",
+		Data: gin.H{
+			"name": cmd,
+			"data": data,
+		},
 	}
 }
 
@@ -406,73 +1038,3 @@ func getCompletionModelConfig(config schema.ModelConfig) chat_utils.CompletionMo
 		MaxTokens:   maxTokens,
 	}
 }
-
-func unescapeUnicode(raw []byte) (string, error) {
-	str, err := strconv.Unquote(strings.Replace(strconv.Quote(string(raw)), `\\u`, `\u`, -1))
-	if err != nil {
-		return "", err
-	}
-	return str, nil
-}
-func searchFromInternet(rawMessage string) (result string, err error) {
-	// 1. 提取搜索关键词
-	completion, _, err := services.BuiltinPresetCompletion(
-		services.ChatSearchKeywordGeneratePresetName, map[string]string{
-			"CONTENT": rawMessage,
-		},
-	)
-	if err != nil {
-		return
-	}
-	keyword := chat_utils.ExtractTagContent(completion, "search")
-	if keyword == "" {
-		return "", nil
-	}
-
-	// 2. 请求搜索服务
-	// 读取配置
-	config, err := services.GetSystemConfigService().GetConfig(services.ChatOnlineSearchServiceBaseURL)
-	if err != nil {
-		return "", err
-	}
-	var baseUrls []string
-	err = json.Unmarshal(config.Value, &baseUrls)
-	if err != nil || len(baseUrls) < 1 {
-		return "", err
-	}
-	baseUrl, _ := slice.Random(baseUrls)
-	// 创建 HTTP 请求
-	resp, err := http.Get(
-		fmt.Sprintf("%s/search?format=json&q=%s", baseUrl, url.QueryEscape(keyword)),
-	)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
-
-	// 3. 读取响应内容，将 unicode编码 转为文本
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-	result, err = unescapeUnicode(body)
-
-	// 4. 解析结果并返回
-	type searchResType struct {
-		Query  string `json:"query"`
-		Result []struct {
-			Title         string `json:"title"`
-			Url           string `json:"url"`
-			Content       string `json:"content"`
-			PublishedData string `json:"publishedDate"`
-			Engine        string `json:"engine"`
-		} `json:"results"`
-	}
-	var searchRes searchResType
-	err = json.Unmarshal([]byte(result), &searchRes)
-	if err != nil {
-		return "", err
-	}
-	resByte, _ := json.Marshal(searchRes)
-	return string(resByte), nil
-}
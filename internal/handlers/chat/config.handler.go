@@ -15,7 +15,7 @@ import (
 // GetModelConfig
 //
 //	@Summary		获取模型配置
-//	@Description	获取模型配置
+//	@Description	获取当前用户套餐可使用的模型配置
 //	@Tags			config
 //	@Accept			json
 //	@Produce		json
@@ -32,6 +32,17 @@ func (h *Handler) GetModelConfig(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrInternal)
 		return
 	}
+	// 仅返回套餐可使用的模型集合
+	allowed, err := services.GetPlanService().CollectionFilter(ctx_utils.GetUserId(c))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	modelConfig = slice.Filter(
+		modelConfig, func(_ int, model entity.ConfigChatModel) bool {
+			return allowed(model.Name)
+		},
+	)
 	ctx_utils.Success(c, modelConfig)
 }
 
@@ -61,3 +72,21 @@ func (h *Handler) GetBotConfig(c *gin.Context) {
 	)
 	ctx_utils.Success(c, cachedPresets)
 }
+
+// GetToolConfig
+//
+//	@Summary		获取工具配置
+//	@Description	获取已启用的对话工具，可用于会话或单条消息的工具选择
+//	@Tags			config
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[[]services.ToolInfo]
+//	@Router			/chat/config/tools [get]
+func (h *Handler) GetToolConfig(c *gin.Context) {
+	tools := slice.Filter(
+		services.GetToolRegistryService().ListTools(), func(_ int, tool services.ToolInfo) bool {
+			return tool.Enabled
+		},
+	)
+	ctx_utils.Success(c, tools)
+}
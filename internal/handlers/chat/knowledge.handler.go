@@ -0,0 +1,387 @@
+package chat
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/knowledge_utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// getKnowledgeBase 读取路径参数中的知识库，owned 为 true 时要求当前用户为创建者，否则可访问即可
+//
+// 失败时已写入响应，返回 nil
+func (h *Handler) getKnowledgeBase(c *gin.Context, owned bool) *schema.KnowledgeBase {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return nil
+	}
+	kb, err := services.GetKnowledgeService().GetKnowledgeBase(uri.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx_utils.HttpError(c, constants.ErrNotFound)
+			return nil
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return nil
+	}
+	userId := ctx_utils.GetUserId(c)
+	if (owned && kb.OwnerID != userId) || !services.GetKnowledgeService().CanAccess(userId, kb) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return nil
+	}
+	return kb
+}
+
+// getKnowledgeDocument 读取路径参数中的文档及其所属知识库，要求当前用户为知识库的创建者
+//
+// 失败时已写入响应，返回 nil
+func (h *Handler) getKnowledgeDocument(c *gin.Context) (*schema.KnowledgeDocument, *schema.KnowledgeBase) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return nil, nil
+	}
+	var doc schema.KnowledgeDocument
+	if err := h.Db.First(&doc, uri.ID).Error; err != nil {
+		ctx_utils.HttpError(c, constants.ErrNotFound)
+		return nil, nil
+	}
+	kb, err := services.GetKnowledgeService().GetKnowledgeBase(doc.KnowledgeBaseID)
+	if err != nil || kb.OwnerID != ctx_utils.GetUserId(c) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return nil, nil
+	}
+	return &doc, kb
+}
+
+// knowledgeEmbedError 将向量化前额度检查等错误转换为响应
+func knowledgeEmbedError(c *gin.Context, err error) {
+	var bizErr constants.BizError
+	switch {
+	case errors.As(err, &bizErr):
+		ctx_utils.BizError(c, bizErr)
+	case errors.Is(err, services.ErrNoEmbeddingModel):
+		ctx_utils.CustomError(c, http.StatusServiceUnavailable, err.Error())
+	default:
+		ctx_utils.CustomError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// validKnowledgeBaseUpdate 校验更新后的分段参数，未更新的字段沿用知识库原值
+func validKnowledgeBaseUpdate(kb *schema.KnowledgeBase, req *entity.ReqUpdateBody[schema.KnowledgeBase]) bool {
+	chunkSize, chunkOverlap, topK := kb.ChunkSize, kb.ChunkOverlap, kb.TopK
+	if slice.Contain(req.Updates, "chunk_size") {
+		chunkSize = req.Data.ChunkSize
+	}
+	if slice.Contain(req.Updates, "chunk_overlap") {
+		chunkOverlap = req.Data.ChunkOverlap
+	}
+	if slice.Contain(req.Updates, "top_k") {
+		topK = req.Data.TopK
+	}
+	return chunkSize > 0 && chunkSize <= services.KnowledgeMaxChunkSize &&
+		chunkOverlap >= 0 && chunkOverlap < chunkSize &&
+		topK > 0 && topK <= services.KnowledgeMaxTopK
+}
+
+// CreateKnowledgeBase
+//
+//	@Summary		创建知识库
+//	@Description	创建知识库，未指定向量化模型集合时使用系统配置的默认集合，创建后不可修改
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		chat.CreateKnowledgeBase.createRequest		true	"知识库参数"
+//	@Success		200	{object}	entity.CommonResponse[schema.KnowledgeBase]	"成功创建的知识库"
+//	@Router			/chat/knowledge/create [post]
+func (h *Handler) CreateKnowledgeBase(c *gin.Context) {
+	type createRequest struct {
+		Name                string  `json:"name" binding:"required,max=64"`
+		Description         string  `json:"description"`
+		EmbeddingCollection string  `json:"embedding_collection"` // 向量化模型集合，为空时使用默认集合
+		ChunkSize           int     `json:"chunk_size"`           // 分段大小（token），默认 500，最大 2000
+		ChunkOverlap        int     `json:"chunk_overlap"`        // 相邻分段的重叠大小（token），默认为分段大小的 1/10
+		TopK                int     `json:"top_k"`                // 每次检索的分段数量，默认 5
+		MinScore            float64 `json:"min_score"`            // 余弦相似度阈值
+	}
+	var req createRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	kb := schema.KnowledgeBase{
+		Name:                req.Name,
+		Description:         req.Description,
+		OwnerID:             ctx_utils.GetUserId(c),
+		EmbeddingCollection: req.EmbeddingCollection,
+		ChunkSize:           req.ChunkSize,
+		ChunkOverlap:        req.ChunkOverlap,
+		TopK:                req.TopK,
+		MinScore:            req.MinScore,
+	}
+	if err := services.GetKnowledgeService().CreateKnowledgeBase(&kb); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create knowledge base")
+		return
+	}
+	ctx_utils.Success(c, kb)
+}
+
+// GetKnowledgeBases
+//
+//	@Summary		获取知识库列表
+//	@Description	获取当前用户创建的知识库及公开的知识库
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[[]schema.KnowledgeBase]	"知识库列表"
+//	@Router			/chat/knowledge/list [get]
+func (h *Handler) GetKnowledgeBases(c *gin.Context) {
+	var kbs []schema.KnowledgeBase
+	if err := h.Db.Where("owner_id = ? OR is_public = ?", ctx_utils.GetUserId(c), true).
+		Order("created_at DESC").
+		Find(&kbs).Error; err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, kbs)
+}
+
+// GetKnowledgeBase
+//
+//	@Summary		获取知识库
+//	@Description	获取当前用户可访问的知识库
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64										true	"知识库 ID"
+//	@Success		200	{object}	entity.CommonResponse[schema.KnowledgeBase]	"知识库"
+//	@Router			/chat/knowledge/{id} [get]
+func (h *Handler) GetKnowledgeBase(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, false)
+	if kb == nil {
+		return
+	}
+	ctx_utils.Success(c, kb)
+}
+
+// UpdateKnowledgeBase
+//
+//	@Summary		更新知识库
+//	@Description	更新当前用户创建的知识库，分段参数仅对之后处理的文档生效
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64										true	"知识库 ID"
+//	@Param			req	body		entity.ReqUpdateBody[schema.KnowledgeBase]	true	"知识库参数"
+//	@Success		200	{object}	entity.CommonResponse[bool]					"更新成功与否"
+//	@Router			/chat/knowledge/{id}/update [post]
+func (h *Handler) UpdateKnowledgeBase(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, true)
+	if kb == nil {
+		return
+	}
+	var req entity.ReqUpdateBody[schema.KnowledgeBase]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	req.Data.ID = kb.ID
+	req.WithWhitelist("name", "description", "chunk_size", "chunk_overlap", "top_k", "min_score")
+	if len(req.Updates) == 0 || !validKnowledgeBaseUpdate(kb, &req) {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := h.Db.Select(req.Updates).Updates(&req.Data).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update knowledge base")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// DeleteKnowledgeBase
+//
+//	@Summary		删除知识库
+//	@Description	删除当前用户创建的知识库及其全部文档
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"知识库 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"删除成功与否"
+//	@Router			/chat/knowledge/{id}/delete [post]
+func (h *Handler) DeleteKnowledgeBase(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, true)
+	if kb == nil {
+		return
+	}
+	if err := services.GetKnowledgeService().DeleteKnowledgeBase(kb.ID); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to delete knowledge base")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// UploadKnowledgeDocument
+//
+//	@Summary		上传知识库文档
+//	@Description	上传文本、Markdown、HTML 或 PDF 文档，正文提取后在后台分段及向量化，可通过文档列表查看处理状态
+//	@Tags			Knowledge
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		uint64											true	"知识库 ID"
+//	@Param			file	formData	file											true	"文档"
+//	@Success		200		{object}	entity.CommonResponse[schema.KnowledgeDocument]	"上传的文档"
+//	@Router			/chat/knowledge/{id}/document/upload [post]
+func (h *Handler) UploadKnowledgeDocument(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, true)
+	if kb == nil {
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	// 多读取一个字节以判断是否超出大小限制
+	data, err := io.ReadAll(io.LimitReader(file, services.KnowledgeMaxDocumentBytes+1))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+
+	doc, err := services.GetKnowledgeService().AddDocument(kb, header.Filename, header.Header.Get("Content-Type"), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrKnowledgeDocumentTooLarge):
+			ctx_utils.CustomError(c, http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, knowledge_utils.ErrUnsupportedDocument), errors.Is(err, knowledge_utils.ErrEmptyDocument):
+			ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		case errors.As(err, new(constants.BizError)), errors.Is(err, services.ErrNoEmbeddingModel):
+			knowledgeEmbedError(c, err)
+		default:
+			ctx_utils.CustomError(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		return
+	}
+	ctx_utils.Success(c, doc)
+}
+
+// GetKnowledgeDocuments
+//
+//	@Summary		获取知识库文档列表
+//	@Description	获取知识库中的文档及其处理状态
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64												true	"知识库 ID"
+//	@Success		200	{object}	entity.CommonResponse[[]schema.KnowledgeDocument]	"文档列表"
+//	@Router			/chat/knowledge/{id}/document/list [get]
+func (h *Handler) GetKnowledgeDocuments(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, false)
+	if kb == nil {
+		return
+	}
+	var docs []schema.KnowledgeDocument
+	if err := h.Db.Where("knowledge_base_id = ?", kb.ID).Order("created_at DESC").Find(&docs).Error; err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, docs)
+}
+
+// ReprocessKnowledgeDocument
+//
+//	@Summary		重新处理知识库文档
+//	@Description	按知识库当前的分段参数重新分段及向量化文档，用于处理失败后重试
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"文档 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"提交成功与否"
+//	@Router			/chat/knowledge/document/{id}/reprocess [post]
+func (h *Handler) ReprocessKnowledgeDocument(c *gin.Context) {
+	doc, kb := h.getKnowledgeDocument(c)
+	if doc == nil {
+		return
+	}
+	if doc.Status == schema.KnowledgeDocumentStatusProcessing {
+		ctx_utils.CustomError(c, http.StatusConflict, "document is being processed")
+		return
+	}
+	if err := services.GetKnowledgeService().ReprocessDocument(kb, doc); err != nil {
+		knowledgeEmbedError(c, err)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// DeleteKnowledgeDocument
+//
+//	@Summary		删除知识库文档
+//	@Description	删除文档及其分段
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"文档 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"删除成功与否"
+//	@Router			/chat/knowledge/document/{id}/delete [post]
+func (h *Handler) DeleteKnowledgeDocument(c *gin.Context) {
+	doc, _ := h.getKnowledgeDocument(c)
+	if doc == nil {
+		return
+	}
+	if err := services.GetKnowledgeService().DeleteDocument(doc); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// SearchKnowledgeBase
+//
+//	@Summary		检索知识库
+//	@Description	检索知识库中与查询最相关的分段，用于调试分段及检索参数
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64												true	"知识库 ID"
+//	@Param			req	body		chat.SearchKnowledgeBase.searchRequest				true	"查询"
+//	@Success		200	{object}	entity.CommonResponse[[]services.RetrievedChunk]	"检索结果"
+//	@Router			/chat/knowledge/{id}/search [post]
+func (h *Handler) SearchKnowledgeBase(c *gin.Context) {
+	kb := h.getKnowledgeBase(c, false)
+	if kb == nil {
+		return
+	}
+	type searchRequest struct {
+		Query string `json:"query" binding:"required"`
+	}
+	var req searchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	chunks, err := services.GetKnowledgeService().Retrieve(c.Request.Context(), kb, ctx_utils.GetUserId(c), req.Query)
+	if err != nil {
+		knowledgeEmbedError(c, err)
+		return
+	}
+	ctx_utils.Success(c, chunks)
+}
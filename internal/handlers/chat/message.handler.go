@@ -8,6 +8,7 @@ import (
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/datatypes"
+	"net/http"
 )
 
 // GetMessages
@@ -52,6 +53,7 @@ func (h *Handler) GetMessages(c *gin.Context) {
 		modelMap[m.ModelID] = m.Model.Name
 		messages[i].Model = nil // 不直接返回模型信息
 	}
+	h.fillMessageFiles(messages)
 	ctx_utils.Success(
 		c, &ChatMessageListResponse{
 			PaginatedContinuationResponse: entity.NewPaginatedContinuationResponse(messages, nextPage),
@@ -113,6 +115,7 @@ func (h *Handler) GetSharedMessages(c *gin.Context) {
 		modelMap[m.ModelID] = m.Model.Name
 		messages[i].Model = nil // 不直接返回模型信息
 	}
+	h.fillMessageFiles(messages)
 	ctx_utils.Success(
 		c, &ChatMessageListResponse{
 			PaginatedContinuationResponse: entity.NewPaginatedContinuationResponse(messages, nextPage),
@@ -169,3 +172,84 @@ func (h *Handler) UpdateMessage(c *gin.Context) {
 
 	ctx_utils.Success(c, true)
 }
+
+// EditMessageStream
+//
+//	@Summary		编辑消息并重新发送
+//	@Description	编辑一条历史用户消息，编辑后的消息作为原消息的兄弟分支保存并流式生成新的回复，原分支保持不变
+//	@Tags			Message
+//	@Accept			json
+//	@Produce		text/event-stream
+//	@Param			id		path	uint64								true	"用户消息 ID"
+//	@Param			request	body	chat.EditMessageStream.editInput	true	"编辑后的内容及参数"
+//	@Router			/chat/message/{id}/edit [post]
+func (h *Handler) EditMessageStream(c *gin.Context) {
+	var uri PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	type editInput struct {
+		Question string   `json:"question" binding:"required"` // 编辑后的提问内容
+		FileIDs  []uint64 `json:"file_ids" binding:"-"`        // 编辑后的附件文件 ID，不传则沿用原消息附件
+		completionParams
+	}
+	var req editInput
+	if err := c.ShouldBindJSON(&req); err != nil || req.Question == "" {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+
+	// 查消息
+	message, err := h.Store.GetMessage(uri.ID)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "message not found")
+		return
+	}
+	// 验证用户对会话的所有权
+	if !h.Helper.CheckUserSession(ctx_utils.GetUserId(c), message.SessionID) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return
+	}
+	if message.Role != "user" {
+		ctx_utils.CustomError(c, http.StatusBadRequest, "only user message can be edited")
+		return
+	}
+
+	// 获取会话配置，旧版线性会话需要先串联为消息树
+	var session schema.Session
+	if err := h.Db.First(&session, "id = ?", message.SessionID).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "session not found")
+		return
+	}
+	if _, err := h.Store.LinkSessionMessages(&session); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	// 串联后重新读取父消息
+	if message, err = h.Store.GetMessage(uri.ID); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+
+	fileIds := req.FileIDs
+	if fileIds == nil {
+		fileIds = message.FileIDs
+	}
+
+	// 编辑后的消息与原消息挂载在同一父消息下
+	h.streamCompletion(
+		c, &completionTask{
+			completionParams: req.completionParams,
+			Session:          &session,
+			Question:         req.Question,
+			FileIDs:          fileIds,
+			ContextLeafID:    message.ParentID,
+			ReplyParentID:    message.ParentID,
+			Messages: []schema.Message{
+				{SessionID: session.ID, Role: "user", FileIDs: fileIds},
+				{SessionID: session.ID, Role: "assistant"},
+			},
+		},
+	)
+}
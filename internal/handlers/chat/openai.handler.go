@@ -0,0 +1,521 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/duke-git/lancet/v2/random"
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// openAIChatRequest OpenAI 格式的补全请求，仅解析网关支持的字段
+type openAIChatRequest struct {
+	Model         string              `json:"model" binding:"required"`
+	Messages      []openAIChatMessage `json:"messages" binding:"required,min=1,dive"`
+	Stream        bool                `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+	Temperature         *float64 `json:"temperature,omitempty"`
+	MaxTokens           *int64   `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int64   `json:"max_completion_tokens,omitempty"`
+}
+
+// openAIChatMessage OpenAI 格式的消息，content 可以是字符串或内容片段数组
+type openAIChatMessage struct {
+	Role    string          `json:"role" binding:"required"`
+	Content json.RawMessage `json:"content" swaggertype:"string"`
+}
+
+// openAIContentPart OpenAI 格式的内容片段
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// openAIUsage OpenAI 格式的用量
+type openAIUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// openAIChatCompletion 非流式的补全结果
+type openAIChatCompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   openAIUsage    `json:"usage"`
+}
+
+type openAIChoice struct {
+	Index        int                   `json:"index"`
+	Message      openAIResponseMessage `json:"message"`
+	FinishReason string                `json:"finish_reason"`
+}
+
+// openAIResponseMessage 模型回复的消息，流式分片中作为 delta
+type openAIResponseMessage struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+// openAIChatCompletionChunk 流式补全的分片
+type openAIChatCompletionChunk struct {
+	ID      string              `json:"id"`
+	Object  string              `json:"object"`
+	Created int64               `json:"created"`
+	Model   string              `json:"model"`
+	Choices []openAIChunkChoice `json:"choices"`
+	Usage   *openAIUsage        `json:"usage,omitempty"`
+}
+
+type openAIChunkChoice struct {
+	Index        int                   `json:"index"`
+	Delta        openAIResponseMessage `json:"delta"`
+	FinishReason *string               `json:"finish_reason"`
+}
+
+// openAIModel OpenAI 格式的模型信息
+type openAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// openAIModelList OpenAI 格式的模型列表
+type openAIModelList struct {
+	Object string        `json:"object"`
+	Data   []openAIModel `json:"data"`
+}
+
+// openAIError 以 OpenAI 的错误格式响应，便于 SDK 正确解析
+func openAIError(c *gin.Context, status int, errType string, message string) {
+	c.AbortWithStatusJSON(
+		status, gin.H{
+			"error": gin.H{
+				"message": message,
+				"type":    errType,
+				"code":    nil,
+			},
+		},
+	)
+}
+
+// openAIProviderError 将提供商的错误转换为 OpenAI 格式响应
+func openAIProviderError(c *gin.Context, err error) {
+	status := chat_utils.ErrorStatusCode(err)
+	if status < http.StatusBadRequest {
+		status = http.StatusBadGateway
+	}
+	openAIError(c, status, "api_error", err.Error())
+}
+
+// convertOpenAIMessages 将 OpenAI 格式的消息转换为内部消息，system/developer 消息合并为系统提示词
+func convertOpenAIMessages(messages []openAIChatMessage) (string, []chat_utils.Message, error) {
+	var systemPrompts []string
+	result := make([]chat_utils.Message, 0, len(messages))
+	for _, m := range messages {
+		content, attachments, err := parseOpenAIContent(m.Content)
+		if err != nil {
+			return "", nil, err
+		}
+		switch m.Role {
+		case "system", "developer":
+			systemPrompts = append(systemPrompts, content)
+		case "user":
+			result = append(result, chat_utils.Message{Role: "user", Content: content, Attachments: attachments})
+		case "assistant":
+			result = append(result, chat_utils.AssistantMessage(content))
+		default:
+			return "", nil, fmt.Errorf("unsupported message role: %s", m.Role)
+		}
+	}
+	if len(result) == 0 {
+		return "", nil, errors.New("messages must contain at least one user or assistant message")
+	}
+	return strings.Join(systemPrompts, "\n\n"), result, nil
+}
+
+// parseOpenAIContent 解析消息内容，图片片段转换为附件
+func parseOpenAIContent(raw json.RawMessage) (string, []chat_utils.Attachment, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+	var parts []openAIContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, errors.New("content must be a string or an array of content parts")
+	}
+	var texts []string
+	var attachments []chat_utils.Attachment
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "image_url":
+			if part.ImageURL == nil || part.ImageURL.URL == "" {
+				return "", nil, errors.New("image_url.url is required")
+			}
+			attachments = append(
+				attachments, chat_utils.Attachment{
+					Name:     "image",
+					MimeType: guessImageMimeType(part.ImageURL.URL),
+					URL:      part.ImageURL.URL,
+				},
+			)
+		default:
+			return "", nil, fmt.Errorf("unsupported content part type: %s", part.Type)
+		}
+	}
+	return strings.Join(texts, "\n"), attachments, nil
+}
+
+// guessImageMimeType 从 data URL 或链接后缀推断图片类型，无法推断时按 jpeg 处理
+func guessImageMimeType(rawUrl string) string {
+	if rest, ok := strings.CutPrefix(rawUrl, "data:"); ok {
+		if meta, _, found := strings.Cut(rest, ";"); found && strings.HasPrefix(meta, "image/") {
+			return meta
+		}
+	} else if u, err := url.Parse(rawUrl); err == nil {
+		if mimeType := mime.TypeByExtension(path.Ext(u.Path)); strings.HasPrefix(mimeType, "image/") {
+			return mimeType
+		}
+	}
+	return "image/jpeg"
+}
+
+// OpenAIChatCompletions
+//
+//	@Summary		OpenAI 兼容的补全接口
+//	@Description	接受 OpenAI 格式的请求，model 为模型集合名称，stream 为 true 时以 chat.completion.chunk 格式流式输出，用量计入当前用户
+//	@Tags			OpenAI
+//	@Accept			json
+//	@Produce		json,text/event-stream
+//	@Param			request	body		chat.openAIChatRequest	true	"OpenAI 格式的补全请求"
+//	@Success		200		{object}	chat.openAIChatCompletion
+//	@Router			/v1/chat/completions [post]
+func (h *Handler) OpenAIChatCompletions(c *gin.Context) {
+	var req openAIChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	systemPrompt, messages, err := convertOpenAIMessages(req.Messages)
+	if err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	// 检查套餐是否可使用该模型集合
+	userId := ctx_utils.GetUserId(c)
+	allowed, err := services.GetPlanService().CanUseCollection(userId, req.Model)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	if !allowed {
+		openAIError(c, constants.BizErrPlanModelNotAllowed.HttpCode, "model_not_allowed", constants.BizErrPlanModelNotAllowed.Msg)
+		return
+	}
+
+	// 通过模型集合解析模型
+	candidates, err := services.GetModelCollectionService().GetModelCandidatesFromCollection(req.Model)
+	if err != nil || len(candidates) == 0 || candidates[0].Provider == nil {
+		openAIError(c, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("the model `%s` does not exist", req.Model))
+		return
+	}
+	modelInfo := candidates[0]
+	apiKey, err := services.GetAPIKeyService().PickAPIKey(modelInfo.Provider.APIKeys)
+	if err != nil {
+		openAIError(c, http.StatusServiceUnavailable, "api_error", err.Error())
+		return
+	}
+
+	// 请求参数覆盖模型的默认配置，备用模型同样适用
+	modelConfig := func(config schema.ModelConfig) chat_utils.CompletionModelConfig {
+		completionConfig := getCompletionModelConfig(config)
+		if req.Temperature != nil {
+			completionConfig.Temperature = *req.Temperature
+		}
+		if req.MaxCompletionTokens != nil {
+			completionConfig.MaxTokens = *req.MaxCompletionTokens
+		} else if req.MaxTokens != nil {
+			completionConfig.MaxTokens = *req.MaxTokens
+		}
+		return completionConfig
+	}
+
+	// 检查余额及额度是否足够支付提示词及预留的输出
+	usageService := services.GetUsageService()
+	promptTokens := chat_utils.EstimateTokens(systemPrompt) + chat_utils.EstimateMessagesTokens(messages)
+	if err := usageService.CheckQuota(userId, usageService.EstimateCharge(candidates, promptTokens, modelConfig(modelInfo.Config).MaxTokens)); err != nil {
+		var bizErr constants.BizError
+		if errors.As(err, &bizErr) {
+			openAIError(c, bizErr.HttpCode, "insufficient_quota", bizErr.Msg)
+		} else {
+			openAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+		}
+		return
+	}
+
+	opts := chat_utils.GetCommonCompletionOptions(
+		modelInfo, apiKey, chat_utils.CompletionOptions{
+			Messages:              messages,
+			SystemPrompt:          systemPrompt,
+			CompletionModelConfig: modelConfig(modelInfo.Config),
+		},
+	)
+	opts.Fallbacks = services.GetChatService().GetFallbackTargets(candidates[1:], modelConfig)
+	opts.OnAttempt = services.GetModelCollectionService().RecordAttempt
+
+	// 生成不随客户端断开而取消，保证补全结束后总能计费
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), completionTimeout)
+	eventChan := make(chan chat_utils.StreamEvent)
+	if err := chat_utils.CompletionStream(ctx, opts, eventChan); err != nil {
+		cancel()
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	id := "chatcmpl-" + random.RandString(24)
+	created := time.Now().Unix()
+	if req.Stream {
+		includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		h.streamOpenAICompletion(c, eventChan, cancel, id, created, req.Model, includeUsage, userId)
+		return
+	}
+	defer cancel()
+
+	// 非流式：等待补全结束
+	var doneResp *chat_utils.DoneResponse
+	for event := range eventChan {
+		switch event.Type {
+		case chat_utils.ErrorEventType:
+			if partial, ok := event.Metadata.(chat_utils.DoneResponse); ok {
+				// 出错前的请求已产生用量
+				h.chargeOpenAIUsage(userId, partial)
+			}
+			openAIProviderError(c, event.Error)
+			return
+		case chat_utils.DoneEventType:
+			if resp, ok := event.Metadata.(chat_utils.DoneResponse); ok {
+				doneResp = &resp
+			}
+		}
+	}
+	if doneResp == nil {
+		openAIError(c, http.StatusBadGateway, "api_error", "completion interrupted")
+		return
+	}
+	usage := h.chargeOpenAIUsage(userId, *doneResp)
+	resp := openAIChatCompletion{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   req.Model,
+		Choices: []openAIChoice{
+			{
+				Message: openAIResponseMessage{
+					Role:             "assistant",
+					Content:          doneResp.Content,
+					ReasoningContent: doneResp.ReasoningContent,
+				},
+				FinishReason: "stop",
+			},
+		},
+		Usage: usage,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// streamOpenAICompletion 以 chat.completion.chunk 格式转发补全事件
+//
+// 在输出首个分片前出错时以普通的错误响应返回，输出开始后的错误以 error 对象写入流中。
+// 客户端提前断开时在后台消费剩余事件，并在补全结束后计费，最后调用 cancel 释放生成上下文
+func (h *Handler) streamOpenAICompletion(c *gin.Context, eventChan <-chan chat_utils.StreamEvent, cancel context.CancelFunc, id string, created int64, model string, includeUsage bool, userId uint64) {
+	started := false
+	charged := false
+	newChunk := func() openAIChatCompletionChunk {
+		return openAIChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []openAIChunkChoice{},
+		}
+	}
+	write := func(w io.Writer, data any) {
+		if !started {
+			started = true
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Status(http.StatusOK)
+			// 首个分片带上角色
+			first := newChunk()
+			first.Choices = append(first.Choices, openAIChunkChoice{})
+			first.Choices[0].Delta.Role = "assistant"
+			writeOpenAIChunk(w, first)
+		}
+		writeOpenAIChunk(w, data)
+	}
+
+	c.Stream(
+		func(w io.Writer) bool {
+			event, ok := <-eventChan
+			if !ok {
+				return false
+			}
+			switch event.Type {
+			case chat_utils.ContentEventType, chat_utils.ReasoningContentEventType:
+				chunk := newChunk()
+				chunk.Choices = append(chunk.Choices, openAIChunkChoice{})
+				if event.Type == chat_utils.ContentEventType {
+					chunk.Choices[0].Delta.Content = event.Content
+				} else {
+					chunk.Choices[0].Delta.ReasoningContent = event.Content
+				}
+				write(w, chunk)
+			case chat_utils.ErrorEventType:
+				if partial, ok := event.Metadata.(chat_utils.DoneResponse); ok {
+					// 出错前的请求已产生用量
+					h.chargeOpenAIUsage(userId, partial)
+					charged = true
+				}
+				if !started {
+					openAIProviderError(c, event.Error)
+					return false
+				}
+				write(w, gin.H{"error": gin.H{"message": event.Error.Error(), "type": "api_error", "code": nil}})
+				return false
+			case chat_utils.DoneEventType:
+				doneResp, _ := event.Metadata.(chat_utils.DoneResponse)
+				usage := h.chargeOpenAIUsage(userId, doneResp)
+				charged = true
+				finishReason := "stop"
+				chunk := newChunk()
+				chunk.Choices = append(chunk.Choices, openAIChunkChoice{FinishReason: &finishReason})
+				write(w, chunk)
+				if includeUsage {
+					chunk = newChunk()
+					chunk.Usage = &usage
+					write(w, chunk)
+				}
+				_, _ = io.WriteString(w, "data: [DONE]\n\n")
+				return false
+			}
+			return true
+		},
+	)
+	// 客户端断开时消费剩余事件，避免补全协程阻塞，并在补全结束时计费
+	go func() {
+		defer cancel()
+		for event := range eventChan {
+			// 完成事件及带有用量的错误事件均需计费
+			doneResp, ok := event.Metadata.(chat_utils.DoneResponse)
+			if !charged && (event.Type == chat_utils.DoneEventType || event.Type == chat_utils.ErrorEventType && ok) {
+				h.chargeOpenAIUsage(userId, doneResp)
+				charged = true
+			}
+		}
+	}()
+}
+
+// writeOpenAIChunk 写入一条 SSE data 行
+func writeOpenAIChunk(w io.Writer, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", raw)
+}
+
+// chargeOpenAIUsage 记录用量并扣减用户余额，计费方式与对话接口一致
+func (h *Handler) chargeOpenAIUsage(userId uint64, doneResp chat_utils.DoneResponse) openAIUsage {
+	usage := doneResp.Usage
+	if _, err := services.GetUsageService().RecordUsage(
+		services.UsageRecordParams{
+			UserID:   userId,
+			Source:   schema.UsageRecordSourceOpenAI,
+			ModelID:  doneResp.ModelID,
+			APIKeyID: doneResp.APIKeyID,
+			Usage:    usage,
+		},
+	); err != nil {
+		// do nothing
+	}
+	return openAIUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.PromptTokens + usage.CompletionTokens,
+	}
+}
+
+// OpenAIListModels
+//
+//	@Summary		OpenAI 兼容的模型列表
+//	@Description	列出可用的模型集合，id 可作为补全接口的 model 参数
+//	@Tags			OpenAI
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	chat.openAIModelList
+//	@Router			/v1/models [get]
+func (h *Handler) OpenAIListModels(c *gin.Context) {
+	config, err := services.GetSystemConfigService().GetConfig(services.ConfigAvailableChatModelCollection)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	var modelConfig []entity.ConfigChatModel
+	if err := json.Unmarshal(config.Value, &modelConfig); err != nil {
+		openAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	allowed, err := services.GetPlanService().CollectionFilter(ctx_utils.GetUserId(c))
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	created := config.CreatedAt.Unix()
+	models := make([]openAIModel, 0, len(modelConfig))
+	for _, m := range modelConfig {
+		if !allowed(m.Name) {
+			continue
+		}
+		models = append(
+			models, openAIModel{
+				ID:      m.Name,
+				Object:  "model",
+				Created: created,
+				OwnedBy: "open-chat",
+			},
+		)
+	}
+	c.JSON(http.StatusOK, openAIModelList{Object: "list", Data: models})
+}
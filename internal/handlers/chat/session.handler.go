@@ -1,9 +1,11 @@
 package chat
 
 import (
+	"github.com/duke-git/lancet/v2/slice"
 	"github.com/fcraft/open-chat/internal/constants"
 	"github.com/fcraft/open-chat/internal/entity"
 	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -282,7 +284,15 @@ func (h *Handler) UpdateSession(c *gin.Context) {
 		ctx_utils.BizError(c, constants.BizErrNoPermission)
 		return
 	}
-	req.WithWhitelist("name", "system_prompt")
+	req.WithWhitelist("name", "system_prompt", "tools", "knowledge_base_id")
+	// 仅可挂载当前用户可访问的知识库
+	if slice.Contain(req.Updates, "knowledge_base_id") && req.Data.KnowledgeBaseID != nil && *req.Data.KnowledgeBaseID > 0 {
+		kb, err := services.GetKnowledgeService().GetKnowledgeBase(*req.Data.KnowledgeBaseID)
+		if err != nil || !services.GetKnowledgeService().CanAccess(ctx_utils.GetUserId(c), kb) {
+			ctx_utils.BizError(c, constants.BizErrNoPermission)
+			return
+		}
+	}
 	if err := h.Db.Omit("LastActive").Select(req.Updates).Updates(&req.Data).Error; err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update session")
 		return
@@ -331,6 +341,55 @@ func (h *Handler) UpdateSessionFlag(c *gin.Context) {
 	ctx_utils.Success(c, true)
 }
 
+// SwitchSessionBranch
+//
+//	@Summary		切换会话分支
+//	@Description	选中会话中的某条消息所在分支，后续对话及上下文将沿该分支（该消息下最新的回复路径）继续
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			session_id	path		string									true	"会话 ID"
+//	@Param			req			body		chat.SwitchSessionBranch.BranchRequest	true	"分支消息"
+//	@Success		200			{object}	entity.CommonResponse[uint64]			"切换后分支末端消息 ID"
+//	@Router			/chat/session/branch/{session_id} [post]
+func (h *Handler) SwitchSessionBranch(c *gin.Context) {
+	var uri PathParamSessionId
+	if err := c.BindUri(&uri); err != nil || uri.SessionId == "" {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	type BranchRequest struct {
+		MessageID uint64 `json:"message_id" binding:"required"` // 分支上的任意消息 ID
+	}
+	var req BranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	// 验证用户对会话的所有权
+	if !h.Helper.CheckUserSession(ctx_utils.GetUserId(c), uri.SessionId) {
+		ctx_utils.BizError(c, constants.BizErrNoPermission)
+		return
+	}
+	// 验证消息属于该会话
+	message, err := h.Store.GetMessage(req.MessageID)
+	if err != nil || message.SessionID != uri.SessionId {
+		ctx_utils.BizError(c, constants.BizErrNoRecord)
+		return
+	}
+	// 查找分支末端并切换
+	leafId, err := h.Store.FindBranchLeaf(message.ID)
+	if err != nil || leafId == 0 {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	if err := h.Store.UpdateSessionCurrentMessage(uri.SessionId, leafId); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to switch branch")
+		return
+	}
+	ctx_utils.Success(c, leafId)
+}
+
 // ShareSession
 //
 //	@Summary		分享会话
@@ -6,6 +6,7 @@ import (
 	"github.com/fcraft/open-chat/internal/schema"
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
 	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/fcraft/open-chat/internal/utils/storage_utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -26,6 +27,10 @@ func (h *Handler) CreateBucket(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrBadRequest)
 		return
 	}
+	if _, err := storage_utils.GetBackend(&bucket); err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err := h.Db.Create(&bucket).Error; err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create bucket")
 		return
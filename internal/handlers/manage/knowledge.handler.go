@@ -0,0 +1,114 @@
+package manage
+
+import (
+	"net/http"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetKnowledgeBases
+//
+//	@Summary		分页获取知识库
+//	@Description	分页获取全部用户的知识库，可按创建者筛选
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			req			query		entity.ParamPagingSort														true	"分页参数"
+//	@Param			owner_id	query		uint64																		false	"创建者用户 ID"
+//	@Success		200			{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.KnowledgeBase]]	"知识库列表"
+//	@Router			/manage/knowledge/list [get]
+func (h *Handler) GetKnowledgeBases(c *gin.Context) {
+	type knowledgeFilter struct {
+		OwnerID *uint64 `form:"owner_id"`
+	}
+	var param entity.ParamPagingSort
+	var filter knowledgeFilter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	tx := h.Db
+	if filter.OwnerID != nil {
+		tx = tx.Where("owner_id = ?", *filter.OwnerID)
+	}
+	kbs, total, err := gorm_utils.GetByPageTotal[schema.KnowledgeBase](tx, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.KnowledgeBase]{
+			List:  kbs,
+			Total: total,
+		},
+	)
+}
+
+// UpdateKnowledgeBase
+//
+//	@Summary		更新知识库
+//	@Description	更新任意知识库，可设置是否对所有用户公开
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64										true	"知识库 ID"
+//	@Param			req	body		entity.ReqUpdateBody[schema.KnowledgeBase]	true	"知识库参数"
+//	@Success		200	{object}	entity.CommonResponse[bool]					"更新成功与否"
+//	@Router			/manage/knowledge/{id}/update [post]
+func (h *Handler) UpdateKnowledgeBase(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	var req entity.ReqUpdateBody[schema.KnowledgeBase]
+	if err := c.ShouldBindJSON(&req); err != nil || req.Data.TopK < 0 || req.Data.TopK > services.KnowledgeMaxTopK {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	req.WithWhitelist("name", "description", "is_public", "top_k", "min_score")
+	if len(req.Updates) == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	req.Data.ID = uri.ID
+	if err := h.Db.Select(req.Updates).Updates(&req.Data).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update knowledge base")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// DeleteKnowledgeBase
+//
+//	@Summary		删除知识库
+//	@Description	删除任意知识库及其全部文档
+//	@Tags			Knowledge
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"知识库 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"删除成功与否"
+//	@Router			/manage/knowledge/{id}/delete [post]
+func (h *Handler) DeleteKnowledgeBase(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetKnowledgeService().DeleteKnowledgeBase(uri.ID); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to delete knowledge base")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
@@ -1,6 +1,7 @@
 package manage
 
 import (
+	"github.com/duke-git/lancet/v2/slice"
 	"github.com/fcraft/open-chat/internal/constants"
 	"github.com/fcraft/open-chat/internal/entity"
 	"github.com/fcraft/open-chat/internal/schema"
@@ -60,6 +61,70 @@ func (h *Handler) GetModel(c *gin.Context) {
 	ctx_utils.Success(c, model)
 }
 
+// ModelHealthResponse 模型连通性检测结果
+type ModelHealthResponse struct {
+	ModelID     uint64                    `json:"model_id"`
+	Unhealthy   bool                      `json:"unhealthy"`    // 是否已被标记为不健康，不健康的模型不参与集合路由
+	SuccessRate float64                   `json:"success_rate"` // 返回记录中的成功率
+	History     []schema.ModelHealthCheck `json:"history"`      // 最近的检测记录，按时间倒序
+}
+
+// GetModelHealth
+//
+//	@Summary		获取模型健康状态
+//	@Description	获取模型的连通性检测结果（由定时任务 detect_model_connection 定期检测）
+//	@Tags			Model
+//	@Accept			json
+//	@Produce		json
+//	@Param			model_id	path		uint64										true	"Model ID"
+//	@Param			limit		query		int											false	"返回的记录数，默认 50，最大 500"
+//	@Success		200			{object}	entity.CommonResponse[ModelHealthResponse]	"健康状态"
+//	@Router			/manage/model/{model_id}/health [get]
+func (h *Handler) GetModelHealth(c *gin.Context) {
+	var uri struct {
+		ModelId uint64 `uri:"model_id" binding:"required"`
+	}
+	if err := c.BindUri(&uri); err != nil || uri.ModelId == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	var req struct {
+		Limit int `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	req.Limit = min(req.Limit, 500)
+
+	model, err := h.Store.GetModel(uri.ModelId)
+	if err != nil {
+		ctx_utils.CustomError(c, 404, "model not found")
+		return
+	}
+	var history []schema.ModelHealthCheck
+	if err := h.Db.Where("model_id = ?", uri.ModelId).Order("id DESC").Limit(req.Limit).Find(&history).Error; err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	var successRate float64
+	if len(history) > 0 {
+		successRate = float64(len(slice.Filter(history, func(_ int, r schema.ModelHealthCheck) bool { return r.Success }))) /
+			float64(len(history))
+	}
+	ctx_utils.Success(
+		c, ModelHealthResponse{
+			ModelID:     model.ID,
+			Unhealthy:   model.Unhealthy,
+			SuccessRate: successRate,
+			History:     history,
+		},
+	)
+}
+
 // GetModels
 //
 //	@Summary		批量获取模型
@@ -217,10 +282,22 @@ func (h *Handler) CreateModelCollection(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrBadRequest)
 		return
 	}
+	if _, ok := services.GetBalanceStrategy(collection.Strategy); !ok {
+		ctx_utils.CustomError(c, http.StatusBadRequest, "unknown balance strategy")
+		return
+	}
+	// 负载均衡参数需在关联模型创建后更新
+	settings := collection.ModelSettings
+	collection.ModelSettings = nil
 	if err := gorm_utils.Save[schema.ModelCollection](h.Db, &collection); err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create model collection")
 		return
 	}
+	if err := h.Store.UpdateCollectionModelSettings(collection.ID, settings); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update collection model settings")
+		return
+	}
+	collection.ModelSettings = settings
 	ctx_utils.Success(c, collection)
 }
 
@@ -247,6 +324,12 @@ func (h *Handler) UpdateModelCollection(c *gin.Context) {
 		return
 	}
 	role.Data.ID = uri.ID
+	if slice.Contain(role.Updates, "strategy") {
+		if _, ok := services.GetBalanceStrategy(role.Data.Strategy); !ok {
+			ctx_utils.CustomError(c, http.StatusBadRequest, "unknown balance strategy")
+			return
+		}
+	}
 	// 更新模型列表
 	if role.Data.Models != nil {
 		if err := h.Db.Model(&role.Data).Association("Models").Replace(role.Data.Models); err != nil {
@@ -254,8 +337,15 @@ func (h *Handler) UpdateModelCollection(c *gin.Context) {
 			return
 		}
 	}
+	// 更新模型的负载均衡参数
+	if role.Data.ModelSettings != nil {
+		if err := h.Store.UpdateCollectionModelSettings(uri.ID, role.Data.ModelSettings); err != nil {
+			ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update collection model settings")
+			return
+		}
+	}
 	// 更新其它信息
-	if err := h.Db.Select(role.Updates).Omit("Models").Updates(&role.Data).Error; err != nil {
+	if err := h.Db.Select(role.Updates).Omit("Models", "ModelSettings").Updates(&role.Data).Error; err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update collection")
 		return
 	}
@@ -283,7 +373,7 @@ func (h *Handler) GetModelCollection(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrBadRequest)
 		return
 	}
-	model, err := gorm_utils.GetByID[schema.ModelCollection](h.Db.Preload("Models"), uri.ID)
+	model, err := gorm_utils.GetByID[schema.ModelCollection](h.Db.Preload("Models").Preload("ModelSettings"), uri.ID)
 	if err != nil {
 		ctx_utils.CustomError(c, 404, "model collection not found")
 		return
@@ -308,7 +398,7 @@ func (h *Handler) GetModelCollections(c *gin.Context) {
 		return
 	}
 	models, total, err := gorm_utils.GetByPageTotal[schema.ModelCollection](
-		h.Db.Preload("Models"),
+		h.Db.Preload("Models").Preload("ModelSettings"),
 		req.PagingParam,
 		req.SortParam,
 	)
@@ -324,6 +414,37 @@ func (h *Handler) GetModelCollections(c *gin.Context) {
 	)
 }
 
+// GetModelCollectionStats
+//
+//	@Summary		获取模型集合的实时统计
+//	@Description	获取集合内各模型的实时调用统计（请求数、错误数、延迟及错误率的滑动平均），用于观察负载均衡效果
+//	@Tags			ModelCollection
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64												true	"ModelCollection ID"
+//	@Success		200	{object}	entity.CommonResponse[map[uint64]redis.ModelStats]	"模型 ID -> 统计数据"
+//	@Router			/manage/collection/{id}/stats [get]
+func (h *Handler) GetModelCollectionStats(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	collection, err := gorm_utils.GetByID[schema.ModelCollection](h.Db.Preload("Models"), uri.ID)
+	if err != nil {
+		ctx_utils.CustomError(c, 404, "model collection not found")
+		return
+	}
+	stats, err := h.Redis.GetModelStats(
+		slice.Map(collection.Models, func(_ int, m schema.Model) uint64 { return m.ID }),
+	)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, stats)
+}
+
 // DeleteModelCollection
 //
 //	@Summary		删除模型集合
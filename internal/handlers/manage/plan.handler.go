@@ -0,0 +1,300 @@
+package manage
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPlans
+//
+//	@Summary		分页获取套餐
+//	@Description	分页获取订阅套餐
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		entity.ParamPagingSort												true	"分页参数"
+//	@Success		200	{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.Plan]]	"套餐列表"
+//	@Router			/manage/plan/list [get]
+func (h *Handler) GetPlans(c *gin.Context) {
+	var param entity.ParamPagingSort
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("priority ASC", "id")
+	plans, total, err := gorm_utils.GetByPageTotal[schema.Plan](h.Db, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.Plan]{
+			List:  plans,
+			Total: total,
+		},
+	)
+}
+
+// CreatePlan
+//
+//	@Summary		创建套餐
+//	@Description	创建订阅套餐，model_collections 为空表示可使用全部模型集合
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			plan	body		schema.Plan							true	"套餐参数"
+//	@Success		200		{object}	entity.CommonResponse[schema.Plan]	"成功创建的套餐"
+//	@Router			/manage/plan/create [post]
+func (h *Handler) CreatePlan(c *gin.Context) {
+	var plan schema.Plan
+	if err := c.ShouldBindJSON(&plan); err != nil || plan.Name == "" || plan.MonthlyTokens < 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := h.Db.Create(&plan).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create plan")
+		return
+	}
+	ctx_utils.Success(c, plan)
+}
+
+// UpdatePlan
+//
+//	@Summary		更新套餐
+//	@Description	更新订阅套餐，月度额度的变更在下次重置时生效
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		uint64								true	"套餐 ID"
+//	@Param			plan	body		entity.ReqUpdateBody[schema.Plan]	true	"套餐参数"
+//	@Success		200		{object}	entity.CommonResponse[bool]			"更新成功与否"
+//	@Router			/manage/plan/{id}/update [post]
+func (h *Handler) UpdatePlan(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	var plan entity.ReqUpdateBody[schema.Plan]
+	if err := c.ShouldBindJSON(&plan); err != nil || plan.Data.MonthlyTokens < 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	plan.WithWhitelist("display_name", "description", "monthly_tokens", "model_collections", "priority", "is_default")
+	if len(plan.Updates) == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	plan.Data.ID = uri.ID
+	if err := h.Db.Select(plan.Updates).Updates(&plan.Data).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update plan")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// DeletePlan
+//
+//	@Summary		删除套餐
+//	@Description	删除订阅套餐，使用该套餐的用户及角色回退到其它套餐
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"套餐 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"删除成功与否"
+//	@Router			/manage/plan/{id}/delete [post]
+func (h *Handler) DeletePlan(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	err := h.Db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Where("plan_id = ?", uri.ID).Delete(&schema.UserPlan{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("plan_id = ?", uri.ID).Delete(&schema.RolePlan{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schema.Plan{}, uri.ID).Error
+		},
+	)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to delete plan")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// AssignUserPlan
+//
+//	@Summary		为用户分配套餐
+//	@Description	为用户分配套餐，优先于角色套餐，立即按新套餐设置本月额度
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		manage.AssignUserPlan.assignRequest	true	"分配参数"
+//	@Success		200	{object}	entity.CommonResponse[bool]			"分配成功与否"
+//	@Router			/manage/plan/assign/user [post]
+func (h *Handler) AssignUserPlan(c *gin.Context) {
+	type assignRequest struct {
+		UserID    uint64     `json:"user_id" binding:"required"`
+		PlanID    uint64     `json:"plan_id" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"` // 过期时间，为空表示永不过期
+	}
+	var req assignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := h.Db.First(&schema.User{}, req.UserID).Error; err != nil {
+		ctx_utils.CustomError(c, http.StatusNotFound, "user not found")
+		return
+	}
+	err := services.GetPlanService().AssignUserPlan(req.UserID, req.PlanID, req.ExpiresAt, ctx_utils.GetUserId(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx_utils.CustomError(c, http.StatusNotFound, "plan not found")
+			return
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// UnassignUserPlan
+//
+//	@Summary		取消用户套餐
+//	@Description	取消直接分配给用户的套餐，下月起按角色套餐或默认套餐重置额度
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		manage.UnassignUserPlan.unassignRequest	true	"用户"
+//	@Success		200	{object}	entity.CommonResponse[bool]				"取消成功与否"
+//	@Router			/manage/plan/unassign/user [post]
+func (h *Handler) UnassignUserPlan(c *gin.Context) {
+	type unassignRequest struct {
+		UserID uint64 `json:"user_id" binding:"required"`
+	}
+	var req unassignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetPlanService().UnassignUserPlan(req.UserID); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// AssignRolePlan
+//
+//	@Summary		为角色分配套餐
+//	@Description	为角色分配套餐，拥有多个角色的用户取优先级最高的套餐，额度变更在下次重置时生效
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		manage.AssignRolePlan.assignRequest	true	"分配参数"
+//	@Success		200	{object}	entity.CommonResponse[bool]			"分配成功与否"
+//	@Router			/manage/plan/assign/role [post]
+func (h *Handler) AssignRolePlan(c *gin.Context) {
+	type assignRequest struct {
+		RoleID uint64 `json:"role_id" binding:"required"`
+		PlanID uint64 `json:"plan_id" binding:"required"`
+	}
+	var req assignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetPlanService().AssignRolePlan(req.RoleID, req.PlanID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx_utils.CustomError(c, http.StatusNotFound, "role or plan not found")
+			return
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// UnassignRolePlan
+//
+//	@Summary		取消角色套餐
+//	@Description	取消分配给角色的套餐
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		manage.UnassignRolePlan.unassignRequest	true	"角色"
+//	@Success		200	{object}	entity.CommonResponse[bool]				"取消成功与否"
+//	@Router			/manage/plan/unassign/role [post]
+func (h *Handler) UnassignRolePlan(c *gin.Context) {
+	type unassignRequest struct {
+		RoleID uint64 `json:"role_id" binding:"required"`
+	}
+	var req unassignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetPlanService().UnassignRolePlan(req.RoleID); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// GetRolePlans
+//
+//	@Summary		获取角色套餐
+//	@Description	获取全部角色的套餐分配
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[[]schema.RolePlan]	"角色套餐列表"
+//	@Router			/manage/plan/role/list [get]
+func (h *Handler) GetRolePlans(c *gin.Context) {
+	var rolePlans []schema.RolePlan
+	if err := h.Db.Preload("Plan").Find(&rolePlans).Error; err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, rolePlans)
+}
+
+// GetUserPlan
+//
+//	@Summary		获取用户套餐
+//	@Description	获取用户当前生效的套餐，未配置任何套餐时为空
+//	@Tags			Plan
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64								true	"用户 ID"
+//	@Success		200	{object}	entity.CommonResponse[schema.Plan]	"当前套餐"
+//	@Router			/manage/plan/user/{id} [get]
+func (h *Handler) GetUserPlan(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	plan, err := services.GetPlanService().GetUserPlan(uri.ID)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, plan)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/fcraft/open-chat/internal/entity"
 	_ "github.com/fcraft/open-chat/internal/entity"
 	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
 	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
 	"github.com/gin-gonic/gin"
@@ -27,6 +29,11 @@ func (h *Handler) CreateProvider(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrBadRequest)
 		return
 	}
+	// 校验协议类型
+	if _, err := chat_utils.GetProviderAdapter(provider.Type); err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err := h.Store.AddProvider(&provider); err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to create provider")
 		return
@@ -134,6 +141,11 @@ func (h *Handler) UpdateProvider(c *gin.Context) {
 		ctx_utils.HttpError(c, constants.ErrBadRequest)
 		return
 	}
+	// 校验协议类型
+	if _, err := chat_utils.GetProviderAdapter(provider.Type); err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	provider.ID = uri.ID
 	if err := h.Store.UpdateProvider(&provider); err != nil {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to update provider")
@@ -217,7 +229,7 @@ func (h *Handler) DeleteAPIKey(c *gin.Context) {
 // GetAPIKeyByProvider
 //
 //	@Summary		列出APIKey
-//	@Description	列出供应商的 APIKey
+//	@Description	列出供应商的 APIKey，并附带健康状态（active/cooling/disabled）、连续失败次数、冷却结束时间及各类失败的累计次数
 //	@Tags			APIKey
 //	@Accept			json
 //	@Produce		json
@@ -245,6 +257,9 @@ func (h *Handler) GetAPIKeyByProvider(c *gin.Context) {
 		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to load keys")
 		return
 	}
+	if err := services.GetAPIKeyService().FillHealth(apiKeys); err != nil {
+		// 健康状态仅用于展示，不影响列表
+	}
 	ctx_utils.Success(
 		c, entity.PaginatedTotalResponse[schema.APIKey]{
 			List:  apiKeys,
@@ -252,3 +267,26 @@ func (h *Handler) GetAPIKeyByProvider(c *gin.Context) {
 		},
 	)
 }
+
+// EnableAPIKey
+//
+//	@Summary		启用 APIKey
+//	@Description	重新启用因鉴权失败而被自动停用的 APIKey，并清除其健康数据
+//	@Tags			APIKey
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"API Key ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"启用成功与否"
+//	@Router			/manage/key/{id}/enable [post]
+func (h *Handler) EnableAPIKey(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetAPIKeyService().EnableAPIKey(uri.ID); err != nil {
+		ctx_utils.CustomError(c, http.StatusInternalServerError, "failed to enable key")
+		return
+	}
+	ctx_utils.Success(c, true)
+}
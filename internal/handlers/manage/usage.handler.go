@@ -0,0 +1,58 @@
+package manage
+
+import (
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsageRecords
+//
+//	@Summary		分页获取用量流水
+//	@Description	分页获取全部用户的用量流水，可按用户及来源筛选
+//	@Tags			Usage
+//	@Accept			json
+//	@Produce		json
+//	@Param			req		query		entity.ParamPagingSort														true	"分页参数"
+//	@Param			user_id	query		uint64																		false	"用户 ID"
+//	@Param			source	query		string																		false	"来源：chat/openai/preset"
+//	@Success		200		{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.UsageRecord]]	"用量流水"
+//	@Router			/manage/usage/records [get]
+func (h *Handler) GetUsageRecords(c *gin.Context) {
+	type usageFilter struct {
+		UserID *uint64 `form:"user_id"`
+		Source string  `form:"source"`
+	}
+	var param entity.ParamPagingSort
+	var filter usageFilter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	tx := h.Db
+	if filter.UserID != nil {
+		tx = tx.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Source != "" {
+		tx = tx.Where("source = ?", filter.Source)
+	}
+	records, total, err := gorm_utils.GetByPageTotal[schema.UsageRecord](tx, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.UsageRecord]{
+			List:  records,
+			Total: total,
+		},
+	)
+}
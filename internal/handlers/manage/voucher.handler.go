@@ -0,0 +1,230 @@
+package manage
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateVoucherBatchResponse 生成兑换码批次的结果
+type CreateVoucherBatchResponse struct {
+	Batch    schema.VoucherBatch `json:"batch"`
+	Vouchers []schema.Voucher    `json:"vouchers"`
+}
+
+// CreateVoucherBatch
+//
+//	@Summary		生成兑换码批次
+//	@Description	批量生成兑换码，每个兑换码可被不同用户兑换 max_redemptions 次
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		manage.CreateVoucherBatch.createRequest						true	"批次参数"
+//	@Success		200	{object}	entity.CommonResponse[manage.CreateVoucherBatchResponse]	"生成的批次及兑换码"
+//	@Router			/manage/voucher/batch/create [post]
+func (h *Handler) CreateVoucherBatch(c *gin.Context) {
+	type createRequest struct {
+		Name           string     `json:"name" binding:"required,max=64"`
+		Value          int64      `json:"value" binding:"required,min=1"`          // 每次兑换增加的额度
+		Count          int        `json:"count" binding:"required,min=1,max=1000"` // 生成数量
+		MaxRedemptions int        `json:"max_redemptions" binding:"min=0"`         // 每个兑换码可兑换次数，默认 1
+		ExpiresAt      *time.Time `json:"expires_at"`                              // 过期时间，为空表示永不过期
+	}
+	var req createRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	batch, vouchers, err := services.GetVoucherService().CreateBatch(
+		services.CreateBatchParams{
+			Name:           req.Name,
+			Value:          req.Value,
+			Count:          req.Count,
+			MaxRedemptions: req.MaxRedemptions,
+			ExpiresAt:      req.ExpiresAt,
+			CreatedBy:      ctx_utils.GetUserId(c),
+		},
+	)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx_utils.Success(c, CreateVoucherBatchResponse{Batch: *batch, Vouchers: vouchers})
+}
+
+// GetVoucherBatches
+//
+//	@Summary		分页获取兑换码批次
+//	@Description	分页获取兑换码批次
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		entity.ParamPagingSort														true	"分页参数"
+//	@Success		200	{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.VoucherBatch]]	"批次列表"
+//	@Router			/manage/voucher/batch/list [get]
+func (h *Handler) GetVoucherBatches(c *gin.Context) {
+	var param entity.ParamPagingSort
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	batches, total, err := gorm_utils.GetByPageTotal[schema.VoucherBatch](h.Db, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.VoucherBatch]{
+			List:  batches,
+			Total: total,
+		},
+	)
+}
+
+// GetVoucherBatchCodes
+//
+//	@Summary		分页获取批次内的兑换码
+//	@Description	分页获取批次内的兑换码及兑换次数
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64																	true	"批次 ID"
+//	@Param			req	query		entity.ParamPagingSort													true	"分页参数"
+//	@Success		200	{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.Voucher]]	"兑换码列表"
+//	@Router			/manage/voucher/batch/{id}/codes [get]
+func (h *Handler) GetVoucherBatchCodes(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	var param entity.ParamPagingSort
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("id ASC", "id")
+	vouchers, total, err := gorm_utils.GetByPageTotal[schema.Voucher](
+		h.Db.Where("batch_id = ?", uri.ID),
+		param.PagingParam,
+		param.SortParam,
+	)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.Voucher]{
+			List:  vouchers,
+			Total: total,
+		},
+	)
+}
+
+// DisableVoucherBatch
+//
+//	@Summary		停用兑换码批次
+//	@Description	停用批次内的全部兑换码，已兑换的额度不受影响
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"批次 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"停用成功与否"
+//	@Router			/manage/voucher/batch/{id}/disable [post]
+func (h *Handler) DisableVoucherBatch(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetVoucherService().DisableBatch(uri.ID); err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// DisableVoucher
+//
+//	@Summary		停用兑换码
+//	@Description	停用单个兑换码，已兑换的额度不受影响
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"兑换码 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"停用成功与否"
+//	@Router			/manage/voucher/{id}/disable [post]
+func (h *Handler) DisableVoucher(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetVoucherService().DisableVoucher(uri.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx_utils.HttpError(c, constants.ErrNotFound)
+			return
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// GetVoucherRedemptions
+//
+//	@Summary		分页获取兑换记录
+//	@Description	分页获取兑换记录，可按用户及批次筛选
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			req			query		entity.ParamPagingSort															true	"分页参数"
+//	@Param			user_id		query		uint64																			false	"用户 ID"
+//	@Param			batch_id	query		uint64																			false	"批次 ID"
+//	@Success		200			{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.VoucherRedemption]]	"兑换记录"
+//	@Router			/manage/voucher/redemptions [get]
+func (h *Handler) GetVoucherRedemptions(c *gin.Context) {
+	type redemptionFilter struct {
+		UserID  *uint64 `form:"user_id"`
+		BatchID *uint64 `form:"batch_id"`
+	}
+	var param entity.ParamPagingSort
+	var filter redemptionFilter
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	tx := h.Db
+	if filter.UserID != nil {
+		tx = tx.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.BatchID != nil {
+		tx = tx.Where("batch_id = ?", *filter.BatchID)
+	}
+	redemptions, total, err := gorm_utils.GetByPageTotal[schema.VoucherRedemption](tx, param.PagingParam, param.SortParam)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.VoucherRedemption]{
+			List:  redemptions,
+			Total: total,
+		},
+	)
+}
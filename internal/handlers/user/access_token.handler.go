@@ -0,0 +1,114 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccessTokenResponse 创建令牌的结果，令牌明文仅返回这一次
+type CreateAccessTokenResponse struct {
+	Token string `json:"token"`
+	schema.AccessToken
+}
+
+// CreateAccessToken
+//
+//	@Summary		创建个人访问令牌
+//	@Description	创建用于脚本及第三方集成的访问令牌，权限范围须为当前用户已拥有的权限路径，令牌明文仅返回一次
+//	@Tags			AccessToken
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		user.CreateAccessToken.createRequest					true	"令牌信息"
+//	@Success		200	{object}	entity.CommonResponse[user.CreateAccessTokenResponse]	"创建成功"
+//	@Router			/user/token/create [post]
+func (h *Handler) CreateAccessToken(c *gin.Context) {
+	type createRequest struct {
+		Name      string     `json:"name" binding:"required,max=64"`
+		Scopes    []string   `json:"scopes" binding:"required,min=1"` // 权限路径，形如 POST:/v1/chat/completions
+		ExpiresAt *time.Time `json:"expires_at"`                      // 过期时间，为空表示永不过期
+	}
+	var req createRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	rawToken, token, err := services.GetAccessTokenService().CreateAccessToken(
+		ctx_utils.GetUserId(c), req.Name, req.Scopes, req.ExpiresAt,
+	)
+	if err != nil {
+		ctx_utils.CustomError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx_utils.Success(c, CreateAccessTokenResponse{Token: rawToken, AccessToken: *token})
+}
+
+// GetAccessTokens
+//
+//	@Summary		获取个人访问令牌列表
+//	@Description	获取当前用户的全部访问令牌，不包含令牌明文
+//	@Tags			AccessToken
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[[]schema.AccessToken]	"令牌列表"
+//	@Router			/user/token/list [get]
+func (h *Handler) GetAccessTokens(c *gin.Context) {
+	tokens, err := services.GetAccessTokenService().ListAccessTokens(ctx_utils.GetUserId(c))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, tokens)
+}
+
+// RevokeAccessToken
+//
+//	@Summary		撤销个人访问令牌
+//	@Description	撤销当前用户的访问令牌，撤销后立即失效
+//	@Tags			AccessToken
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64						true	"令牌 ID"
+//	@Success		200	{object}	entity.CommonResponse[bool]	"撤销成功与否"
+//	@Router			/user/token/{id}/revoke [post]
+func (h *Handler) RevokeAccessToken(c *gin.Context) {
+	var uri entity.PathParamId
+	if err := c.BindUri(&uri); err != nil || uri.ID == 0 {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	if err := services.GetAccessTokenService().RevokeAccessToken(ctx_utils.GetUserId(c), uri.ID); err != nil {
+		if errors.Is(err, services.ErrAccessTokenInvalid) {
+			ctx_utils.HttpError(c, constants.ErrNotFound)
+			return
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, true)
+}
+
+// GetAccessTokenScopes
+//
+//	@Summary		获取可授予令牌的权限
+//	@Description	获取当前用户可授予访问令牌的权限路径
+//	@Tags			AccessToken
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[[]string]	"权限路径列表"
+//	@Router			/user/token/scopes [get]
+func (h *Handler) GetAccessTokenScopes(c *gin.Context) {
+	scopes, err := services.GetAccessTokenService().GetUserPermissionPaths(ctx_utils.GetUserId(c))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, scopes)
+}
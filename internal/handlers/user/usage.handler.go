@@ -0,0 +1,63 @@
+package user
+
+import (
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/fcraft/open-chat/internal/utils/gorm_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsageRecords
+//
+//	@Summary		分页获取用量流水
+//	@Description	分页获取当前用户的用量流水
+//	@Tags			Usage
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		entity.ParamPagingSort														true	"分页参数"
+//	@Success		200	{object}	entity.CommonResponse[entity.PaginatedTotalResponse[schema.UsageRecord]]	"用量流水"
+//	@Router			/user/usage/records [get]
+func (h *Handler) GetUsageRecords(c *gin.Context) {
+	var param entity.ParamPagingSort
+	if err := c.ShouldBindQuery(&param); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	param.SortParam.WithDefault("created_at DESC", "id")
+	records, total, err := gorm_utils.GetByPageTotal[schema.UsageRecord](
+		h.Db.Where("user_id = ?", ctx_utils.GetUserId(c)),
+		param.PagingParam,
+		param.SortParam,
+	)
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(
+		c, &entity.PaginatedTotalResponse[schema.UsageRecord]{
+			List:  records,
+			Total: total,
+		},
+	)
+}
+
+// GetUsageQuota
+//
+//	@Summary		获取剩余额度
+//	@Description	获取当前用户的余额，以及每日、每月额度的使用情况和重置时间
+//	@Tags			Usage
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.CommonResponse[services.QuotaStatus]	"额度信息"
+//	@Router			/user/usage/quota [get]
+func (h *Handler) GetUsageQuota(c *gin.Context) {
+	status, err := services.GetUsageService().GetQuotaStatus(ctx_utils.GetUserId(c))
+	if err != nil {
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, status)
+}
@@ -278,8 +278,11 @@ func (h *Handler) Register(c *gin.Context) {
 
 // userLogin 用户登录处理，包含 角色授权、token 签发；返回用户数据
 func (h *Handler) doUserLogin(c *gin.Context, userId uint64) (*schema.User, error) {
-	// 赠送用量
-	if _, err := h.Store.CreateUserUsage(userId, 100000); err != nil {
+	// 初始化用量并发放本月套餐额度
+	if _, err := h.Store.CreateUserUsage(userId, 0); err != nil {
+		return nil, err
+	}
+	if err := services.GetPlanService().ResetAllowance(userId); err != nil {
 		return nil, err
 	}
 
@@ -304,8 +307,8 @@ func (h *Handler) doUserLogin(c *gin.Context, userId uint64) (*schema.User, erro
 }
 
 func (h *Handler) doUserRegister(userId uint64) error {
-	// 赠送用量
-	if _, err := h.Store.CreateUserUsage(userId, 100000); err != nil {
+	// 初始化用量，套餐额度在绑定角色后发放
+	if _, err := h.Store.CreateUserUsage(userId, 0); err != nil {
 		return err
 	}
 
@@ -326,5 +329,6 @@ func (h *Handler) doUserRegister(userId uint64) error {
 		return err
 	}
 
-	return nil
+	// 发放本月套餐额度
+	return services.GetPlanService().ResetAllowance(userId)
 }
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/fcraft/open-chat/internal/constants"
+	_ "github.com/fcraft/open-chat/internal/entity"
+	_ "github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/services"
+	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RedeemVoucher
+//
+//	@Summary		兑换兑换码
+//	@Description	兑换兑换码，额度计入当前用户余额；同一兑换码每个用户只能兑换一次，短时间内多次输入无效兑换码将被限制
+//	@Tags			Voucher
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		user.RedeemVoucher.redeemRequest				true	"兑换码"
+//	@Success		200	{object}	entity.CommonResponse[schema.VoucherRedemption]	"兑换记录"
+//	@Router			/user/redeem [post]
+func (h *Handler) RedeemVoucher(c *gin.Context) {
+	type redeemRequest struct {
+		Code string `json:"code" binding:"required,max=64"`
+	}
+	var req redeemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ctx_utils.HttpError(c, constants.ErrBadRequest)
+		return
+	}
+	redemption, err := services.GetVoucherService().RedeemVoucher(ctx_utils.GetUserId(c), req.Code)
+	if err != nil {
+		var bizErr constants.BizError
+		if errors.As(err, &bizErr) {
+			ctx_utils.BizError(c, bizErr)
+			return
+		}
+		ctx_utils.HttpError(c, constants.ErrInternal)
+		return
+	}
+	ctx_utils.Success(c, redemption)
+}
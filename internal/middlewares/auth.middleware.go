@@ -1,11 +1,13 @@
 package middlewares
 
 import (
+	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/fcraft/open-chat/internal/constants"
 	"github.com/fcraft/open-chat/internal/entity"
+	"github.com/fcraft/open-chat/internal/services"
 	redisstore "github.com/fcraft/open-chat/internal/storage/redis"
 	"github.com/fcraft/open-chat/internal/utils/auth_utils"
 	"github.com/fcraft/open-chat/internal/utils/ctx_utils"
@@ -14,7 +16,7 @@ import (
 
 var ignorePaths = []string{
 	"/swagger", "/base/public-key", "/user/refresh", "/user/login", "/user/backdoor/login", "/user/logout",
-	"/user/register", "/auth",
+	"/user/register", "/auth", "/storage/object",
 }
 
 // AuthMiddleware 鉴权中间件
@@ -31,6 +33,19 @@ func AuthMiddleware(redisStore *redisstore.RedisStore) gin.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌，权限范围由 PermissionMiddleware 校验
+		if rawToken := ctx_utils.GetRawAuthToken(c); services.IsAccessToken(rawToken) {
+			accessToken, err := services.GetAccessTokenService().ValidateAccessToken(rawToken)
+			if err != nil {
+				ctx_utils.CustomError(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			c.Set("claims", &entity.UserClaims{ID: accessToken.UserID})
+			c.Set(constants.AccessTokenScopesKey, accessToken.Scopes.Data())
+			c.Next()
+			return
+		}
+
 		// 1. 解析 auth_token
 		token := auth_utils.ValidateAuthToken(c)
 		if token == nil || !token.Valid {
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"slices"
+
 	"github.com/fcraft/open-chat/internal/constants"
 	"github.com/fcraft/open-chat/internal/entity"
 	handlers "github.com/fcraft/open-chat/internal/storage/helper"
@@ -65,6 +67,12 @@ func PermissionMiddleware(helper *handlers.QueryHelper) gin.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌只能访问其权限范围内的接口
+		if scopes, exists := c.Get(constants.AccessTokenScopesKey); exists && !slices.Contains(scopes.([]string), currentPath) {
+			ctx_utils.BizError(c, constants.BizErrNoPermission)
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -212,6 +212,96 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 		)
 	}
 
+	// routes for file upload and storage
+	fileHandler := base.NewFileHandler(baseHandler)
+	fileGroup := r.Group("/file")
+	{
+		router.registerRoute(
+			fileGroup,
+			POST,
+			"/upload",
+			"直接上传文件",
+
+			fileHandler.UploadFile,
+		)
+		router.registerRoute(
+			fileGroup,
+			GET,
+			"/upload/policy",
+			"获取各模块的上传限制",
+
+			fileHandler.GetUploadPolicy,
+		)
+		router.registerRoute(
+			fileGroup,
+			POST,
+			"/upload/presign",
+			"获取预签名上传链接",
+
+			fileHandler.PresignUpload,
+		)
+		router.registerRoute(
+			fileGroup,
+			GET,
+			"/list",
+			"获取当前用户的文件列表",
+
+			fileHandler.GetFiles,
+		)
+		router.registerRoute(
+			fileGroup,
+			GET,
+			"/:id",
+			"获取文件及临时下载链接",
+
+			fileHandler.GetFile,
+		)
+		router.registerRoute(
+			fileGroup,
+			POST,
+			"/:id/complete",
+			"确认预签名上传",
+
+			fileHandler.CompleteUpload,
+		)
+		router.registerRoute(
+			fileGroup,
+			GET,
+			"/:id/download",
+			"下载文件",
+
+			fileHandler.DownloadFile,
+		)
+		router.registerRoute(
+			fileGroup,
+			POST,
+			"/:id/delete",
+			"删除文件",
+
+			fileHandler.DeleteFile,
+		)
+	}
+	// 本地储存的签名链接，通过签名鉴权
+	storageGroup := r.Group("/storage")
+	{
+		router.registerRoute(
+			storageGroup,
+			GET,
+			"/object/:bucket_id/*key",
+			"读取本地储存对象",
+
+			fileHandler.GetLocalObject,
+		)
+		router.registerRoute(
+			storageGroup,
+			PUT,
+			"/object/:bucket_id/*key",
+			"写入本地储存对象",
+
+			fileHandler.PutLocalObject,
+		)
+	}
+
 	// routes for chat completion
 	chatHandler := chat.NewChatHandler(baseHandler)
 	chatGroup := r.Group("/chat")
@@ -234,6 +324,14 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				chatHandler.GetBotConfig,
 			)
+			router.registerRoute(
+				chatConfigGroup,
+				GET,
+				"/tools",
+				"获取可用的对话工具列表",
+
+				chatHandler.GetToolConfig,
+			)
 		}
 		// routes for preset
 		botRoleGroup := r.Group("/preset")
@@ -332,6 +430,14 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				chatHandler.UpdateSessionFlag,
 			)
+			router.registerRoute(
+				chatSessionGroup,
+				POST,
+				"/branch/:session_id",
+				"切换聊天会话的当前分支",
+
+				chatHandler.SwitchSessionBranch,
+			)
 			router.registerRoute(
 				chatSessionGroup,
 				POST,
@@ -383,6 +489,97 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				chatHandler.UpdateMessage,
 			)
+			router.registerRoute(
+				chatMessageGroup,
+				POST,
+				"/:id/edit",
+				"编辑用户消息并重新发送（新建消息分支）",
+
+				chatHandler.EditMessageStream,
+			)
+		}
+		chatKnowledgeGroup := chatGroup.Group("/knowledge")
+		{
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/create",
+				"创建知识库",
+
+				chatHandler.CreateKnowledgeBase,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				GET,
+				"/list",
+				"获取当前用户可访问的知识库列表",
+
+				chatHandler.GetKnowledgeBases,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				GET,
+				"/:id",
+				"获取指定知识库的详细信息",
+
+				chatHandler.GetKnowledgeBase,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/:id/update",
+				"更新知识库",
+
+				chatHandler.UpdateKnowledgeBase,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/:id/delete",
+				"删除知识库及其文档",
+
+				chatHandler.DeleteKnowledgeBase,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/:id/document/upload",
+				"上传知识库文档",
+
+				chatHandler.UploadKnowledgeDocument,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				GET,
+				"/:id/document/list",
+				"获取知识库文档列表",
+
+				chatHandler.GetKnowledgeDocuments,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/:id/search",
+				"检索知识库",
+
+				chatHandler.SearchKnowledgeBase,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/document/:id/reprocess",
+				"重新处理知识库文档",
+
+				chatHandler.ReprocessKnowledgeDocument,
+			)
+			router.registerRoute(
+				chatKnowledgeGroup,
+				POST,
+				"/document/:id/delete",
+				"删除知识库文档",
+
+				chatHandler.DeleteKnowledgeDocument,
+			)
 		}
 		chatCompletionGroup := chatGroup.Group("/completion")
 		{
@@ -394,9 +591,54 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				chatHandler.CompletionStream,
 			)
+			router.registerRoute(
+				chatCompletionGroup,
+				POST,
+				"/regenerate/:id",
+				"重新生成AI回复（新建回复分支）",
+
+				chatHandler.RegenerateStream,
+			)
+			router.registerRoute(
+				chatCompletionGroup,
+				GET,
+				"/resume/:id",
+				"断线后续传AI回复的流式输出",
+
+				chatHandler.ResumeStream,
+			)
+			router.registerRoute(
+				chatCompletionGroup,
+				POST,
+				"/stop/:message_id",
+				"停止生成AI回复并保存已输出内容",
+
+				chatHandler.StopCompletion,
+			)
 		}
 	}
 
+	// routes for OpenAI-compatible gateway
+	openAIGroup := r.Group("/v1")
+	{
+		router.registerRoute(
+			openAIGroup,
+			POST,
+			"/chat/completions",
+			"OpenAI 兼容的补全接口",
+
+			chatHandler.OpenAIChatCompletions,
+		)
+		router.registerRoute(
+			openAIGroup,
+			GET,
+			"/models",
+			"OpenAI 兼容的模型列表",
+
+			chatHandler.OpenAIListModels,
+		)
+	}
+
 	// routes for user
 	userHandler := user.NewUserHandler(baseHandler)
 	userGroup := r.Group("/user")
@@ -410,6 +652,16 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 		if os.Getenv("GO_ENV") == "dev" {
 			router.registerRoute(userGroup, POST, "/backdoor/login", "后台登录接口", userHandler.BackdoorLogin)
 		}
+		userTokenGroup := userGroup.Group("/token")
+		{
+			router.registerRoute(userTokenGroup, POST, "/create", "创建个人访问令牌", userHandler.CreateAccessToken)
+			router.registerRoute(userTokenGroup, GET, "/list", "获取个人访问令牌列表", userHandler.GetAccessTokens)
+			router.registerRoute(userTokenGroup, POST, "/:id/revoke", "撤销个人访问令牌", userHandler.RevokeAccessToken)
+			router.registerRoute(userTokenGroup, GET, "/scopes", "获取可授予令牌的权限", userHandler.GetAccessTokenScopes)
+		}
+		router.registerRoute(userGroup, GET, "/usage/records", "获取当前用户的用量流水", userHandler.GetUsageRecords)
+		router.registerRoute(userGroup, GET, "/usage/quota", "获取当前用户的剩余额度", userHandler.GetUsageQuota)
+		router.registerRoute(userGroup, POST, "/redeem", "兑换兑换码", userHandler.RedeemVoucher)
 	}
 	authGroup := r.Group("/auth")
 	{
@@ -490,6 +742,14 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				manageHandler.DeleteAPIKey,
 			)
+			router.registerRoute(
+				manageApiKeyGroup,
+				POST,
+				"/:id/enable",
+				"重新启用被停用的API访问密钥",
+
+				manageHandler.EnableAPIKey,
+			)
 			router.registerRoute(
 				manageApiKeyGroup,
 				GET,
@@ -517,6 +777,14 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				manageHandler.GetModel,
 			)
+			router.registerRoute(
+				manageModelGroup,
+				GET,
+				"/:model_id/health",
+				"获取模型的连通性检测结果",
+
+				manageHandler.GetModelHealth,
+			)
 			router.registerRoute(
 				manageModelGroup,
 				GET,
@@ -584,6 +852,14 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 
 				manageHandler.GetModelCollection,
 			)
+			router.registerRoute(
+				manageCollectionGroup,
+				GET,
+				"/:id/stats",
+				"获取模型集合的实时统计",
+
+				manageHandler.GetModelCollectionStats,
+			)
 			router.registerRoute(
 				manageCollectionGroup,
 				GET,
@@ -800,6 +1076,178 @@ func InitRouter(r *gin.Engine, store *gormStore.GormStore, redis *redis.RedisSto
 				manageHandler.DeleteBucket,
 			)
 		}
+		manageUsageGroup := manageGroup.Group("/usage")
+		{
+			router.registerRoute(
+				manageUsageGroup,
+				GET,
+				"/records",
+				"分页获取用量流水",
+
+				manageHandler.GetUsageRecords,
+			)
+		}
+		manageVoucherGroup := manageGroup.Group("/voucher")
+		{
+			router.registerRoute(
+				manageVoucherGroup,
+				POST,
+				"/batch/create",
+				"生成兑换码批次",
+
+				manageHandler.CreateVoucherBatch,
+			)
+			router.registerRoute(
+				manageVoucherGroup,
+				GET,
+				"/batch/list",
+				"分页获取兑换码批次",
+
+				manageHandler.GetVoucherBatches,
+			)
+			router.registerRoute(
+				manageVoucherGroup,
+				GET,
+				"/batch/:id/codes",
+				"分页获取批次内的兑换码",
+
+				manageHandler.GetVoucherBatchCodes,
+			)
+			router.registerRoute(
+				manageVoucherGroup,
+				POST,
+				"/batch/:id/disable",
+				"停用兑换码批次",
+
+				manageHandler.DisableVoucherBatch,
+			)
+			router.registerRoute(
+				manageVoucherGroup,
+				POST,
+				"/:id/disable",
+				"停用兑换码",
+
+				manageHandler.DisableVoucher,
+			)
+			router.registerRoute(
+				manageVoucherGroup,
+				GET,
+				"/redemptions",
+				"分页获取兑换记录",
+
+				manageHandler.GetVoucherRedemptions,
+			)
+		}
+		managePlanGroup := manageGroup.Group("/plan")
+		{
+			router.registerRoute(
+				managePlanGroup,
+				GET,
+				"/list",
+				"分页获取套餐",
+
+				manageHandler.GetPlans,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/create",
+				"创建套餐",
+
+				manageHandler.CreatePlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/:id/update",
+				"更新套餐",
+
+				manageHandler.UpdatePlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/:id/delete",
+				"删除套餐",
+
+				manageHandler.DeletePlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/assign/user",
+				"为用户分配套餐",
+
+				manageHandler.AssignUserPlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/unassign/user",
+				"取消用户套餐",
+
+				manageHandler.UnassignUserPlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/assign/role",
+				"为角色分配套餐",
+
+				manageHandler.AssignRolePlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				POST,
+				"/unassign/role",
+				"取消角色套餐",
+
+				manageHandler.UnassignRolePlan,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				GET,
+				"/role/list",
+				"获取角色套餐",
+
+				manageHandler.GetRolePlans,
+			)
+			router.registerRoute(
+				managePlanGroup,
+				GET,
+				"/user/:id",
+				"获取用户套餐",
+
+				manageHandler.GetUserPlan,
+			)
+		}
+		manageKnowledgeGroup := manageGroup.Group("/knowledge")
+		{
+			router.registerRoute(
+				manageKnowledgeGroup,
+				GET,
+				"/list",
+				"分页获取全部知识库",
+
+				manageHandler.GetKnowledgeBases,
+			)
+			router.registerRoute(
+				manageKnowledgeGroup,
+				POST,
+				"/:id/update",
+				"更新知识库及公开状态",
+
+				manageHandler.UpdateKnowledgeBase,
+			)
+			router.registerRoute(
+				manageKnowledgeGroup,
+				POST,
+				"/:id/delete",
+				"删除知识库及其文档",
+
+				manageHandler.DeleteKnowledgeBase,
+			)
+		}
 	}
 
 	// routes for tue
@@ -0,0 +1,25 @@
+package schema
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// AccessToken 用户创建的个人访问令牌，用于脚本及第三方集成调用接口
+type AccessToken struct {
+	ID         uint64                       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64                       `gorm:"index;not null" json:"user_id"`
+	Name       string                       `gorm:"not null" json:"name"`          // 令牌名称
+	Prefix     string                       `gorm:"not null" json:"prefix"`        // 令牌前若干位，用于辨认令牌
+	TokenHash  string                       `gorm:"uniqueIndex;not null" json:"-"` // 令牌的 SHA-256 摘要，明文仅在创建时返回
+	Scopes     datatypes.JSONType[[]string] `gorm:"type:json" json:"scopes"`       // 可访问的权限路径（形如：POST:/user/create）
+	ExpiresAt  *time.Time                   `json:"expires_at"`                    // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time                   `json:"last_used_at"`                  // 最近使用时间
+	AutoCreateUpdateDeleteAt
+}
+
+// IsExpired 令牌是否已过期
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now())
+}
@@ -3,24 +3,39 @@ package schema
 type Bucket struct {
 	ID              uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
 	DisplayName     string `gorm:"not null" json:"display_name"`
-	EndpointURL     string `gorm:"not null" json:"endpoint_url"`
+	Type            string `gorm:"default:'s3'" json:"type"`     // 储存类型：s3/local
+	EndpointURL     string `gorm:"not null" json:"endpoint_url"` // S3 服务地址，本地储存时为根目录
 	Region          string `gorm:"not null" json:"region"`
 	AccessKeyID     string `gorm:"not null" json:"access_key_id"`
-	SecretAccessKey string `gorm:"not null" json:"secret_access_key"`
-	BucketName      string `gorm:"not null" json:"bucket_name"`
+	SecretAccessKey string `gorm:"not null" json:"secret_access_key"` // 本地储存时用于签名临时链接
+	BucketName      string `gorm:"not null" json:"bucket_name"`       // 本地储存时为根目录下的子目录
 	AutoCreateUpdateDeleteAt
 }
 
+// 储存桶类型
+const (
+	BucketTypeS3    = "s3"    // S3 兼容的对象储存
+	BucketTypeLocal = "local" // 本地文件系统
+)
+
 type File struct {
 	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
 	BucketID uint64 `gorm:"not null" json:"bucket_id"`
-	Name     string `gorm:"not null" json:"name"`               // 文件名
-	Size     int64  `gorm:"not null" json:"size"`               // 文件大小（字节）
-	Type     string `gorm:"not null" json:"type"`               // 文件类型（如 image/jpeg）
-	Module   string `gorm:"not null" json:"module"`             // 文件所属模块
-	S3Path   string `gorm:"not null;unique" json:"s3_path"`     // S3 存储路径（如 "uploads/abc123.jpg"）
-	OwnerID  uint64 `gorm:"not null;default:0" json:"owner_id"` // 文件所有者ID（可选）
+	Name     string `gorm:"not null" json:"name"`                         // 文件名
+	Size     int64  `gorm:"not null" json:"size"`                         // 文件大小（字节）
+	Type     string `gorm:"not null" json:"type"`                         // 文件类型（如 image/jpeg）
+	Module   string `gorm:"not null" json:"module"`                       // 文件所属模块
+	S3Path   string `gorm:"not null;unique" json:"s3_path"`               // S3 存储路径（如 "uploads/abc123.jpg"）
+	OwnerID  uint64 `gorm:"not null;default:0" json:"owner_id"`           // 文件所有者ID（可选）
+	Status   string `gorm:"not null;default:'ready';index" json:"status"` // 上传状态，见 FileStatus* 常量
 	AutoCreateDeleteAt
 
 	Bucket *Bucket `gorm:"foreignKey:ID;references:BucketID" json:"bucket"`
+	URL    string  `gorm:"-" json:"url,omitempty"` // 临时访问链接，按需组装
 }
+
+// 文件的上传状态
+const (
+	FileStatusPending = "pending" // 已签发预签名上传链接，等待客户端上传并确认
+	FileStatusReady   = "ready"   // 上传完成，可以使用
+)
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KnowledgeBase 知识库，可挂载到会话或预设，对话时检索相关片段作为上下文
+type KnowledgeBase struct {
+	ID                  uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name                string  `gorm:"not null" json:"name"`
+	Description         string  `json:"description"`
+	OwnerID             uint64  `gorm:"index;not null;default:0" json:"owner_id"` // 创建者用户 ID
+	IsPublic            bool    `gorm:"default:false" json:"is_public"`           // 是否对所有用户可见，仅管理员可设置
+	EmbeddingCollection string  `gorm:"not null" json:"embedding_collection"`     // 向量化使用的模型集合，创建后不可修改
+	ChunkSize           int     `gorm:"not null;default:500" json:"chunk_size"`   // 分段大小（token）
+	ChunkOverlap        int     `gorm:"not null;default:50" json:"chunk_overlap"` // 相邻分段的重叠大小（token）
+	TopK                int     `gorm:"not null;default:5" json:"top_k"`          // 每次检索的分段数量
+	MinScore            float64 `gorm:"not null;default:0" json:"min_score"`      // 余弦相似度阈值，低于该值的分段不使用
+	DocumentCount       int     `gorm:"not null;default:0" json:"document_count"` // 文档数量
+	ChunkCount          int     `gorm:"not null;default:0" json:"chunk_count"`    // 分段数量
+	AutoCreateUpdateDeleteAt
+}
+
+// 知识库文档的处理状态
+const (
+	KnowledgeDocumentStatusPending    = "pending"    // 等待处理
+	KnowledgeDocumentStatusProcessing = "processing" // 分段及向量化中
+	KnowledgeDocumentStatusReady      = "ready"      // 可检索
+	KnowledgeDocumentStatusFailed     = "failed"     // 处理失败
+)
+
+// KnowledgeDocument 知识库中的文档
+type KnowledgeDocument struct {
+	ID              uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	KnowledgeBaseID uint64 `gorm:"index;not null" json:"knowledge_base_id"`
+	Name            string `gorm:"not null" json:"name"`                     // 文件名
+	MimeType        string `json:"mime_type"`                                // 上传时的文件类型
+	Size            int64  `json:"size"`                                     // 文件大小（字节）
+	Content         string `gorm:"type:text" json:"-"`                       // 提取的正文，用于重新处理
+	Status          string `gorm:"not null;default:'pending'" json:"status"` // 处理状态，见 KnowledgeDocumentStatus* 常量
+	Error           string `json:"error,omitempty"`                          // 处理失败的原因
+	ChunkCount      int    `gorm:"not null;default:0" json:"chunk_count"`    // 分段数量
+	Tokens          int64  `gorm:"not null;default:0" json:"tokens"`         // 正文的估算 token 数
+	AutoCreateUpdateAt
+}
+
+// KnowledgeChunk 文档分段及其向量
+type KnowledgeChunk struct {
+	ID              uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	KnowledgeBaseID uint64  `gorm:"index;not null" json:"knowledge_base_id"`
+	DocumentID      uint64  `gorm:"index;not null" json:"document_id"`
+	Index           int     `gorm:"not null" json:"index"` // 在文档中的序号
+	Content         string  `gorm:"type:text;not null" json:"content"`
+	Tokens          int64   `json:"tokens"`
+	Embedding       Vector  `gorm:"type:real[]" json:"-"` // 向量，启用 pgvector 时检索前转换为 vector 类型
+	Dimensions      int     `gorm:"index" json:"dimensions"`
+	Norm            float64 `json:"-"` // 向量的模，未启用 pgvector 时用于计算余弦相似度
+	AutoCreateAt
+}
+
+// Vector 浮点向量，以 Postgres real[] 格式存储
+type Vector []float32
+
+// Value 转换为 Postgres 数组字面量
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.literal("{", "}"), nil
+}
+
+// Scan 解析 Postgres 数组或 pgvector 字面量
+func (v *Vector) Scan(src any) error {
+	var s string
+	switch value := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		s = value
+	case []byte:
+		s = string(value)
+	default:
+		return fmt.Errorf("unsupported vector type: %T", src)
+	}
+	s = strings.Trim(strings.TrimSpace(s), "{}[]")
+	if s == "" {
+		*v = Vector{}
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	vec := make(Vector, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return err
+		}
+		vec[i] = float32(f)
+	}
+	*v = vec
+	return nil
+}
+
+// String 转换为 pgvector 字面量，如 [1,2,3]
+func (v Vector) String() string {
+	return v.literal("[", "]")
+}
+
+func (v Vector) literal(open string, close string) string {
+	var sb strings.Builder
+	sb.WriteString(open)
+	for i, f := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	sb.WriteString(close)
+	return sb.String()
+}
@@ -6,18 +6,21 @@ type Message struct {
 	// 默认结构
 	ID               uint64                             `gorm:"primaryKey;autoIncrement" json:"id"`
 	SessionID        string                             `gorm:"index" json:"session_id"`
-	Role             string                             `json:"role"`      // user/assistant/system
-	ModelID          uint64                             `json:"model_id"`  // 回复所使用的模型
-	PresetID         uint64                             `json:"preset_id"` // 回复所使用的预设
+	ParentID         uint64                             `gorm:"index;default:0" json:"parent_id"` // 父消息 ID，构成消息树，0 表示根消息
+	Role             string                             `json:"role"`                             // user/assistant/system
+	ModelID          uint64                             `json:"model_id"`                         // 回复所使用的模型
+	PresetID         uint64                             `json:"preset_id"`                        // 回复所使用的预设
 	Content          string                             `json:"content"`
 	ReasoningContent string                             `json:"reasoning_content"`
 	Extra            datatypes.JSONType[map[string]any] `json:"extra"`
 	TokenUsage       int64                              `gorm:"default:0" json:"token_usage"`
+	FileIDs          datatypes.JSONSlice[uint64]        `json:"file_ids"` // 附件文件 ID 列表
 	AutoCreateDeleteAt
 
 	// 组装结构
 	Model  *Model  `gorm:"foreignKey:ID;references:ModelID" json:"model"`
 	Preset *Preset `gorm:"foreignKey:ID;references:PresetID" json:"preset"`
+	Files  []File  `gorm:"-" json:"files,omitempty"` // 附件文件，按需组装
 }
 
 func (m *Message) TableName() string {
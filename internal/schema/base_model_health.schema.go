@@ -0,0 +1,11 @@
+package schema
+
+// ModelHealthCheck 模型连通性检测记录
+type ModelHealthCheck struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	ModelID   uint64 `gorm:"index;not null" json:"model_id"` // 检测的模型
+	Success   bool   `json:"success"`                        // 是否成功
+	LatencyMs int64  `json:"latency_ms"`                     // 请求耗时
+	Error     string `gorm:"type:text" json:"error"`         // 失败时的错误信息
+	AutoCreateAt
+}
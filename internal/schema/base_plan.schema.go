@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Plan 订阅套餐，决定每月额度及可用的模型集合
+type Plan struct {
+	ID               uint64                       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name             string                       `gorm:"uniqueIndex;not null" json:"name"`         // 套餐标识，如 free/pro/team
+	DisplayName      string                       `json:"display_name"`                             // 展示名称
+	Description      string                       `json:"description"`                              // 套餐描述
+	MonthlyTokens    int64                        `gorm:"not null;default:0" json:"monthly_tokens"` // 每月额度，每月初重置
+	ModelCollections datatypes.JSONType[[]string] `gorm:"type:json" json:"model_collections"`       // 可用的模型集合名称，为空表示不限制
+	Priority         int                          `gorm:"default:0" json:"priority"`                // 用户经多个角色获得多个套餐时取优先级最高者
+	IsDefault        bool                         `gorm:"default:false" json:"is_default"`          // 未分配套餐的用户使用的默认套餐
+	AutoCreateUpdateDeleteAt
+}
+
+// AllowsCollection 套餐是否可使用指定的模型集合
+func (p *Plan) AllowsCollection(name string) bool {
+	collections := p.ModelCollections.Data()
+	if len(collections) == 0 {
+		return true
+	}
+	for _, collection := range collections {
+		if collection == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UserPlan 直接分配给用户的套餐，优先于角色套餐
+type UserPlan struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64     `gorm:"uniqueIndex;not null" json:"user_id"`
+	PlanID     uint64     `gorm:"index;not null" json:"plan_id"`
+	ExpiresAt  *time.Time `json:"expires_at"`  // 过期时间，过期后回退到角色套餐或默认套餐
+	AssignedBy uint64     `json:"assigned_by"` // 分配者用户 ID
+	AutoCreateUpdateAt
+	Plan *Plan `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE" json:"plan,omitempty"`
+}
+
+// RolePlan 分配给角色的套餐，拥有该角色的用户均可使用
+type RolePlan struct {
+	ID     uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID uint64 `gorm:"uniqueIndex;not null" json:"role_id"`
+	PlanID uint64 `gorm:"index;not null" json:"plan_id"`
+	AutoCreateUpdateAt
+	Plan *Plan `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE" json:"plan,omitempty"`
+}
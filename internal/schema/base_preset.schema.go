@@ -7,12 +7,14 @@ import (
 
 type Preset struct {
 	// 原始数据
-	ID              uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name            string `gorm:"unique;index" json:"name"` // 角色名称
-	Description     string `json:"description"`              // 角色描述
-	PromptSessionId string `json:"prompt_session_id"`        // 引用一个 session 中的对话作为 prompt
-	Module          string `gorm:"index" json:"type"`        // 角色所属模块（chat、tue 等）
-	Version         int64  `gorm:"default:0" json:"version"` // 预设版本号，可能被用于标记是否需要强制更新
+	ID              uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string    `gorm:"unique;index" json:"name"` // 角色名称
+	Description     string    `json:"description"`              // 角色描述
+	PromptSessionId string    `json:"prompt_session_id"`        // 引用一个 session 中的对话作为 prompt
+	Module          string    `gorm:"index" json:"type"`        // 角色所属模块（chat、tue 等）
+	Version         int64     `gorm:"default:0" json:"version"` // 预设版本号，可能被用于标记是否需要强制更新
+	Tools           ToolNames `json:"tools"`                    // 预设开放的工具名称，为 null 时沿用请求或会话的工具配置
+	KnowledgeBaseID *uint64   `json:"knowledge_base_id"`        // 挂载的知识库 ID，非空时优先于会话的知识库
 	AutoCreateUpdateDeleteAt
 
 	// 组装数据
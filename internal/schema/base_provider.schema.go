@@ -1,10 +1,13 @@
 package schema
 
+import "time"
+
 type Provider struct {
 	ID          uint64   `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name        string   `gorm:"not null;unique" json:"name"`           // 提供商名称
 	DisplayName string   `gorm:"" json:"display_name"`                  // 对外展示提供商名称
 	BaseURL     string   `gorm:"not null" json:"base_url"`              // API 的基本 URL
+	Type        string   `gorm:"default:'openai'" json:"type"`          // 协议类型：openai/anthropic/gemini/ollama
 	Description string   `gorm:"" json:"description"`                   // 额外提供商描述
 	Icon        string   `json:"icon"`                                  // 供应商图标
 	APIKeys     []APIKey `gorm:"foreignKey:ProviderID" json:"api_keys"` // 一对多关系，与 APIKey 模型关联
@@ -13,12 +16,32 @@ type Provider struct {
 }
 
 type APIKey struct {
-	ID         uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
-	ProviderID uint64 `gorm:"index;not null" json:"provider_id"` // 外键，指向 Provider
-	Key        string `gorm:"not null" json:"key"`               // API 密钥
+	ID             uint64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProviderID     uint64        `gorm:"index;not null" json:"provider_id"` // 外键，指向 Provider
+	Key            string        `gorm:"not null" json:"key"`               // API 密钥
+	Disabled       bool          `gorm:"default:false" json:"disabled"`     // 是否已停用，鉴权失败时自动停用
+	DisabledReason string        `json:"disabled_reason"`                   // 停用原因
+	Health         *APIKeyHealth `gorm:"-" json:"health,omitempty"`         // 健康状态，按需组装
 	AutoCreateAt
 }
 
+// API Key 的健康状态
+const (
+	APIKeyStatusActive   = "active"   // 可用
+	APIKeyStatusCooling  = "cooling"  // 连续失败，冷却中
+	APIKeyStatusDisabled = "disabled" // 已停用
+)
+
+// APIKeyHealth API Key 的健康状态，统计数据保存在 Redis 中
+type APIKeyHealth struct {
+	Status        string           `json:"status"`                   // 状态，见 APIKeyStatus* 常量
+	Failures      int64            `json:"failures"`                 // 连续失败次数
+	CooldownUntil *time.Time       `json:"cooldown_until,omitempty"` // 冷却结束时间
+	LastError     string           `json:"last_error,omitempty"`     // 最后一次失败的错误信息
+	LastFailedAt  *time.Time       `json:"last_failed_at,omitempty"` // 最后一次失败的时间
+	Counts        map[string]int64 `json:"counts"`                   // 各类失败的累计次数，如 401/403/429/timeout
+}
+
 type Model struct {
 	// 原始数据
 	ID          uint64      `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -29,6 +52,8 @@ type Model struct {
 	Icon        string      `json:"icon"`                                    // 模型图标
 	Config      ModelConfig `gorm:"type:json;serializer:json" json:"config"` // 使用 JSON 储存配置
 	Active      bool        `gorm:"default:true" json:"active"`              // 是否启用
+	Unhealthy   bool        `gorm:"default:false" json:"unhealthy"`          // 连通性检测连续失败，暂不参与集合路由
+	Embedding   bool        `gorm:"default:false" json:"embedding"`          // 是否为向量化模型，仅参与向量化路由，不可用于对话
 
 	// 组装数据
 	Provider *Provider `gorm:"foreignKey:ProviderID" json:"provider"`
@@ -48,9 +73,17 @@ type ModelConfig struct {
 	AllowSystemPrompt  bool    `json:"allow_system_prompt"` // 是否允许用户自行修改系统提示
 	SystemPrompt       string  `json:"system_prompt"`       // 预设系统提示
 	MaxTokens          int64   `json:"max_tokens"`
+	ContextLength      int64   `json:"context_length"` // 上下文长度（token）
 	TopP               float32 `json:"top_p"`
 	FrequencyPenalty   float32 `json:"frequency_penalty"`
 	PresencePenalty    float32 `json:"presence_penalty"`
+	Vision             bool    `json:"vision"`       // 是否支持图片输入
+	FileInput          bool    `json:"file_input"`   // 是否支持文件（如 PDF）输入
+	InputPrice         float64 `json:"input_price"`  // 输入价格（每百万 token）
+	OutputPrice        float64 `json:"output_price"` // 输出价格（每百万 token）
+
+	EmbeddingDimensions int64 `json:"embedding_dimensions,omitempty"` // 向量化输出维度，0 表示使用模型默认维度
+	EmbeddingBatchSize  int   `json:"embedding_batch_size,omitempty"` // 向量化单次请求的最大文本数，0 表示使用提供商的上限
 }
 
 var DefaultModelConfig = ModelConfig{
@@ -58,6 +91,7 @@ var DefaultModelConfig = ModelConfig{
 	AllowSystemPrompt:  true,
 	SystemPrompt:       "",
 	MaxTokens:          4096,
+	ContextLength:      16384,
 	TopP:               1.0,
 	FrequencyPenalty:   0.0,
 	PresencePenalty:    0.0,
@@ -65,11 +99,34 @@ var DefaultModelConfig = ModelConfig{
 
 // ModelCollection 是多个相似模型的集合，用于负载均衡/统一名称，这在对接多个供应商服务的时候很有用
 type ModelCollection struct {
-	ID          uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string  `gorm:"not null;unique" json:"name"`                       // 唯一标识名称
-	DisplayName string  `json:"display_name"`                                      // 展示名称
-	Description string  `json:"description"`                                       // 额外描述
-	Icon        string  `json:"icon"`                                              // 图标
-	Models      []Model `gorm:"many2many:model_collections_models;" json:"models"` // 关联的模型
+	ID            uint64                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name          string                 `gorm:"not null;unique" json:"name"`                        // 唯一标识名称
+	DisplayName   string                 `json:"display_name"`                                       // 展示名称
+	Description   string                 `json:"description"`                                        // 额外描述
+	Icon          string                 `json:"icon"`                                               // 图标
+	Strategy      string                 `gorm:"default:'weighted_random'" json:"strategy"`          // 负载均衡策略，见 BalanceStrategy* 常量
+	Models        []Model                `gorm:"many2many:model_collections_models;" json:"models"`  // 关联的模型
+	ModelSettings []ModelCollectionModel `gorm:"foreignKey:ModelCollectionID" json:"model_settings"` // 集合内各模型的负载均衡参数
 	AutoCreateAt
 }
+
+// 模型集合的负载均衡策略
+const (
+	BalanceStrategyWeightedRandom = "weighted_random" // 按权重随机
+	BalanceStrategyRoundRobin     = "round_robin"     // 轮询
+	BalanceStrategyLeastLatency   = "least_latency"   // 最低延迟（综合错误率）
+	BalanceStrategyLowestCost     = "lowest_cost"     // 最低价格
+	BalanceStrategyPriority       = "priority"        // 按优先级，失败时依次降级
+)
+
+// ModelCollectionModel 模型集合与模型的关联表，记录模型在集合内的负载均衡参数
+type ModelCollectionModel struct {
+	ModelCollectionID uint64 `gorm:"primaryKey;autoIncrement:false" json:"model_collection_id"`
+	ModelID           uint64 `gorm:"primaryKey;autoIncrement:false" json:"model_id"`
+	Weight            int    `gorm:"not null;default:1" json:"weight"`   // 权重，用于加权随机，为 0 时仅作为备用模型
+	Priority          int    `gorm:"not null;default:0" json:"priority"` // 优先级，数值越小越优先
+}
+
+func (m *ModelCollectionModel) TableName() string {
+	return "model_collections_models"
+}
@@ -2,25 +2,34 @@ package schema
 
 import (
 	"encoding/json"
+	"gorm.io/datatypes"
 	"time"
 )
 
 // Session 会话，一系列消息的集合
 type Session struct {
 	// 原始数据
-	ID            string          `gorm:"primaryKey;default:gen_random_uuid()" json:"id"`
-	Name          string          `json:"name"`
-	NameType      SessionNameType `gorm:"default:0" json:"name_type"` // 标题来源
-	EnableContext bool            `json:"enable_context"`             // 上下文开关
-	ContextSize   int             `json:"context_size"`               // 上下文大小
-	SystemPrompt  string          `json:"system_prompt"`              // 系统提示词
-	LastActive    time.Time       `json:"last_active"`
+	ID               string          `gorm:"primaryKey;default:gen_random_uuid()" json:"id"`
+	Name             string          `json:"name"`
+	NameType         SessionNameType `gorm:"default:0" json:"name_type"`          // 标题来源
+	EnableContext    bool            `json:"enable_context"`                      // 上下文开关
+	ContextSize      int             `json:"context_size"`                        // 上下文大小
+	SystemPrompt     string          `json:"system_prompt"`                       // 系统提示词
+	CurrentMessageID uint64          `gorm:"default:0" json:"current_message_id"` // 当前选中分支的末端消息 ID
+	Tools            ToolNames       `json:"tools"`                               // 会话可用的工具名称，为 null 时按提问内容自动选择
+	Summary          string          `gorm:"type:text" json:"summary"`            // 早期对话的滚动摘要
+	SummaryMessageID uint64          `gorm:"default:0" json:"summary_message_id"` // 摘要覆盖到的最后一条消息 ID
+	KnowledgeBaseID  *uint64         `json:"knowledge_base_id"`                   // 挂载的知识库 ID，为空时不检索
+	LastActive       time.Time       `json:"last_active"`
 	AutoCreateUpdateDeleteAt
 
 	// 组装数据
 	Messages []Message `gorm:"foreignKey:SessionID;references:ID" json:"messages"`
 }
 
+// ToolNames 工具名称列表，null 与空列表含义不同：null 表示未指定，空列表表示不使用工具
+type ToolNames = datatypes.JSONSlice[string]
+
 type SessionNameType int
 
 const (
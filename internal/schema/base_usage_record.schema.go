@@ -0,0 +1,34 @@
+package schema
+
+// UsageRecordSource 用量来源
+type UsageRecordSource string
+
+const (
+	UsageRecordSourceChat      UsageRecordSource = "chat"      // 对话
+	UsageRecordSourceOpenAI    UsageRecordSource = "openai"    // OpenAI 兼容接口
+	UsageRecordSourcePreset    UsageRecordSource = "preset"    // 内置预设调用
+	UsageRecordSourceEmbedding UsageRecordSource = "embedding" // 文本向量化
+)
+
+// UsageRecord 用量流水，每次补全记录一条，用户余额的扣减以此为准
+type UsageRecord struct {
+	ID               uint64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           uint64            `gorm:"index" json:"user_id"` // 用户 ID，内置预设调用为 0
+	Source           UsageRecordSource `gorm:"index;not null" json:"source"`
+	SessionID        string            `gorm:"index" json:"session_id"`
+	MessageID        uint64            `json:"message_id"`
+	PresetID         uint64            `json:"preset_id"`
+	PresetRecordID   uint64            `json:"preset_record_id"` // 内置预设的调用记录 ID
+	ModelID          uint64            `gorm:"index" json:"model_id"`
+	Model            string            `json:"model"` // 模型名称，模型被删除后仍可追溯
+	ProviderID       uint64            `json:"provider_id"`
+	APIKeyID         uint64            `json:"api_key_id"`
+	PromptTokens     int64             `json:"prompt_tokens"`
+	CompletionTokens int64             `json:"completion_tokens"` // 包含思考内容的 token 数
+	ReasoningTokens  int64             `json:"reasoning_tokens"`
+	InputPrice       float64           `json:"input_price"`  // 记录时的输入价格（每百万 token）
+	OutputPrice      float64           `json:"output_price"` // 记录时的输出价格（每百万 token）
+	Cost             float64           `json:"cost"`         // 按模型价格计算的费用
+	Charge           int64             `json:"charge"`       // 从用户余额中扣除的额度
+	AutoCreateAt
+}
@@ -0,0 +1,40 @@
+package schema
+
+import "time"
+
+// VoucherBatch 管理员批量生成的兑换码批次
+type VoucherBatch struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name           string     `gorm:"not null" json:"name"`                      // 批次名称，如活动名
+	Value          int64      `gorm:"not null" json:"value"`                     // 每次兑换增加的额度
+	Count          int        `gorm:"not null" json:"count"`                     // 生成的兑换码数量
+	MaxRedemptions int        `gorm:"not null;default:1" json:"max_redemptions"` // 每个兑换码可被兑换的次数（不同用户）
+	ExpiresAt      *time.Time `json:"expires_at"`                                // 过期时间，为空表示永不过期
+	CreatedBy      uint64     `json:"created_by"`                                // 创建者用户 ID
+	AutoCreateUpdateDeleteAt
+}
+
+// Voucher 兑换码
+type Voucher struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	BatchID        uint64     `gorm:"index;not null" json:"batch_id"`
+	Code           string     `gorm:"uniqueIndex;not null" json:"code"`
+	Value          int64      `gorm:"not null" json:"value"`
+	MaxRedemptions int        `gorm:"not null;default:1" json:"max_redemptions"`
+	Redeemed       int        `gorm:"not null;default:0" json:"redeemed"` // 已兑换次数
+	ExpiresAt      *time.Time `json:"expires_at"`
+	Disabled       bool       `gorm:"default:false" json:"disabled"`
+	AutoCreateUpdateDeleteAt
+}
+
+// VoucherRedemption 兑换记录，同一用户对同一兑换码只能兑换一次
+type VoucherRedemption struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	VoucherID uint64 `gorm:"uniqueIndex:idx_voucher_redemption_user;not null" json:"voucher_id"`
+	UserID    uint64 `gorm:"uniqueIndex:idx_voucher_redemption_user;index;not null" json:"user_id"`
+	BatchID   uint64 `gorm:"index" json:"batch_id"`
+	Code      string `gorm:"not null" json:"code"`
+	Value     int64  `gorm:"not null" json:"value"` // 本次增加的额度
+	Balance   int64  `json:"balance"`               // 兑换后的余额
+	AutoCreateAt
+}
@@ -1,8 +1,12 @@
 package schema
 
+import "time"
+
 type UserUsage struct {
-	ID     uint64 `gorm:"primaryKey" json:"id" binding:"-"`
-	UserID uint64 `gorm:"unique" json:"user_id" binding:"required"`
-	Token  int64  `gorm:"default:0" json:"token" binding:"required"`
+	ID          uint64     `gorm:"primaryKey" json:"id" binding:"-"`
+	UserID      uint64     `gorm:"unique" json:"user_id" binding:"required"`
+	Token       int64      `gorm:"default:0" json:"token" binding:"required"`
+	PlanToken   int64      `gorm:"default:0" json:"plan_token"` // 套餐当月剩余额度，扣费时优先扣减，每月重置
+	PlanResetAt *time.Time `json:"plan_reset_at"`               // 套餐额度上次重置时间
 	AutoCreateAt
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"gorm.io/datatypes"
+)
+
+var (
+	accessTokenServiceInstance *AccessTokenService
+	accessTokenServiceOnce     sync.Once
+)
+
+// AccessTokenService 个人访问令牌
+//
+// 令牌以 AccessTokenPrefix 开头，数据库中仅保存其 SHA-256 摘要；令牌只能访问 Scopes 中的接口，且不超出用户自身的权限
+type AccessTokenService struct {
+	*BaseService
+}
+
+const (
+	AccessTokenPrefix = "oc-" // 令牌前缀，用于区分 JWT
+
+	accessTokenRandomLength  = 40          // 前缀之后的随机部分长度
+	accessTokenDisplayLength = 10          // 用于辨认令牌的前若干位
+	accessTokenTouchInterval = time.Minute // 最近使用时间的更新间隔，避免每次请求都写库
+
+	accessTokenAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var (
+	ErrAccessTokenInvalid = errors.New("invalid access token")
+	ErrAccessTokenExpired = errors.New("access token expired")
+)
+
+func InitAccessTokenService(base *BaseService) *AccessTokenService {
+	accessTokenServiceOnce.Do(
+		func() {
+			accessTokenServiceInstance = &AccessTokenService{
+				BaseService: base,
+			}
+		},
+	)
+	return accessTokenServiceInstance
+}
+
+func GetAccessTokenService() *AccessTokenService {
+	return accessTokenServiceInstance
+}
+
+// IsAccessToken 是否为个人访问令牌
+func IsAccessToken(rawToken string) bool {
+	return strings.HasPrefix(rawToken, AccessTokenPrefix)
+}
+
+func hashAccessToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetUserPermissionPaths 获取用户拥有的全部权限路径，超级管理员拥有全部权限
+func (s *AccessTokenService) GetUserPermissionPaths(userId uint64) ([]string, error) {
+	roles, err := s.Helper.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+	if slice.Some(roles, func(_ int, role schema.Role) bool { return role.Name == "SUPER_ADMIN" }) {
+		var paths []string
+		if err := s.Gorm.Model(&schema.Permission{}).Where("active = ?", true).Pluck("path", &paths).Error; err != nil {
+			return nil, err
+		}
+		return paths, nil
+	}
+	var paths []string
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			paths = append(paths, permission.Path)
+		}
+	}
+	return slice.Unique(paths), nil
+}
+
+// CreateAccessToken 创建令牌，返回仅此一次可见的令牌明文
+//
+//	Parameters:
+//		- userId: 用户 ID
+//		- name: 令牌名称
+//		- scopes: 权限路径，必须是用户已拥有的权限
+//		- expiresAt: 过期时间，为空表示永不过期
+//	Returns:
+//		- string: 令牌明文
+//		- *schema.AccessToken: 令牌记录
+//		- error: 错误信息
+func (s *AccessTokenService) CreateAccessToken(userId uint64, name string, scopes []string, expiresAt *time.Time) (string, *schema.AccessToken, error) {
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return "", nil, errors.New("expires_at must be in the future")
+	}
+	scopes = slice.Unique(scopes)
+	owned, err := s.GetUserPermissionPaths(userId)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(owned, scope) {
+			return "", nil, fmt.Errorf("scope not allowed: %s", scope)
+		}
+	}
+
+	secret, err := generateAccessTokenSecret()
+	if err != nil {
+		return "", nil, err
+	}
+	rawToken := AccessTokenPrefix + secret
+	token := &schema.AccessToken{
+		UserID:    userId,
+		Name:      name,
+		Prefix:    rawToken[:accessTokenDisplayLength],
+		TokenHash: hashAccessToken(rawToken),
+		Scopes:    datatypes.NewJSONType(scopes),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.Gorm.Create(token).Error; err != nil {
+		return "", nil, err
+	}
+	return rawToken, token, nil
+}
+
+// generateAccessTokenSecret 使用 crypto/rand 生成令牌的随机部分，丢弃超出字母表整数倍的字节以避免取模偏差
+func generateAccessTokenSecret() (string, error) {
+	const limit = 256 - 256%len(accessTokenAlphabet)
+	var sb strings.Builder
+	buf := make([]byte, accessTokenRandomLength)
+	for sb.Len() < accessTokenRandomLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			sb.WriteByte(accessTokenAlphabet[int(b)%len(accessTokenAlphabet)])
+			if sb.Len() == accessTokenRandomLength {
+				break
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// ListAccessTokens 获取用户的全部令牌
+func (s *AccessTokenService) ListAccessTokens(userId uint64) ([]schema.AccessToken, error) {
+	var tokens []schema.AccessToken
+	if err := s.Gorm.Where("user_id = ?", userId).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeAccessToken 撤销用户的令牌
+func (s *AccessTokenService) RevokeAccessToken(userId uint64, tokenId uint64) error {
+	result := s.Gorm.Where("id = ? AND user_id = ?", tokenId, userId).Delete(&schema.AccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccessTokenInvalid
+	}
+	return nil
+}
+
+// ValidateAccessToken 校验令牌明文，有效时返回令牌记录并更新最近使用时间
+func (s *AccessTokenService) ValidateAccessToken(rawToken string) (*schema.AccessToken, error) {
+	if !IsAccessToken(rawToken) {
+		return nil, ErrAccessTokenInvalid
+	}
+	var token schema.AccessToken
+	if err := s.Gorm.Where("token_hash = ?", hashAccessToken(rawToken)).First(&token).Error; err != nil {
+		return nil, ErrAccessTokenInvalid
+	}
+	if token.IsExpired() {
+		return nil, ErrAccessTokenExpired
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > accessTokenTouchInterval {
+		if err := s.Gorm.Model(&schema.AccessToken{}).Where("id = ?", token.ID).UpdateColumn("last_used_at", now).Error; err != nil {
+			s.Logger.Warn("failed to update access token last used time", "token_id", token.ID, "error", err.Error())
+		}
+	}
+	return &token, nil
+}
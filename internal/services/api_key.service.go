@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+)
+
+var (
+	apiKeyServiceInstance *APIKeyService
+	apiKeyServiceOnce     sync.Once
+)
+
+// APIKeyService 提供商 API Key 的健康检查及熔断
+//
+// 限流（429）及超时的密钥按连续失败次数指数冷却，冷却期间不被选用；鉴权失败（401/403）的密钥直接停用
+type APIKeyService struct {
+	*BaseService
+}
+
+const (
+	apiKeyBaseCooldown = 10 * time.Second // 首次失败的冷却时间
+	apiKeyMaxCooldown  = 30 * time.Minute // 冷却时间上限
+)
+
+// ErrNoAvailableAPIKey 提供商没有可用的 API Key
+var ErrNoAvailableAPIKey = errors.New("no available api key")
+
+func InitAPIKeyService(base *BaseService) *APIKeyService {
+	apiKeyServiceOnce.Do(
+		func() {
+			apiKeyServiceInstance = &APIKeyService{
+				BaseService: base,
+			}
+		},
+	)
+	return apiKeyServiceInstance
+}
+
+func GetAPIKeyService() *APIKeyService {
+	return apiKeyServiceInstance
+}
+
+// PickAPIKey 从提供商的密钥中随机选择一个可用的密钥
+//
+// 跳过已停用的密钥；全部密钥均在冷却中时，选择最早结束冷却的密钥，避免请求直接失败
+func (s *APIKeyService) PickAPIKey(apiKeys []schema.APIKey) (schema.APIKey, error) {
+	apiKeys = slice.Filter(apiKeys, func(_ int, key schema.APIKey) bool { return !key.Disabled })
+	if len(apiKeys) == 0 {
+		return schema.APIKey{}, ErrNoAvailableAPIKey
+	}
+	healths, err := s.RedisStore.GetAPIKeyHealth(slice.Map(apiKeys, func(_ int, key schema.APIKey) uint64 { return key.ID }))
+	if err != nil {
+		// 无法获取健康状态时不影响正常请求
+		s.Logger.Warn("failed to load api key health", "error", err.Error())
+		key, _ := slice.Random(apiKeys)
+		return key, nil
+	}
+
+	var active, cooling []schema.APIKey
+	for _, key := range apiKeys {
+		switch healths[key.ID].Status {
+		case schema.APIKeyStatusActive:
+			active = append(active, key)
+		case schema.APIKeyStatusCooling:
+			cooling = append(cooling, key)
+		}
+	}
+	if len(active) > 0 {
+		key, _ := slice.Random(active)
+		return key, nil
+	}
+	if len(cooling) == 0 {
+		return schema.APIKey{}, ErrNoAvailableAPIKey
+	}
+	earliest := cooling[0]
+	for _, key := range cooling[1:] {
+		if healths[key.ID].CooldownUntil.Before(*healths[earliest.ID].CooldownUntil) {
+			earliest = key
+		}
+	}
+	return earliest, nil
+}
+
+// RecordResult 根据调用结果更新密钥的健康状态
+func (s *APIKeyService) RecordResult(apiKeyId uint64, err error) {
+	if apiKeyId == 0 {
+		return
+	}
+	if err == nil {
+		if err := s.RedisStore.RecordAPIKeySuccess(apiKeyId); err != nil {
+			s.Logger.Warn("failed to record api key success", "api_key_id", apiKeyId, "error", err.Error())
+		}
+		return
+	}
+
+	var kind string
+	switch statusCode := chat_utils.ErrorStatusCode(err); {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		s.DisableAPIKey(apiKeyId, err.Error())
+		return
+	case statusCode == http.StatusTooManyRequests:
+		kind = strconv.Itoa(statusCode)
+	case chat_utils.IsTimeoutError(err):
+		kind = "timeout"
+	default:
+		// 其它错误与密钥无关
+		return
+	}
+	cooldown, recordErr := s.RedisStore.RecordAPIKeyFailure(apiKeyId, kind, err.Error(), apiKeyBaseCooldown, apiKeyMaxCooldown)
+	if recordErr != nil {
+		s.Logger.Warn("failed to record api key failure", "api_key_id", apiKeyId, "error", recordErr.Error())
+		return
+	}
+	s.Logger.Warn("api key cooling down", "api_key_id", apiKeyId, "kind", kind, "cooldown", cooldown.String())
+}
+
+// DisableAPIKey 停用密钥
+func (s *APIKeyService) DisableAPIKey(apiKeyId uint64, reason string) {
+	if err := s.Gorm.Model(&schema.APIKey{}).Where("id = ?", apiKeyId).Updates(
+		map[string]any{
+			"disabled":        true,
+			"disabled_reason": reason,
+		},
+	).Error; err != nil {
+		s.Logger.Error("failed to disable api key", "api_key_id", apiKeyId, "error", err.Error())
+	}
+	if err := s.RedisStore.DisableAPIKeyHealth(apiKeyId, reason); err != nil {
+		s.Logger.Error("failed to disable api key in cache", "api_key_id", apiKeyId, "error", err.Error())
+	}
+	s.Logger.Warn("api key disabled", "api_key_id", apiKeyId, "reason", reason)
+}
+
+// EnableAPIKey 重新启用密钥，并清除其健康数据
+func (s *APIKeyService) EnableAPIKey(apiKeyId uint64) error {
+	if err := s.Gorm.Model(&schema.APIKey{}).Where("id = ?", apiKeyId).Updates(
+		map[string]any{
+			"disabled":        false,
+			"disabled_reason": "",
+		},
+	).Error; err != nil {
+		return err
+	}
+	return s.RedisStore.ResetAPIKeyHealth(apiKeyId)
+}
+
+// FillHealth 组装密钥的健康状态
+func (s *APIKeyService) FillHealth(apiKeys []schema.APIKey) error {
+	healths, err := s.RedisStore.GetAPIKeyHealth(slice.Map(apiKeys, func(_ int, key schema.APIKey) uint64 { return key.ID }))
+	if err != nil {
+		return err
+	}
+	for i := range apiKeys {
+		health := healths[apiKeys[i].ID]
+		if apiKeys[i].Disabled {
+			health.Status = schema.APIKeyStatusDisabled
+		}
+		apiKeys[i].Health = &health
+	}
+	return nil
+}
@@ -1,11 +1,15 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/duke-git/lancet/v2/slice"
 	"github.com/fcraft/open-chat/internal/schema"
 	"github.com/fcraft/open-chat/internal/utils/chat_utils"
 	"gorm.io/datatypes"
 	"sync"
+	"time"
 )
 
 type ChatService struct {
@@ -77,8 +81,11 @@ func InitChatService(base *BaseService) *ChatService {
 
 const (
 	ChatOnlineSearchServiceBaseURL      = "chat_online_search_searxng_service"
+	ChatMaxToolSteps                    = "chat_max_tool_steps"
+	ChatFailoverMaxRetries              = "chat_failover_max_retries"
 	ChatSessionTitleGeneratePresetName  = "chat_session_title_generate"
 	ChatSearchKeywordGeneratePresetName = "chat_search_keyword_generate"
+	ChatSessionSummaryPresetName        = "chat_session_summary"
 )
 
 func registerSystemConfig() {
@@ -100,6 +107,93 @@ func registerSystemConfig() {
 	if err != nil {
 		return
 	}
+	err = GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ChatMaxToolSteps,
+			DisplayName: "工具调用最大轮数",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     20,
+				"description": "max rounds of tool calls in one completion",
+			},
+			Default:  datatypes.NewJSONType[any](chat_utils.DefaultMaxToolSteps),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+	err = GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ChatFailoverMaxRetries,
+			DisplayName: "模型故障转移最大重试次数",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     10,
+				"description": "max fallback models to try when the selected model fails before streaming",
+			},
+			Default:  datatypes.NewJSONType[any](defaultFailoverMaxRetries),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// GetMaxToolSteps 获取单次对话中工具调用的最大轮数
+func (s *ChatService) GetMaxToolSteps() int {
+	config, err := GetSystemConfigService().GetConfig(ChatMaxToolSteps)
+	if err != nil {
+		return chat_utils.DefaultMaxToolSteps
+	}
+	var steps int
+	if err := json.Unmarshal(config.Value, &steps); err != nil || steps <= 0 {
+		return chat_utils.DefaultMaxToolSteps
+	}
+	return steps
+}
+
+// defaultFailoverMaxRetries 默认的故障转移最大重试次数
+const defaultFailoverMaxRetries = 2
+
+// GetFailoverMaxRetries 获取模型故障转移的最大重试次数，即最多尝试的备用模型数
+func (s *ChatService) GetFailoverMaxRetries() int {
+	config, err := GetSystemConfigService().GetConfig(ChatFailoverMaxRetries)
+	if err != nil {
+		return defaultFailoverMaxRetries
+	}
+	var retries int
+	if err := json.Unmarshal(config.Value, &retries); err != nil || retries < 0 {
+		return defaultFailoverMaxRetries
+	}
+	return retries
+}
+
+// GetFallbackTargets 将候选模型转换为故障转移的备用目标，数量受最大重试次数限制
+func (s *ChatService) GetFallbackTargets(models []schema.Model, modelConfig func(schema.ModelConfig) chat_utils.CompletionModelConfig) []chat_utils.CompletionTarget {
+	var targets []chat_utils.CompletionTarget
+	maxRetries := s.GetFailoverMaxRetries()
+	for _, model := range models {
+		if len(targets) >= maxRetries {
+			break
+		}
+		if model.Provider == nil {
+			continue
+		}
+		apiKey, err := GetAPIKeyService().PickAPIKey(model.Provider.APIKeys)
+		if err != nil {
+			continue
+		}
+		target := chat_utils.GetCompletionTarget(model, apiKey)
+		if modelConfig != nil {
+			target.CompletionModelConfig = modelConfig(model.Config)
+		}
+		targets = append(targets, target)
+	}
+	return targets
 }
 
 func registerBuiltinPreset() {
@@ -117,6 +211,22 @@ func registerBuiltinPreset() {
 		},
 	)
 
+	// 滚动摘要
+	GetPresetService().RegisterBuiltinPresetsSimple(
+		ChatSessionSummaryPresetName, "对话滚动摘要", 1, "", []chat_utils.Message{
+			chat_utils.UserMessage(
+				`
+你的任务是维护一段长对话的滚动摘要。请将已有摘要与新增的对话内容合并为一份新的摘要。
+要求：保留关键事实、用户的偏好与要求、已得出的结论及尚未解决的问题，省略寒暄，控制在 500 字以内。
+已有摘要：{SUMMARY}
+新增对话内容：{CONTENT}
+摘要语言：对话中的主要自然语言
+摘要输出在<summary></summary>中
+`,
+			),
+		},
+	)
+
 	// 提炼搜索词
 	GetPresetService().RegisterBuiltinPresetsSimple(
 		ChatSearchKeywordGeneratePresetName, "搜索词提炼", 2, "", []chat_utils.Message{
@@ -131,6 +241,79 @@ func registerBuiltinPreset() {
 	)
 }
 
+const (
+	summaryTriggerMessages = 30 // 未摘要的消息超过该条数时更新摘要
+	summaryKeepMessages    = 10 // 更新摘要时保留不折叠的最近消息条数
+)
+
+// UpdateSessionSummary 当分支上未摘要的消息过多时，将较早的消息折叠进会话的滚动摘要
+//
+//	Parameters:
+//		- sessionID: 会话 ID
+//		- leafId: 当前分支的末端消息 ID
+func (s *ChatService) UpdateSessionSummary(sessionID string, leafId uint64) error {
+	// 同一会话同一时间只进行一次摘要
+	lockKey := "session-summary-lock:" + sessionID
+	locked, err := s.Redis.SetNX(context.Background(), lockKey, 1, 5*time.Minute).Result()
+	if err != nil || !locked {
+		return err
+	}
+	defer s.Redis.Del(context.Background(), lockKey)
+
+	var session schema.Session
+	if err := s.Gorm.First(&session, "id = ?", sessionID).Error; err != nil {
+		return err
+	}
+	messages, err := s.GormStore.GetBranchMessages(leafId, -1)
+	if err != nil {
+		return err
+	}
+
+	// 找出摘要之后的消息，摘要不在当前分支上时从头开始
+	summary := session.Summary
+	start := slice.IndexOf(
+		slice.Map(messages, func(_ int, m schema.Message) uint64 { return m.ID }),
+		session.SummaryMessageID,
+	) + 1
+	if start == 0 {
+		summary = ""
+	}
+	pending := messages[start:]
+	if len(pending) <= summaryTriggerMessages {
+		return nil
+	}
+	folded := pending[:len(pending)-summaryKeepMessages]
+
+	strMessages, err := json.Marshal(chat_utils.ConvertSchemaToMessages(folded))
+	if err != nil {
+		return err
+	}
+	if summary == "" {
+		summary = "无"
+	}
+	completion, _, err := BuiltinPresetCompletion(
+		ChatSessionSummaryPresetName,
+		map[string]string{
+			"SUMMARY": summary,
+			"CONTENT": string(strMessages),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	newSummary := chat_utils.ExtractTagContent(completion, "summary")
+	if newSummary == "" {
+		return fmt.Errorf("empty summary for session %s", sessionID)
+	}
+
+	return s.Gorm.Model(&schema.Session{}).Where("id = ?", sessionID).Updates(
+		map[string]any{
+			"summary":            newSummary,
+			"summary_message_id": folded[len(folded)-1].ID,
+		},
+	).Error
+}
+
 // GetChatService 获取对话服务
 func GetChatService() *ChatService {
 	if chatServiceInstance == nil {
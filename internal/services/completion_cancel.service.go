@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	completionCancelServiceInstance *CompletionCancelService
+	completionCancelServiceOnce     sync.Once
+)
+
+// ErrCompletionStopped 用户主动停止生成
+var ErrCompletionStopped = errors.New("completion stopped by user")
+
+const completionStopChannel = "completion-stop"
+
+func completionRunningKey(messageId uint64) string {
+	return fmt.Sprintf("completion-running:%d", messageId)
+}
+
+// CompletionCancelService 管理进行中的补全，支持跨实例停止生成
+type CompletionCancelService struct {
+	BaseService *BaseService
+	cancels     sync.Map // 回复消息 ID -> context.CancelCauseFunc
+}
+
+func InitCompletionCancelService(base *BaseService) *CompletionCancelService {
+	completionCancelServiceOnce.Do(
+		func() {
+			completionCancelServiceInstance = &CompletionCancelService{
+				BaseService: base,
+			}
+			go completionCancelServiceInstance.subscribe()
+		},
+	)
+	return completionCancelServiceInstance
+}
+
+func GetCompletionCancelService() *CompletionCancelService {
+	return completionCancelServiceInstance
+}
+
+// Register 登记进行中的补全，返回注销函数
+//
+// 同时在 Redis 中标记补全进行中，expire 为标记的最长有效期，防止实例异常退出后标记残留
+func (s *CompletionCancelService) Register(messageId uint64, cancel context.CancelCauseFunc, expire time.Duration) func() {
+	s.cancels.Store(messageId, cancel)
+	if err := s.BaseService.Redis.Set(context.Background(), completionRunningKey(messageId), 1, expire).Err(); err != nil {
+		s.BaseService.Logger.Warn("failed to mark completion running", "message_id", messageId, "error", err.Error())
+	}
+	return func() {
+		s.cancels.Delete(messageId)
+		_ = s.BaseService.Redis.Del(context.Background(), completionRunningKey(messageId)).Err()
+	}
+}
+
+// IsRunning 判断补全是否仍在进行中，可能运行在任意实例上
+func (s *CompletionCancelService) IsRunning(messageId uint64) bool {
+	count, err := s.BaseService.Redis.Exists(context.Background(), completionRunningKey(messageId)).Result()
+	return err == nil && count > 0
+}
+
+// Stop 停止补全，通过 Redis 广播到所有实例
+func (s *CompletionCancelService) Stop(messageId uint64) error {
+	// 本实例直接取消，无需等待广播
+	s.cancelLocal(messageId)
+	return s.BaseService.Redis.Publish(
+		context.Background(),
+		completionStopChannel,
+		strconv.FormatUint(messageId, 10),
+	).Err()
+}
+
+// cancelLocal 取消本实例上的补全
+func (s *CompletionCancelService) cancelLocal(messageId uint64) bool {
+	cancel, ok := s.cancels.Load(messageId)
+	if !ok {
+		return false
+	}
+	cancel.(context.CancelCauseFunc)(ErrCompletionStopped)
+	return true
+}
+
+// subscribe 监听其他实例发出的停止请求
+func (s *CompletionCancelService) subscribe() {
+	pubsub := s.BaseService.Redis.Subscribe(context.Background(), completionStopChannel)
+	defer func() {
+		_ = pubsub.Close()
+	}()
+	for msg := range pubsub.Channel() {
+		messageId, err := strconv.ParseUint(msg.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		s.cancelLocal(messageId)
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/schema"
+	redisstore "github.com/fcraft/open-chat/internal/storage/redis"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+)
+
+var (
+	embeddingServiceInstance *EmbeddingService
+	embeddingServiceOnce     sync.Once
+)
+
+// EmbeddingService 文本向量化
+//
+// 按模型集合路由到向量化模型，失败时按集合的负载均衡顺序故障转移；向量按内容哈希缓存在 Redis 中，相同文本不会重复向量化
+type EmbeddingService struct {
+	*BaseService
+}
+
+const embeddingCacheExpiration = 7 * 24 * time.Hour // 向量缓存的有效期
+
+var ErrNoEmbeddingModel = errors.New("no embedding model available")
+
+func InitEmbeddingService(base *BaseService) *EmbeddingService {
+	embeddingServiceOnce.Do(
+		func() {
+			embeddingServiceInstance = &EmbeddingService{
+				BaseService: base,
+			}
+		},
+	)
+	return embeddingServiceInstance
+}
+
+func GetEmbeddingService() *EmbeddingService {
+	return embeddingServiceInstance
+}
+
+// embeddingCache 基于 Redis 的向量缓存，实现 chat_utils.EmbeddingCache
+type embeddingCache struct {
+	store *redisstore.RedisStore
+}
+
+func (c embeddingCache) GetEmbeddings(_ context.Context, namespace string, hashes []string) (map[string][]float32, error) {
+	return c.store.GetEmbeddings(namespace, hashes)
+}
+
+func (c embeddingCache) SetEmbeddings(_ context.Context, namespace string, embeddings map[string][]float32) error {
+	return c.store.CacheEmbeddings(namespace, embeddings, embeddingCacheExpiration)
+}
+
+// CheckQuota 向量化前检查用户的余额及额度是否足够支付 tokens 个 token，额度不足时返回 constants.BizErr* 错误
+func (s *EmbeddingService) CheckQuota(collection string, userId uint64, tokens int64) error {
+	if GetModelCollectionService() == nil {
+		return ErrNoEmbeddingModel
+	}
+	candidates, err := GetModelCollectionService().GetEmbeddingCandidatesFromCollection(collection)
+	if err != nil || len(candidates) == 0 {
+		return ErrNoEmbeddingModel
+	}
+	usageService := GetUsageService()
+	return usageService.CheckQuota(userId, usageService.EstimateEmbeddingCharge(candidates, tokens))
+}
+
+// Embed 使用模型集合中的向量化模型向量化文本，返回的向量与输入顺序一致，并记录用量到 userId
+func (s *EmbeddingService) Embed(ctx context.Context, collection string, userId uint64, texts []string) ([]schema.Vector, error) {
+	if GetModelCollectionService() == nil {
+		return nil, ErrNoEmbeddingModel
+	}
+	candidates, err := GetModelCollectionService().GetEmbeddingCandidatesFromCollection(collection)
+	if err != nil || len(candidates) == 0 {
+		return nil, ErrNoEmbeddingModel
+	}
+
+	var targets []chat_utils.EmbeddingTarget
+	for _, model := range candidates {
+		if model.Provider == nil {
+			continue
+		}
+		apiKey, err := GetAPIKeyService().PickAPIKey(model.Provider.APIKeys)
+		if err != nil {
+			continue
+		}
+		targets = append(targets, chat_utils.GetEmbeddingTarget(model, apiKey))
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoEmbeddingModel
+	}
+
+	resp, err := chat_utils.Embeddings(
+		ctx, chat_utils.EmbeddingOptions{
+			EmbeddingTarget: targets[0],
+			Input:           texts,
+			Fallbacks:       targets[1:],
+			OnAttempt:       GetModelCollectionService().RecordEmbeddingAttempt,
+			Cache:           embeddingCache{store: s.RedisStore},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.PromptTokens > 0 {
+		if _, err := GetUsageService().RecordUsage(
+			UsageRecordParams{
+				UserID:   userId,
+				Source:   schema.UsageRecordSourceEmbedding,
+				ModelID:  resp.ModelID,
+				APIKeyID: resp.APIKeyID,
+				Usage: chat_utils.DoneResponseUsage{
+					PromptTokens: resp.PromptTokens,
+				},
+			},
+		); err != nil {
+			s.Logger.Warn("failed to record embedding usage", "model_id", resp.ModelID, "error", err.Error())
+		}
+	}
+
+	vectors := make([]schema.Vector, len(resp.Embeddings))
+	for i, vec := range resp.Embeddings {
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
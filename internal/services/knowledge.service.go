@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"github.com/fcraft/open-chat/internal/utils/knowledge_utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	knowledgeServiceInstance *KnowledgeService
+	knowledgeServiceOnce     sync.Once
+)
+
+// KnowledgeService 知识库
+//
+// 上传的文档提取正文后异步分段及向量化，向量以 real[] 存储在 Postgres 中。
+// 检索在数据库中完成：安装了 pgvector 扩展时转换为 vector 类型计算余弦距离，否则使用 SQL 逐条计算余弦相似度
+type KnowledgeService struct {
+	*BaseService
+	pgvector bool // 数据库是否可用 pgvector 扩展
+}
+
+const (
+	ConfigKnowledgeEmbeddingCollection = "knowledge_embedding_collection"
+
+	defaultKnowledgeEmbeddingCollection = "text-embedding" // 默认的向量化模型集合
+	KnowledgeMaxDocumentBytes           = 10 << 20         // 单个文档的最大字节数
+	KnowledgeMaxTopK                    = 20               // 单次检索的最大分段数量
+	KnowledgeMaxChunkSize               = 2000             // 分段大小（token）的上限
+	knowledgeEmbeddingTimeout           = 30 * time.Second // 检索时向量化查询的超时时间
+	knowledgeDocumentEmbedTimeout       = 10 * time.Minute // 向量化单个文档的超时时间
+	knowledgeProcessInterval            = time.Minute      // 检查未完成文档的间隔
+	knowledgeProcessStaleAfter          = 10 * time.Minute // 处理中的文档超过该时间未更新时视为中断，重新处理
+)
+
+var ErrKnowledgeDocumentTooLarge = errors.New("document too large")
+
+// RetrievedChunk 检索到的分段
+type RetrievedChunk struct {
+	ChunkID      uint64  `json:"chunk_id"`
+	DocumentID   uint64  `json:"document_id"`
+	DocumentName string  `json:"document_name"`
+	Content      string  `json:"content"`
+	Score        float64 `json:"score"` // 余弦相似度
+}
+
+// KnowledgeSource 回答引用的知识库分段，Index 与提示词中的编号一致
+type KnowledgeSource struct {
+	Index int `json:"index"`
+	RetrievedChunk
+}
+
+// KnowledgeContext 一次知识库检索的结果
+type KnowledgeContext struct {
+	KnowledgeBaseID uint64            `json:"knowledge_base_id"`
+	Name            string            `json:"name"`
+	Prompt          string            `json:"-"` // 带编号引用的提示词
+	Sources         []KnowledgeSource `json:"sources"`
+}
+
+func InitKnowledgeService(base *BaseService) *KnowledgeService {
+	knowledgeServiceOnce.Do(
+		func() {
+			knowledgeServiceInstance = &KnowledgeService{
+				BaseService: base,
+			}
+			registerKnowledgeConfig()
+			knowledgeServiceInstance.pgvector = knowledgeServiceInstance.detectPgvector()
+			base.Logger.Info("knowledge vector search initialized", "pgvector", knowledgeServiceInstance.pgvector)
+			err := GetScheduleService().RegisterSchedule(
+				"process_knowledge_documents", "处理未完成的知识库文档", knowledgeProcessInterval, knowledgeServiceInstance.ProcessPendingDocuments,
+			)
+			if err != nil {
+				return
+			}
+		},
+	)
+	return knowledgeServiceInstance
+}
+
+func GetKnowledgeService() *KnowledgeService {
+	return knowledgeServiceInstance
+}
+
+func registerKnowledgeConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigKnowledgeEmbeddingCollection,
+			DisplayName: "知识库向量化模型集合",
+			Schema: map[string]interface{}{
+				"type":        "string",
+				"description": "model collection used to embed documents of newly created knowledge bases",
+			},
+			Default:  datatypes.NewJSONType[any](defaultKnowledgeEmbeddingCollection),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// detectPgvector 尝试启用 pgvector 扩展，无权限或未安装时使用 SQL 计算余弦相似度
+func (s *KnowledgeService) detectPgvector() bool {
+	if err := s.Gorm.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		s.Logger.Info("pgvector extension unavailable, fallback to brute-force cosine", "error", err.Error())
+	}
+	var installed bool
+	if err := s.Gorm.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')").Scan(&installed).Error; err != nil {
+		return false
+	}
+	return installed
+}
+
+// getDefaultEmbeddingCollection 获取新建知识库默认使用的向量化模型集合
+func (s *KnowledgeService) getDefaultEmbeddingCollection() string {
+	config, err := GetSystemConfigService().GetConfig(ConfigKnowledgeEmbeddingCollection)
+	if err != nil {
+		return defaultKnowledgeEmbeddingCollection
+	}
+	var collection string
+	if err := json.Unmarshal(config.Value, &collection); err != nil || collection == "" {
+		return defaultKnowledgeEmbeddingCollection
+	}
+	return collection
+}
+
+// CanAccess 用户能否检索知识库，创建者及公开知识库可检索
+func (s *KnowledgeService) CanAccess(userId uint64, kb *schema.KnowledgeBase) bool {
+	return kb.OwnerID == userId || kb.IsPublic
+}
+
+// RetrievalTokens 一次检索最多注入提示词的 token 数，用于在组装上下文时预留
+func (s *KnowledgeService) RetrievalTokens(kb *schema.KnowledgeBase) int64 {
+	return int64(knowledgeTopK(kb)) * int64(min(kb.ChunkSize, KnowledgeMaxChunkSize))
+}
+
+// knowledgeTopK 知识库每次检索的分段数量，未配置或超出上限时使用上限
+func knowledgeTopK(kb *schema.KnowledgeBase) int {
+	if kb.TopK <= 0 || kb.TopK > KnowledgeMaxTopK {
+		return KnowledgeMaxTopK
+	}
+	return kb.TopK
+}
+
+// GetKnowledgeBase 获取知识库
+func (s *KnowledgeService) GetKnowledgeBase(id uint64) (*schema.KnowledgeBase, error) {
+	var kb schema.KnowledgeBase
+	if err := s.Gorm.First(&kb, id).Error; err != nil {
+		return nil, err
+	}
+	return &kb, nil
+}
+
+// CreateKnowledgeBase 创建知识库，未指定的参数使用默认值
+func (s *KnowledgeService) CreateKnowledgeBase(kb *schema.KnowledgeBase) error {
+	if kb.EmbeddingCollection == "" {
+		kb.EmbeddingCollection = s.getDefaultEmbeddingCollection()
+	}
+	if kb.ChunkSize <= 0 {
+		kb.ChunkSize = 500
+	}
+	kb.ChunkSize = min(kb.ChunkSize, KnowledgeMaxChunkSize)
+	if kb.ChunkOverlap < 0 || kb.ChunkOverlap >= kb.ChunkSize {
+		kb.ChunkOverlap = kb.ChunkSize / 10
+	}
+	if kb.TopK <= 0 || kb.TopK > KnowledgeMaxTopK {
+		kb.TopK = 5
+	}
+	kb.DocumentCount, kb.ChunkCount = 0, 0
+	return s.Gorm.Create(kb).Error
+}
+
+// DeleteKnowledgeBase 删除知识库及其文档和分段
+func (s *KnowledgeService) DeleteKnowledgeBase(id uint64) error {
+	return s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Where("knowledge_base_id = ?", id).Delete(&schema.KnowledgeChunk{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("knowledge_base_id = ?", id).Delete(&schema.KnowledgeDocument{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schema.KnowledgeBase{}, id).Error
+		},
+	)
+}
+
+// AddDocument 提取文档正文并加入知识库，分段及向量化在后台进行
+func (s *KnowledgeService) AddDocument(kb *schema.KnowledgeBase, name string, mimeType string, data []byte) (*schema.KnowledgeDocument, error) {
+	if len(data) > KnowledgeMaxDocumentBytes {
+		return nil, ErrKnowledgeDocumentTooLarge
+	}
+	content, err := knowledge_utils.ExtractText(name, mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+	doc := &schema.KnowledgeDocument{
+		KnowledgeBaseID: kb.ID,
+		Name:            name,
+		MimeType:        mimeType,
+		Size:            int64(len(data)),
+		Content:         content,
+		Status:          schema.KnowledgeDocumentStatusPending,
+		Tokens:          chat_utils.EstimateTokens(content),
+	}
+	// 向量化的用量计入知识库创建者
+	if err := GetEmbeddingService().CheckQuota(kb.EmbeddingCollection, kb.OwnerID, doc.Tokens); err != nil {
+		return nil, err
+	}
+	if err := s.Gorm.Create(doc).Error; err != nil {
+		return nil, err
+	}
+	s.refreshCounts(kb.ID)
+	go s.processDocument(doc.ID)
+	return doc, nil
+}
+
+// ReprocessDocument 重新分段及向量化文档，用于处理失败后重试
+func (s *KnowledgeService) ReprocessDocument(kb *schema.KnowledgeBase, doc *schema.KnowledgeDocument) error {
+	if err := GetEmbeddingService().CheckQuota(kb.EmbeddingCollection, kb.OwnerID, doc.Tokens); err != nil {
+		return err
+	}
+	if err := s.Gorm.Model(doc).Updates(
+		map[string]interface{}{
+			"status": schema.KnowledgeDocumentStatusPending,
+			"error":  "",
+		},
+	).Error; err != nil {
+		return err
+	}
+	go s.processDocument(doc.ID)
+	return nil
+}
+
+// DeleteDocument 删除文档及其分段
+func (s *KnowledgeService) DeleteDocument(doc *schema.KnowledgeDocument) error {
+	err := s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Where("document_id = ?", doc.ID).Delete(&schema.KnowledgeChunk{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(doc).Error
+		},
+	)
+	if err != nil {
+		return err
+	}
+	s.refreshCounts(doc.KnowledgeBaseID)
+	return nil
+}
+
+// refreshCounts 更新知识库的文档及分段数量
+func (s *KnowledgeService) refreshCounts(kbId uint64) {
+	if err := s.Gorm.Exec(
+		`UPDATE knowledge_bases SET
+			document_count = (SELECT COUNT(*) FROM knowledge_documents WHERE knowledge_base_id = ?),
+			chunk_count = (SELECT COUNT(*) FROM knowledge_chunks WHERE knowledge_base_id = ?)
+		WHERE id = ?`, kbId, kbId, kbId,
+	).Error; err != nil {
+		s.Logger.Warn("failed to refresh knowledge base counts", "knowledge_base_id", kbId, "error", err.Error())
+	}
+}
+
+// ProcessPendingDocuments 定时任务：处理等待中及处理中断的文档，如服务重启前未完成的文档
+func (s *KnowledgeService) ProcessPendingDocuments() error {
+	if GetModelCollectionService() == nil {
+		// 模型集合服务尚未初始化
+		return nil
+	}
+	var ids []uint64
+	if err := s.Gorm.Model(&schema.KnowledgeDocument{}).
+		Where(
+			"status = ? OR (status = ? AND updated_at < ?)",
+			schema.KnowledgeDocumentStatusPending, schema.KnowledgeDocumentStatusProcessing, time.Now().Add(-knowledgeProcessStaleAfter),
+		).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		s.processDocument(id)
+	}
+	return nil
+}
+
+// processDocument 分段并向量化文档，替换原有的分段
+func (s *KnowledgeService) processDocument(docId uint64) {
+	// 条件更新认领文档，避免多个实例或定时任务重复处理
+	result := s.Gorm.Model(&schema.KnowledgeDocument{}).
+		Where(
+			"id = ? AND (status = ? OR (status = ? AND updated_at < ?))",
+			docId, schema.KnowledgeDocumentStatusPending, schema.KnowledgeDocumentStatusProcessing, time.Now().Add(-knowledgeProcessStaleAfter),
+		).
+		Update("status", schema.KnowledgeDocumentStatusProcessing)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+	var doc schema.KnowledgeDocument
+	if err := s.Gorm.First(&doc, docId).Error; err != nil {
+		return
+	}
+	kb, err := s.GetKnowledgeBase(doc.KnowledgeBaseID)
+	if err != nil {
+		return
+	}
+
+	count, err := s.embedDocument(kb, &doc)
+	if err != nil {
+		s.Logger.Warn("failed to process knowledge document", "document_id", doc.ID, "error", err.Error())
+		s.Gorm.Model(&doc).Updates(
+			map[string]interface{}{
+				"status": schema.KnowledgeDocumentStatusFailed,
+				"error":  err.Error(),
+			},
+		)
+		return
+	}
+	s.Gorm.Model(&doc).Updates(
+		map[string]interface{}{
+			"status":      schema.KnowledgeDocumentStatusReady,
+			"error":       "",
+			"chunk_count": count,
+		},
+	)
+	s.refreshCounts(kb.ID)
+}
+
+func (s *KnowledgeService) embedDocument(kb *schema.KnowledgeBase, doc *schema.KnowledgeDocument) (int, error) {
+	texts := knowledge_utils.SplitText(doc.Content, int64(kb.ChunkSize), int64(kb.ChunkOverlap))
+	if len(texts) == 0 {
+		return 0, knowledge_utils.ErrEmptyDocument
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), knowledgeDocumentEmbedTimeout)
+	defer cancel()
+	vectors, err := GetEmbeddingService().Embed(ctx, kb.EmbeddingCollection, kb.OwnerID, texts)
+	if err != nil {
+		return 0, err
+	}
+	chunks := make([]schema.KnowledgeChunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = schema.KnowledgeChunk{
+			KnowledgeBaseID: kb.ID,
+			DocumentID:      doc.ID,
+			Index:           i,
+			Content:         text,
+			Tokens:          chat_utils.EstimateTokens(text),
+			Embedding:       vectors[i],
+			Dimensions:      len(vectors[i]),
+			Norm:            knowledge_utils.Norm(vectors[i]),
+		}
+	}
+	err = s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Where("document_id = ?", doc.ID).Delete(&schema.KnowledgeChunk{}).Error; err != nil {
+				return err
+			}
+			return tx.CreateInBatches(&chunks, 100).Error
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
+// Retrieve 检索知识库中与查询最相关的分段，按相似度降序，查询的向量化用量计入 userId
+func (s *KnowledgeService) Retrieve(ctx context.Context, kb *schema.KnowledgeBase, userId uint64, query string) ([]RetrievedChunk, error) {
+	if err := GetEmbeddingService().CheckQuota(kb.EmbeddingCollection, userId, chat_utils.EstimateTokens(query)); err != nil {
+		return nil, err
+	}
+	embedCtx, cancel := context.WithTimeout(ctx, knowledgeEmbeddingTimeout)
+	defer cancel()
+	vectors, err := GetEmbeddingService().Embed(embedCtx, kb.EmbeddingCollection, userId, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	vec := vectors[0]
+	topK := knowledgeTopK(kb)
+
+	tx := s.Gorm.WithContext(ctx).Model(&schema.KnowledgeChunk{}).
+		Joins("JOIN knowledge_documents ON knowledge_documents.id = knowledge_chunks.document_id")
+	if s.pgvector {
+		tx = tx.Select(
+			`knowledge_chunks.id AS chunk_id, knowledge_chunks.document_id, knowledge_documents.name AS document_name,
+			knowledge_chunks.content, 1 - (knowledge_chunks.embedding::vector <=> ?::vector) AS score`,
+			vec.String(),
+		)
+	} else {
+		tx = tx.Select(
+			`knowledge_chunks.id AS chunk_id, knowledge_chunks.document_id, knowledge_documents.name AS document_name,
+			knowledge_chunks.content,
+			(SELECT SUM(a * b) FROM unnest(knowledge_chunks.embedding, ?::real[]) AS t(a, b)) / NULLIF(knowledge_chunks.norm * ?, 0) AS score`,
+			vec, knowledge_utils.Norm(vec),
+		)
+	}
+	var chunks []RetrievedChunk
+	// 更换过向量化模型时，仅检索维度一致的分段
+	if err := tx.Where("knowledge_chunks.knowledge_base_id = ? AND knowledge_chunks.dimensions = ?", kb.ID, len(vec)).
+		Order("score DESC NULLS LAST").
+		Limit(topK).
+		Scan(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return slice.Filter(chunks, func(_ int, chunk RetrievedChunk) bool { return chunk.Score >= kb.MinScore }), nil
+}
+
+// RetrieveForQuestion 检索知识库并组装为带编号引用的提示词，无可访问的知识库或没有结果时返回 nil
+//
+// fromPreset 表示知识库由预设绑定，预设的配置已由管理员授权，不再校验用户的访问权限
+func (s *KnowledgeService) RetrieveForQuestion(ctx context.Context, userId uint64, kbId uint64, fromPreset bool, question string) (*KnowledgeContext, error) {
+	kb, err := s.GetKnowledgeBase(kbId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !fromPreset && !s.CanAccess(userId, kb) {
+		return nil, nil
+	}
+	chunks, err := s.Retrieve(ctx, kb, userId, question)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	result := &KnowledgeContext{
+		KnowledgeBaseID: kb.ID,
+		Name:            kb.Name,
+		Sources:         make([]KnowledgeSource, len(chunks)),
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "以下是从知识库「%s」中检索到的资料，方括号中的数字为引用编号：\n\n", kb.Name)
+	for i, chunk := range chunks {
+		result.Sources[i] = KnowledgeSource{Index: i + 1, RetrievedChunk: chunk}
+		fmt.Fprintf(&sb, "[%d] 来源：%s\n%s\n\n", i+1, chunk.DocumentName, chunk.Content)
+	}
+	sb.WriteString("请参考以上资料回答我的问题。使用某条资料的信息时，在相应语句末尾标注其编号，如 [1]；与问题无关的资料请忽略。")
+	result.Prompt = sb.String()
+	return result, nil
+}
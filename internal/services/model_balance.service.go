@@ -0,0 +1,130 @@
+package services
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	redisstore "github.com/fcraft/open-chat/internal/storage/redis"
+)
+
+// BalanceCandidate 参与负载均衡的候选模型
+type BalanceCandidate struct {
+	Model    schema.Model
+	Weight   int                    // 权重，为 0 时仅作为备用模型
+	Priority int                    // 优先级，数值越小越优先
+	Stats    *redisstore.ModelStats // 实时调用统计，无数据时为 nil
+}
+
+// BalanceStrategy 模型集合的负载均衡策略
+type BalanceStrategy interface {
+	// Order 对候选模型排序，首个为本次选中的模型，其余依次作为故障转移的备用模型
+	Order(collection *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate
+}
+
+// BalanceStrategyFunc 函数形式的负载均衡策略
+type BalanceStrategyFunc func(collection *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate
+
+func (f BalanceStrategyFunc) Order(collection *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	return f(collection, candidates)
+}
+
+var balanceStrategies sync.Map // 策略名称 -> BalanceStrategy
+
+func init() {
+	RegisterBalanceStrategy(schema.BalanceStrategyWeightedRandom, BalanceStrategyFunc(orderByWeightedRandom))
+	RegisterBalanceStrategy(schema.BalanceStrategyRoundRobin, BalanceStrategyFunc(orderByRoundRobin))
+	RegisterBalanceStrategy(schema.BalanceStrategyLeastLatency, BalanceStrategyFunc(orderByLeastLatency))
+	RegisterBalanceStrategy(schema.BalanceStrategyLowestCost, BalanceStrategyFunc(orderByLowestCost))
+	RegisterBalanceStrategy(schema.BalanceStrategyPriority, BalanceStrategyFunc(orderByPriority))
+}
+
+// RegisterBalanceStrategy 注册负载均衡策略，同名覆盖
+func RegisterBalanceStrategy(name string, strategy BalanceStrategy) {
+	balanceStrategies.Store(name, strategy)
+}
+
+// GetBalanceStrategy 获取负载均衡策略，未知策略返回 false
+func GetBalanceStrategy(name string) (BalanceStrategy, bool) {
+	if name == "" {
+		name = schema.BalanceStrategyWeightedRandom
+	}
+	strategy, ok := balanceStrategies.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return strategy.(BalanceStrategy), true
+}
+
+// orderByWeightedRandom 按权重不放回地随机抽样，权重为 0 的模型排在最后
+func orderByWeightedRandom(_ *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	pool := slice.ShuffleCopy(candidates)
+	result := make([]BalanceCandidate, 0, len(pool))
+	for len(pool) > 0 {
+		total := 0
+		for _, c := range pool {
+			total += max(c.Weight, 0)
+		}
+		if total == 0 {
+			// 剩余的均为备用模型
+			return append(result, pool...)
+		}
+		r := rand.IntN(total)
+		for i, c := range pool {
+			r -= max(c.Weight, 0)
+			if r < 0 {
+				result = append(result, c)
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// orderByRoundRobin 按模型 ID 固定顺序轮询，计数保存在 Redis 中以便多实例共享
+func orderByRoundRobin(collection *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	ordered := slices.Clone(candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Model.ID < ordered[j].Model.ID })
+	counter, err := GetModelCollectionService().BaseService.RedisStore.NextCollectionRoundRobin(collection.ID)
+	if err != nil {
+		return orderByWeightedRandom(collection, candidates)
+	}
+	offset := int(counter % int64(len(ordered)))
+	return slices.Concat(ordered[offset:], ordered[:offset])
+}
+
+// orderByLeastLatency 按延迟及错误率综合排序，无统计数据的模型优先，以便尽快获得数据
+func orderByLeastLatency(_ *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	score := func(c BalanceCandidate) float64 {
+		if c.Stats == nil {
+			return 0
+		}
+		// 错误率越高，等效延迟越大
+		return c.Stats.LatencyMs / math.Max(1-c.Stats.ErrorRate, 0.05)
+	}
+	ordered := slice.ShuffleCopy(candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return score(ordered[i]) < score(ordered[j]) })
+	return ordered
+}
+
+// orderByLowestCost 按输入及输出价格之和排序，价格相同的随机排列
+func orderByLowestCost(_ *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	cost := func(c BalanceCandidate) float64 {
+		return c.Model.Config.InputPrice + c.Model.Config.OutputPrice
+	}
+	ordered := slice.ShuffleCopy(candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return cost(ordered[i]) < cost(ordered[j]) })
+	return ordered
+}
+
+// orderByPriority 按优先级排序，同一优先级内按权重随机
+func orderByPriority(collection *schema.ModelCollection, candidates []BalanceCandidate) []BalanceCandidate {
+	ordered := orderByWeightedRandom(collection, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority < ordered[j].Priority })
+	return ordered
+}
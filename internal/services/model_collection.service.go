@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/duke-git/lancet/v2/slice"
 	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
 	"sync"
 	"time"
 
@@ -26,10 +27,9 @@ func InitModelCollectionService(base *BaseService) *ModelCollectionService {
 			modelCollectionServiceInstance = &ModelCollectionService{
 				BaseService: base,
 			}
+			registerModelHealthConfig()
 			err := GetScheduleService().RegisterSchedule(
-				"detect_model_connection", "检测模型连接", 10*time.Minute, func() error {
-					return nil
-				},
+				"detect_model_connection", "检测模型连接", 10*time.Minute, modelCollectionServiceInstance.DetectModelConnection,
 			)
 			if err != nil {
 				return
@@ -73,6 +73,7 @@ func (s *ModelCollectionService) LoadCollectionById(id uint64) (*schema.ModelCol
 		Preload("Models").
 		Preload("Models.Provider").
 		Preload("Models.Provider.APIKeys").
+		Preload("ModelSettings").
 		Where("id = ?", id).
 		First(&collection).Error; err != nil {
 		return nil, err
@@ -94,6 +95,7 @@ func (s *ModelCollectionService) LoadCollectionByName(name string) (*schema.Mode
 		Preload("Models").
 		Preload("Models.Provider").
 		Preload("Models.Provider.APIKeys").
+		Preload("ModelSettings").
 		Where("name = ?", name).
 		First(&collection).Error; err != nil {
 		return nil, err
@@ -106,18 +108,102 @@ func (s *ModelCollectionService) LoadCollectionByName(name string) (*schema.Mode
 
 // GetRandomModelFromCollection 从集合中随机获取一个模型
 func (s *ModelCollectionService) GetRandomModelFromCollection(collectionName string) (*schema.Model, error) {
-	collection, err := s.GetCollectionByName(collectionName)
+	models, err := s.GetModelCandidatesFromCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
+	return &models[0], nil
+}
+
+// GetModelCandidatesFromCollection 按集合的负载均衡策略获取候选模型，首个为本次选中的模型，其余按顺序作为故障转移的备用模型
+//
+// 仅返回对话模型，向量化模型使用 GetEmbeddingCandidatesFromCollection 获取
+func (s *ModelCollectionService) GetModelCandidatesFromCollection(collectionName string) ([]schema.Model, error) {
+	return s.getCandidatesFromCollection(collectionName, false)
+}
 
-	if len(collection.Models) == 0 {
+// GetEmbeddingCandidatesFromCollection 按集合的负载均衡策略获取候选的向量化模型，顺序含义同 GetModelCandidatesFromCollection
+func (s *ModelCollectionService) GetEmbeddingCandidatesFromCollection(collectionName string) ([]schema.Model, error) {
+	return s.getCandidatesFromCollection(collectionName, true)
+}
+
+// getCandidatesFromCollection 按集合的负载均衡策略获取对话模型或向量化模型
+func (s *ModelCollectionService) getCandidatesFromCollection(collectionName string, embedding bool) ([]schema.Model, error) {
+	collection, err := s.GetCollectionByName(collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	// 排除停用、类型不符及连通性检测不健康的模型，全部不健康时仍尝试请求
+	models := slice.Filter(
+		collection.Models, func(_ int, m schema.Model) bool { return m.Active && m.Embedding == embedding },
+	)
+	if healthy := slice.Filter(models, func(_ int, m schema.Model) bool { return !m.Unhealthy }); len(healthy) > 0 {
+		models = healthy
+	} else if len(models) > 0 {
+		s.BaseService.Logger.Warn("all models in collection are unhealthy", "collection", collectionName)
+	}
+	if len(models) == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	model, _ := slice.Random(collection.Models)
+	// 组装负载均衡参数及实时统计
+	settings := make(map[uint64]schema.ModelCollectionModel, len(collection.ModelSettings))
+	for _, setting := range collection.ModelSettings {
+		settings[setting.ModelID] = setting
+	}
+	stats, err := s.BaseService.RedisStore.GetModelStats(
+		slice.Map(models, func(_ int, m schema.Model) uint64 { return m.ID }),
+	)
+	if err != nil {
+		s.BaseService.Logger.Warn("failed to load model stats", "error", err.Error())
+	}
+	candidates := slice.Map(
+		models, func(_ int, m schema.Model) BalanceCandidate {
+			candidate := BalanceCandidate{Model: m, Weight: 1}
+			if setting, ok := settings[m.ID]; ok {
+				candidate.Weight = setting.Weight
+				candidate.Priority = setting.Priority
+			}
+			if stat, ok := stats[m.ID]; ok {
+				candidate.Stats = &stat
+			}
+			return candidate
+		},
+	)
+
+	strategy, ok := GetBalanceStrategy(collection.Strategy)
+	if !ok {
+		s.BaseService.Logger.Warn("unknown balance strategy, fallback to weighted random", "strategy", collection.Strategy)
+		strategy, _ = GetBalanceStrategy(schema.BalanceStrategyWeightedRandom)
+	}
+	return slice.Map(
+		strategy.Order(collection, candidates), func(_ int, c BalanceCandidate) schema.Model {
+			return c.Model
+		},
+	), nil
+}
+
+// RecordAttempt 作为 CompletionOptions.OnAttempt 使用，记录模型及 API Key 的调用结果
+func (s *ModelCollectionService) RecordAttempt(target chat_utils.CompletionTarget, latency time.Duration, err error) {
+	s.RecordModelResult(target.ModelID, latency, err)
+	GetAPIKeyService().RecordResult(target.Provider.ApiKeyID, err)
+}
+
+// RecordEmbeddingAttempt 作为 EmbeddingOptions.OnAttempt 使用，记录模型及 API Key 的调用结果
+func (s *ModelCollectionService) RecordEmbeddingAttempt(target chat_utils.EmbeddingTarget, latency time.Duration, err error) {
+	s.RecordModelResult(target.ModelID, latency, err)
+	GetAPIKeyService().RecordResult(target.Provider.ApiKeyID, err)
+}
 
-	// 简单实现：返回第一个模型
-	// 实际应用中可以实现更复杂的负载均衡逻辑
-	return &model, nil
+// RecordModelResult 记录模型的调用结果，用于负载均衡，请求参数等非服务端原因的错误不计入
+func (s *ModelCollectionService) RecordModelResult(modelId uint64, latency time.Duration, err error) {
+	if modelId == 0 || (err != nil && !chat_utils.IsRetryableError(err)) {
+		return
+	}
+	if err := s.BaseService.RedisStore.RecordModelStat(modelId, latency, err == nil); err != nil {
+		s.BaseService.Logger.Warn("failed to record model stats", "model_id", modelId, "error", err.Error())
+	}
 }
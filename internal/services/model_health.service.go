@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"gorm.io/datatypes"
+)
+
+const (
+	ModelHealthFailureThreshold = "model_health_failure_threshold"
+
+	defaultModelHealthFailureThreshold = 3                  // 默认连续失败多少次后标记为不健康
+	modelHealthProbeTimeout            = 30 * time.Second   // 单次检测的超时时间
+	modelHealthProbeConcurrency        = 5                  // 同时检测的模型数
+	modelHealthRetention               = 7 * 24 * time.Hour // 检测记录保留时间
+)
+
+func registerModelHealthConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ModelHealthFailureThreshold,
+			DisplayName: "模型连续检测失败阈值",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     100,
+				"description": "mark a model unhealthy after this many consecutive failed probes",
+			},
+			Default:  datatypes.NewJSONType[any](defaultModelHealthFailureThreshold),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getModelHealthFailureThreshold 获取连续检测失败的阈值
+func getModelHealthFailureThreshold() int {
+	config, err := GetSystemConfigService().GetConfig(ModelHealthFailureThreshold)
+	if err != nil {
+		return defaultModelHealthFailureThreshold
+	}
+	var threshold int
+	if err := json.Unmarshal(config.Value, &threshold); err != nil || threshold <= 0 {
+		return defaultModelHealthFailureThreshold
+	}
+	return threshold
+}
+
+// DetectModelConnection 检测所有启用的模型的连通性，记录检测结果，并根据连续失败次数更新模型的健康状态
+func (s *ModelCollectionService) DetectModelConnection() error {
+	var models []schema.Model
+	if err := s.BaseService.Gorm.
+		Preload("Provider").
+		Preload("Provider.APIKeys").
+		Where("active = ?", true).
+		Find(&models).Error; err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, modelHealthProbeConcurrency)
+	for _, model := range models {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(model schema.Model) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			record := s.probeModel(model)
+			if err := s.BaseService.Gorm.Create(&record).Error; err != nil {
+				s.BaseService.Logger.Error("failed to save model health check", "model_id", model.ID, "error", err.Error())
+				return
+			}
+			s.updateModelHealth(model, record)
+		}(model)
+	}
+	wg.Wait()
+
+	// 清理过期的检测记录
+	return s.BaseService.Gorm.
+		Where("created_at < ?", time.Now().Add(-modelHealthRetention)).
+		Delete(&schema.ModelHealthCheck{}).Error
+}
+
+// probeModel 向模型发送一个极短的补全请求，向量化模型则向量化一段极短的文本
+func (s *ModelCollectionService) probeModel(model schema.Model) schema.ModelHealthCheck {
+	record := schema.ModelHealthCheck{ModelID: model.ID}
+	if model.Provider == nil {
+		record.Error = "provider not found"
+		return record
+	}
+	apiKey, err := GetAPIKeyService().PickAPIKey(model.Provider.APIKeys)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), modelHealthProbeTimeout)
+	defer cancel()
+	if model.Embedding {
+		start := time.Now()
+		_, err = chat_utils.Embeddings(
+			ctx, chat_utils.EmbeddingOptions{
+				EmbeddingTarget: chat_utils.GetEmbeddingTarget(model, apiKey),
+				Input:           []string{"ping"},
+				OnAttempt:       s.RecordEmbeddingAttempt,
+			},
+		)
+		record.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			record.Error = err.Error()
+			return record
+		}
+		record.Success = true
+		return record
+	}
+	opts := chat_utils.GetCommonCompletionOptions(
+		model, apiKey, chat_utils.CompletionOptions{
+			Messages: []chat_utils.Message{chat_utils.UserMessage("ping")},
+			CompletionModelConfig: chat_utils.CompletionModelConfig{
+				MaxTokens: 16,
+			},
+		},
+	)
+	opts.OnAttempt = s.RecordAttempt
+	start := time.Now()
+	_, err = chat_utils.Completion(ctx, opts)
+	record.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	record.Success = true
+	return record
+}
+
+// updateModelHealth 成功时恢复健康，连续失败达到阈值时标记为不健康，状态变化时刷新相关集合的缓存
+func (s *ModelCollectionService) updateModelHealth(model schema.Model, record schema.ModelHealthCheck) {
+	unhealthy := false
+	if !record.Success {
+		if model.Unhealthy {
+			return
+		}
+		threshold := getModelHealthFailureThreshold()
+		var recent []schema.ModelHealthCheck
+		if err := s.BaseService.Gorm.
+			Where("model_id = ?", model.ID).
+			Order("id DESC").
+			Limit(threshold).
+			Find(&recent).Error; err != nil {
+			return
+		}
+		unhealthy = len(recent) >= threshold && !slice.Some(
+			recent, func(_ int, r schema.ModelHealthCheck) bool { return r.Success },
+		)
+	}
+	if unhealthy == model.Unhealthy {
+		return
+	}
+
+	if err := s.BaseService.Gorm.Model(&schema.Model{}).Where("id = ?", model.ID).Update("unhealthy", unhealthy).Error; err != nil {
+		s.BaseService.Logger.Error("failed to update model health", "model_id", model.ID, "error", err.Error())
+		return
+	}
+	s.BaseService.Logger.Warn("model health changed", "model_id", model.ID, "model", model.Name, "unhealthy", unhealthy)
+	s.refreshCollectionsOfModel(model.ID)
+}
+
+// refreshCollectionsOfModel 刷新包含指定模型的集合的缓存
+func (s *ModelCollectionService) refreshCollectionsOfModel(modelId uint64) {
+	var collectionIds []uint64
+	if err := s.BaseService.Gorm.Model(&schema.ModelCollectionModel{}).
+		Where("model_id = ?", modelId).
+		Pluck("model_collection_id", &collectionIds).Error; err != nil {
+		return
+	}
+	for _, id := range collectionIds {
+		if _, err := s.LoadCollectionById(id); err != nil {
+			s.BaseService.Logger.Error("failed to refresh collection cache", "collection_id", id, "error", err.Error())
+		}
+	}
+}
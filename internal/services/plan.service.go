@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	planServiceInstance *PlanService
+	planServiceOnce     sync.Once
+)
+
+// PlanService 订阅套餐
+//
+// 用户的套餐依次取直接分配且未过期的套餐、所属角色中优先级最高的套餐、默认套餐；均不存在时不限制模型集合且没有月度额度。
+// 套餐额度记在 schema.UserUsage 的 PlanToken 中，扣费时优先扣减，每月初由定时任务重置，不累积到下月
+type PlanService struct {
+	*BaseService
+}
+
+const planAllowanceResetInterval = time.Hour // 检查月度额度重置的间隔，重置本身每月每用户只执行一次
+
+// defaultPlans 首次启动时创建的套餐，已存在的同名套餐不会被覆盖
+var defaultPlans = []schema.Plan{
+	{Name: "free", DisplayName: "免费版", MonthlyTokens: 100000, Priority: 0, IsDefault: true},
+	{Name: "pro", DisplayName: "专业版", MonthlyTokens: 2000000, Priority: 10},
+	{Name: "team", DisplayName: "团队版", MonthlyTokens: 10000000, Priority: 20},
+}
+
+func InitPlanService(base *BaseService) *PlanService {
+	planServiceOnce.Do(
+		func() {
+			planServiceInstance = &PlanService{
+				BaseService: base,
+			}
+			for _, plan := range defaultPlans {
+				plan.ModelCollections = datatypes.NewJSONType([]string{})
+				if err := base.Gorm.Where("name = ?", plan.Name).Attrs(plan).FirstOrCreate(&schema.Plan{}).Error; err != nil {
+					base.Logger.Warn("failed to create default plan", "plan", plan.Name, "error", err.Error())
+				}
+			}
+			err := GetScheduleService().RegisterSchedule(
+				"reset_plan_allowance", "重置套餐月度额度", planAllowanceResetInterval, planServiceInstance.ResetMonthlyAllowances,
+			)
+			if err != nil {
+				return
+			}
+		},
+	)
+	return planServiceInstance
+}
+
+func GetPlanService() *PlanService {
+	return planServiceInstance
+}
+
+// currentPeriodStart 当前额度周期的开始时间，即本月 1 日零点
+func currentPeriodStart() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+// GetUserPlan 获取用户当前生效的套餐，未配置任何套餐时返回 nil
+func (s *PlanService) GetUserPlan(userId uint64) (*schema.Plan, error) {
+	// 1. 直接分配的套餐
+	var userPlan schema.UserPlan
+	err := s.Gorm.Preload("Plan").
+		Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", userId, time.Now()).
+		First(&userPlan).Error
+	if err == nil && userPlan.Plan != nil {
+		return userPlan.Plan, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 2. 角色套餐
+	roles, err := s.Helper.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) > 0 {
+		var rolePlans []schema.RolePlan
+		if err := s.Gorm.Preload("Plan").
+			Where("role_id IN ?", slice.Map(roles, func(_ int, role schema.Role) uint64 { return role.ID })).
+			Find(&rolePlans).Error; err != nil {
+			return nil, err
+		}
+		var plan *schema.Plan
+		for _, rolePlan := range rolePlans {
+			if rolePlan.Plan != nil && (plan == nil || rolePlan.Plan.Priority > plan.Priority) {
+				plan = rolePlan.Plan
+			}
+		}
+		if plan != nil {
+			return plan, nil
+		}
+	}
+
+	// 3. 默认套餐
+	var plan schema.Plan
+	if err := s.Gorm.Where("is_default = ?", true).Order("priority DESC").First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// CollectionFilter 返回判断用户能否使用模型集合的函数，超级管理员及未配置套餐时不限制
+func (s *PlanService) CollectionFilter(userId uint64) (func(name string) bool, error) {
+	roles, err := s.Helper.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+	if slice.Some(roles, func(_ int, role schema.Role) bool { return role.Name == "SUPER_ADMIN" }) {
+		return func(string) bool { return true }, nil
+	}
+	plan, err := s.GetUserPlan(userId)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return func(string) bool { return true }, nil
+	}
+	return plan.AllowsCollection, nil
+}
+
+// CanUseCollection 用户的套餐是否可使用指定的模型集合
+func (s *PlanService) CanUseCollection(userId uint64, name string) (bool, error) {
+	filter, err := s.CollectionFilter(userId)
+	if err != nil {
+		return false, err
+	}
+	return filter(name), nil
+}
+
+// ResetAllowance 本月尚未重置时，将用户的套餐额度重置为套餐的月度额度，可重复调用
+func (s *PlanService) ResetAllowance(userId uint64) error {
+	return s.applyAllowance(userId, false)
+}
+
+// applyAllowance 按用户当前的套餐设置套餐额度，force 为 false 时每月只生效一次
+func (s *PlanService) applyAllowance(userId uint64, force bool) error {
+	plan, err := s.GetUserPlan(userId)
+	if err != nil {
+		return err
+	}
+	var allowance int64
+	if plan != nil {
+		allowance = plan.MonthlyTokens
+	}
+	tx := s.Gorm.Model(&schema.UserUsage{}).Where("user_id = ?", userId)
+	if !force {
+		// 条件更新，多实例同时执行时只重置一次
+		tx = tx.Where("plan_reset_at IS NULL OR plan_reset_at < ?", currentPeriodStart())
+	}
+	return tx.UpdateColumns(
+		map[string]interface{}{
+			"plan_token":    allowance,
+			"plan_reset_at": time.Now(),
+		},
+	).Error
+}
+
+// ResetMonthlyAllowances 定时任务：重置本月尚未重置的用户的套餐额度
+func (s *PlanService) ResetMonthlyAllowances() error {
+	var usages []schema.UserUsage
+	var reset int
+	result := s.Gorm.Select("id", "user_id").
+		Where("plan_reset_at IS NULL OR plan_reset_at < ?", currentPeriodStart()).
+		FindInBatches(
+			&usages, 500, func(tx *gorm.DB, batch int) error {
+				for _, usage := range usages {
+					if err := s.ResetAllowance(usage.UserID); err != nil {
+						s.Logger.Warn("failed to reset plan allowance", "user_id", usage.UserID, "error", err.Error())
+						continue
+					}
+					reset++
+				}
+				return nil
+			},
+		)
+	if result.Error != nil {
+		return result.Error
+	}
+	if reset > 0 {
+		s.Logger.Info("plan allowances reset", "users", reset)
+	}
+	return nil
+}
+
+// AssignUserPlan 为用户分配套餐，并立即按新套餐设置本月额度
+func (s *PlanService) AssignUserPlan(userId uint64, planId uint64, expiresAt *time.Time, assignedBy uint64) error {
+	if err := s.Gorm.First(&schema.Plan{}, planId).Error; err != nil {
+		return err
+	}
+	userPlan := schema.UserPlan{
+		UserID:     userId,
+		PlanID:     planId,
+		ExpiresAt:  expiresAt,
+		AssignedBy: assignedBy,
+	}
+	if err := s.Gorm.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"plan_id", "expires_at", "assigned_by", "updated_at"}),
+		},
+	).Create(&userPlan).Error; err != nil {
+		return err
+	}
+	if _, err := s.GormStore.GetUserUsage(userId); err != nil {
+		return err
+	}
+	return s.applyAllowance(userId, true)
+}
+
+// UnassignUserPlan 取消用户直接分配的套餐，本月额度不变，下月起按角色套餐或默认套餐重置
+func (s *PlanService) UnassignUserPlan(userId uint64) error {
+	return s.Gorm.Where("user_id = ?", userId).Delete(&schema.UserPlan{}).Error
+}
+
+// AssignRolePlan 为角色分配套餐，角色下的用户下月起按新套餐重置额度
+func (s *PlanService) AssignRolePlan(roleId uint64, planId uint64) error {
+	if err := s.Gorm.First(&schema.Plan{}, planId).Error; err != nil {
+		return err
+	}
+	if err := s.Gorm.First(&schema.Role{}, roleId).Error; err != nil {
+		return err
+	}
+	return s.Gorm.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"plan_id", "updated_at"}),
+		},
+	).Create(&schema.RolePlan{RoleID: roleId, PlanID: planId}).Error
+}
+
+// UnassignRolePlan 取消角色的套餐
+func (s *PlanService) UnassignRolePlan(roleId uint64) error {
+	return s.Gorm.Where("role_id = ?", roleId).Delete(&schema.RolePlan{}).Error
+}
@@ -160,10 +160,15 @@ func BuiltinPresetCompletion(presetName string, params map[string]string) (compl
 	preset := presetService.GetBuiltinPreset(presetName)
 
 	// 查询配置，获取默认的AI模型提供商 TODO：目前临时使用 deepseek-v3，后续更新可配置
-	modelInfo, err := GetModelCollectionService().GetRandomModelFromCollection("gpt-any")
-	if err != nil || modelInfo == nil || modelInfo.Provider == nil {
+	candidates, err := GetModelCollectionService().GetModelCandidatesFromCollection("gpt-any")
+	if err != nil || len(candidates) == 0 || candidates[0].Provider == nil {
 		return "", 0, errors.New("default AI provider not found")
 	}
+	modelInfo := &candidates[0]
+	apiKey, err := GetAPIKeyService().PickAPIKey(modelInfo.Provider.APIKeys)
+	if err != nil {
+		return "", 0, err
+	}
 
 	// 记录调用
 	presetRecord := &schema.PresetCompletionRecord{
@@ -176,18 +181,19 @@ func BuiltinPresetCompletion(presetName string, params map[string]string) (compl
 	}
 
 	// 调用AI接口进行补全
-	resp, err := chat_utils.Completion(
-		context.Background(), chat_utils.GetCommonCompletionOptions(
-			*modelInfo, chat_utils.CompletionOptions{
-				CompletionModelConfig: chat_utils.CompletionModelConfig{
-					//MaxTokens:   1000, // 输出长度限制 TODO：跟随更新可配置后可自定义
-					//Temperature: 1.6,  // 较高的温度，提高灵活性 TODO：跟随更新可配置后可自定义
-				},
-				SystemPrompt: preset.PromptSession.SystemPrompt,
-				Messages:     chat_utils.ConvertSchemaToMessages(preset.PromptSession.Messages, params),
+	completionOptions := chat_utils.GetCommonCompletionOptions(
+		*modelInfo, apiKey, chat_utils.CompletionOptions{
+			CompletionModelConfig: chat_utils.CompletionModelConfig{
+				//MaxTokens:   1000, // 输出长度限制 TODO：跟随更新可配置后可自定义
+				//Temperature: 1.6,  // 较高的温度，提高灵活性 TODO：跟随更新可配置后可自定义
 			},
-		),
+			SystemPrompt: preset.PromptSession.SystemPrompt,
+			Messages:     chat_utils.ConvertSchemaToMessages(preset.PromptSession.Messages, params),
+		},
 	)
+	completionOptions.Fallbacks = GetChatService().GetFallbackTargets(candidates[1:], nil)
+	completionOptions.OnAttempt = GetModelCollectionService().RecordAttempt
+	resp, err := chat_utils.Completion(context.Background(), completionOptions)
 
 	// 更新记录
 	defer func() {
@@ -215,6 +221,23 @@ func BuiltinPresetCompletion(presetName string, params map[string]string) (compl
 	if err != nil {
 		return "", presetRecord.ID, fmt.Errorf("failed to complete: %w", err)
 	}
+	// 内置预设由系统调用，用量记录不关联用户
+	if _, err := GetUsageService().RecordUsage(
+		UsageRecordParams{
+			Source:         schema.UsageRecordSourcePreset,
+			PresetID:       preset.ID,
+			PresetRecordID: presetRecord.ID,
+			ModelID:        resp.ModelID,
+			APIKeyID:       resp.APIKeyID,
+			Usage: chat_utils.DoneResponseUsage{
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+				ReasoningTokens:  resp.Usage.ReasoningTokens,
+			},
+		},
+	); err != nil {
+		// do nothing
+	}
 	if resp.Content == "" {
 		return "", presetRecord.ID, errors.New("no content")
 	}
@@ -140,15 +140,16 @@ func ParseProblemFromCompletion(completion string) (*schema.Problem, error) {
 	return problem, nil
 }
 
-func GetQuestionTools() []chat_utils.CompletionTool {
-	return []chat_utils.CompletionTool{
-		MakeExamTool(),
-		MakeQuestionTool(schema.SingleChoice),
-		MakeQuestionTool(schema.MultipleChoice),
-		MakeQuestionTool(schema.TrueFalse),
-		MakeQuestionTool(schema.ShortAnswer),
-		MakeQuestionTool(schema.FillBlank),
-		EveryDayQuestionTool(),
+func GetQuestionTools() []RegisteredTool {
+	keywords := []string{"考", "测", "验", "题"}
+	return []RegisteredTool{
+		{CompletionTool: MakeExamTool(), DisplayName: "生成试卷", Keywords: keywords},
+		{CompletionTool: MakeQuestionTool(schema.SingleChoice), DisplayName: "生成单选题", Keywords: keywords},
+		{CompletionTool: MakeQuestionTool(schema.MultipleChoice), DisplayName: "生成多选题", Keywords: keywords},
+		{CompletionTool: MakeQuestionTool(schema.TrueFalse), DisplayName: "生成判断题", Keywords: keywords},
+		{CompletionTool: MakeQuestionTool(schema.ShortAnswer), DisplayName: "生成简答题", Keywords: keywords},
+		{CompletionTool: MakeQuestionTool(schema.FillBlank), DisplayName: "生成填空题", Keywords: keywords},
+		{CompletionTool: EveryDayQuestionTool(), DisplayName: "每日一题", Keywords: keywords},
 	}
 }
 
@@ -540,6 +541,10 @@ func InitMakeQuestionService(base *BaseService) {
 					),
 				},
 			)
+			// 注册出题工具
+			if err := GetToolRegistryService().RegisterTools(GetQuestionTools()...); err != nil {
+				base.Logger.Error("failed to register question tools", "error", err.Error())
+			}
 		},
 	)
 }
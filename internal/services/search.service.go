@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"github.com/fcraft/open-chat/internal/utils/search_utils"
+	"gorm.io/datatypes"
+)
+
+var (
+	searchServiceInstance *SearchService
+	searchServiceOnce     sync.Once
+)
+
+// SearchService 联网搜索
+//
+// 并发请求配置的全部搜索服务，合并排序后按 token 预算截断，组装为带编号引用的提示词。
+// 未配置 ConfigSearchProviders 时沿用 ChatOnlineSearchServiceBaseURL 中的 SearXNG 服务
+type SearchService struct {
+	*BaseService
+}
+
+const (
+	ConfigSearchProviders   = "chat_online_search_providers"
+	ConfigSearchTokenBudget = "chat_online_search_token_budget"
+
+	defaultSearchTokenBudget = 2000             // 搜索结果在提示词中占用的默认 token 预算
+	searchResultsPerProvider = 10               // 每个搜索服务请求的结果数量
+	searchTimeout            = 15 * time.Second // 单次搜索的超时时间
+)
+
+var ErrSearchNoProvider = errors.New("no search provider configured")
+
+// SearchContext 一次联网搜索的结果
+type SearchContext struct {
+	Query     string                  `json:"query"`
+	Prompt    string                  `json:"-"` // 带编号引用的提示词
+	Citations []search_utils.Citation `json:"citations"`
+}
+
+func InitSearchService(base *BaseService) *SearchService {
+	searchServiceOnce.Do(
+		func() {
+			searchServiceInstance = &SearchService{
+				BaseService: base,
+			}
+			registerSearchConfig()
+		},
+	)
+	return searchServiceInstance
+}
+
+func GetSearchService() *SearchService {
+	return searchServiceInstance
+}
+
+func registerSearchConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigSearchProviders,
+			DisplayName: "联网搜索服务",
+			Schema: map[string]interface{}{
+				"type":        "array",
+				"description": "search providers queried in parallel; results are merged by reciprocal rank fusion",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{
+							"type": "string",
+							"enum": []string{
+								search_utils.ProviderTypeSearXNG,
+								search_utils.ProviderTypeBing,
+								search_utils.ProviderTypeBrave,
+								search_utils.ProviderTypeFixture,
+							},
+						},
+						"base_url": map[string]string{
+							"type":        "string",
+							"description": "service base url, or the result file path for fixture",
+						},
+						"api_key": map[string]string{
+							"type":        "string",
+							"description": "api key, not required by searxng",
+						},
+						"weight": map[string]string{
+							"type":        "number",
+							"description": "ranking weight, defaults to 1",
+						},
+					},
+					"required": []string{"type"},
+				},
+			},
+			Default:  datatypes.NewJSONType[any]([]search_utils.ProviderConfig{}),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+	err = GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigSearchTokenBudget,
+			DisplayName: "联网搜索结果 token 预算",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     200,
+				"description": "max tokens of search results added to the prompt",
+			},
+			Default:  datatypes.NewJSONType[any](defaultSearchTokenBudget),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getProviderConfigs 获取搜索服务配置，未配置时使用旧版的 SearXNG 地址列表
+func (s *SearchService) getProviderConfigs() []search_utils.ProviderConfig {
+	var configs []search_utils.ProviderConfig
+	if config, err := GetSystemConfigService().GetConfig(ConfigSearchProviders); err == nil {
+		_ = json.Unmarshal(config.Value, &configs)
+	}
+	if len(configs) > 0 {
+		return configs
+	}
+	if config, err := GetSystemConfigService().GetConfig(ChatOnlineSearchServiceBaseURL); err == nil {
+		var baseUrls []string
+		if err := json.Unmarshal(config.Value, &baseUrls); err == nil {
+			for _, baseUrl := range baseUrls {
+				if baseUrl != "" {
+					configs = append(configs, search_utils.ProviderConfig{Type: search_utils.ProviderTypeSearXNG, BaseURL: baseUrl})
+				}
+			}
+		}
+	}
+	return configs
+}
+
+// TokenBudget 获取搜索结果的 token 预算，也用于在组装上下文时为搜索结果预留
+func (s *SearchService) TokenBudget() int64 {
+	config, err := GetSystemConfigService().GetConfig(ConfigSearchTokenBudget)
+	if err != nil {
+		return defaultSearchTokenBudget
+	}
+	var budget int64
+	if err := json.Unmarshal(config.Value, &budget); err != nil || budget <= 0 {
+		return defaultSearchTokenBudget
+	}
+	return budget
+}
+
+// Search 并发请求全部搜索服务，返回合并排序并按预算截断后的结果；部分服务失败时使用其余服务的结果
+func (s *SearchService) Search(ctx context.Context, query string) ([]search_utils.SearchResult, error) {
+	configs := s.getProviderConfigs()
+	if len(configs) == 0 {
+		return nil, ErrSearchNoProvider
+	}
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	lists := make([]search_utils.RankedList, len(configs))
+	errs := make([]error, len(configs))
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		provider, err := search_utils.NewProvider(cfg)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := provider.Search(ctx, search_utils.SearchRequest{Query: query, Limit: searchResultsPerProvider})
+			lists[i] = search_utils.RankedList{Results: results, Weight: cfg.Weight}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			s.Logger.Warn("search provider failed", "type", configs[i].Type, "error", err.Error())
+		}
+	}
+	if !slice.Some(lists, func(_ int, list search_utils.RankedList) bool { return len(list.Results) > 0 }) {
+		// 全部失败时返回错误，均无结果时返回空
+		return nil, errors.Join(errs...)
+	}
+	return search_utils.TruncateToBudget(search_utils.Rank(query, lists), s.TokenBudget()), nil
+}
+
+// SearchForQuestion 从提问中提取搜索关键词并联网搜索，无需搜索或没有结果时返回 nil
+func (s *SearchService) SearchForQuestion(ctx context.Context, question string) (*SearchContext, error) {
+	completion, _, err := BuiltinPresetCompletion(
+		ChatSearchKeywordGeneratePresetName, map[string]string{
+			"CONTENT": question,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	query := chat_utils.ExtractTagContent(completion, "search")
+	if query == "" {
+		return nil, nil
+	}
+	results, err := s.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	prompt, citations := search_utils.BuildCitationPrompt(query, results)
+	return &SearchContext{
+		Query:     query,
+		Prompt:    prompt,
+		Citations: citations,
+	}, nil
+}
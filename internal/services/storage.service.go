@@ -0,0 +1,518 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/storage_utils"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	storageServiceInstance *StorageService
+	storageServiceOnce     sync.Once
+)
+
+// StorageService 文件上传及储存
+//
+// 文件可直接通过接口上传，也可以先获取预签名链接由客户端上传后再确认。
+// 文件类型以内容嗅探的结果为准，大小及类型限制按文件所属模块配置
+type StorageService struct {
+	*BaseService
+}
+
+const (
+	ConfigStorageModulePolicies = "storage_module_policies"
+
+	storageUploadURLExpire   = 15 * time.Minute   // 预签名上传链接有效期
+	storageDownloadURLExpire = 1 * time.Hour      // 预签名下载链接有效期
+	storagePendingExpire     = 24 * time.Hour     // 预签名上传超过该时间未确认时视为废弃
+	storageDeletedRetention  = 7 * 24 * time.Hour // 已删除文件的对象保留时间
+	storageCleanupInterval   = 1 * time.Hour      // 清理废弃上传的间隔
+	storageCleanupTimeout    = 30 * time.Second   // 删除单个对象的超时时间
+	storageSniffBytes        = 3072               // 内容嗅探读取的字节数
+)
+
+var (
+	ErrStorageModuleNotAllowed = errors.New("module does not accept uploads")
+	ErrStorageFileTooLarge     = errors.New("file too large")
+	ErrStorageTypeNotAllowed   = errors.New("file type not allowed")
+	ErrStorageNoBucket         = errors.New("no storage bucket available")
+	ErrStorageFileNotFound     = errors.New("file not found")
+	ErrStorageUploadCompleted  = errors.New("upload already completed")
+	ErrStorageObjectMissing    = errors.New("object has not been uploaded")
+)
+
+// StorageModulePolicy 模块的上传限制
+type StorageModulePolicy struct {
+	MaxSize      int64    `json:"max_size"`      // 单个文件的最大字节数
+	AllowedTypes []string `json:"allowed_types"` // 允许的 MIME 类型，支持以 * 结尾的前缀通配（如 image/*），为空时不限制
+	BucketID     uint64   `json:"bucket_id"`     // 使用的储存桶，0 表示 ID 最小的储存桶
+}
+
+// AllowsType 是否允许该 MIME 类型
+func (p StorageModulePolicy) AllowsType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	return len(p.AllowedTypes) == 0 || slice.Some(
+		p.AllowedTypes, func(_ int, allowed string) bool {
+			allowed = strings.ToLower(strings.TrimSpace(allowed))
+			if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+				return strings.HasPrefix(mimeType, prefix)
+			}
+			return mimeType == allowed
+		},
+	)
+}
+
+// defaultStorageModulePolicies 默认的模块上传限制，未配置的模块不允许上传
+var defaultStorageModulePolicies = map[string]StorageModulePolicy{
+	"chat": {
+		MaxSize:      20 << 20,
+		AllowedTypes: []string{"image/*", "application/pdf", "text/*", "application/json"},
+	},
+	"tue": {
+		MaxSize: 100 << 20,
+		AllowedTypes: []string{
+			"image/*", "video/*", "audio/*", "application/pdf", "text/*", "application/zip",
+			"application/msword", "application/vnd.openxmlformats-officedocument.*",
+			"application/vnd.ms-excel", "application/vnd.ms-powerpoint",
+		},
+	},
+}
+
+// PresignedUpload 预签名上传的结果，客户端以 Method 请求 URL 上传文件内容后调用确认接口
+type PresignedUpload struct {
+	File      schema.File `json:"file"`
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+func InitStorageService(base *BaseService) *StorageService {
+	storageServiceOnce.Do(
+		func() {
+			storageServiceInstance = &StorageService{
+				BaseService: base,
+			}
+			registerStorageConfig()
+			err := GetScheduleService().RegisterSchedule(
+				"cleanup_orphaned_files", "清理未确认的上传及已删除的文件", storageCleanupInterval, storageServiceInstance.CleanupOrphanedFiles,
+			)
+			if err != nil {
+				return
+			}
+		},
+	)
+	return storageServiceInstance
+}
+
+func GetStorageService() *StorageService {
+	return storageServiceInstance
+}
+
+func registerStorageConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigStorageModulePolicies,
+			DisplayName: "文件上传模块限制",
+			Schema: map[string]interface{}{
+				"type":        "object",
+				"description": "upload limits keyed by module; modules not listed do not accept uploads",
+				"additionalProperties": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"max_size": map[string]interface{}{"type": "integer", "minimum": 1},
+						"allowed_types": map[string]interface{}{
+							"type":  "array",
+							"items": map[string]string{"type": "string"},
+						},
+						"bucket_id": map[string]interface{}{"type": "integer", "minimum": 0},
+					},
+					"required": []string{"max_size"},
+				},
+			},
+			Default:  datatypes.NewJSONType[any](defaultStorageModulePolicies),
+			IsPublic: false, // 含储存桶 ID，客户端通过 GetUploadPolicies 获取上传限制
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getModulePolicies 读取全部模块的上传限制，未配置时使用默认值
+func (s *StorageService) getModulePolicies() map[string]StorageModulePolicy {
+	policies := defaultStorageModulePolicies
+	if config, err := GetSystemConfigService().GetConfig(ConfigStorageModulePolicies); err == nil {
+		var configured map[string]StorageModulePolicy
+		if err := json.Unmarshal(config.Value, &configured); err == nil {
+			policies = configured
+		}
+	}
+	return policies
+}
+
+// UploadPolicy 提供给客户端的模块上传限制
+type UploadPolicy struct {
+	MaxSize      int64    `json:"max_size"`      // 单个文件的最大字节数
+	AllowedTypes []string `json:"allowed_types"` // 允许的 MIME 类型，为空时不限制
+}
+
+// GetUploadPolicies 获取允许上传的模块及其大小、类型限制，不包含储存桶等内部配置
+//
+//	Returns:
+//		map[string]UploadPolicy 模块 -> 上传限制
+func (s *StorageService) GetUploadPolicies() map[string]UploadPolicy {
+	result := map[string]UploadPolicy{}
+	for module, policy := range s.getModulePolicies() {
+		if policy.MaxSize <= 0 {
+			continue
+		}
+		result[module] = UploadPolicy{
+			MaxSize:      policy.MaxSize,
+			AllowedTypes: policy.AllowedTypes,
+		}
+	}
+	return result
+}
+
+// GetModulePolicy 获取模块的上传限制
+func (s *StorageService) GetModulePolicy(module string) (*StorageModulePolicy, error) {
+	policy, ok := s.getModulePolicies()[module]
+	if !ok || policy.MaxSize <= 0 {
+		return nil, ErrStorageModuleNotAllowed
+	}
+	return &policy, nil
+}
+
+// getBucket 获取模块使用的储存桶
+func (s *StorageService) getBucket(policy *StorageModulePolicy) (*schema.Bucket, error) {
+	var bucket schema.Bucket
+	tx := s.Gorm.Order("id")
+	if policy.BucketID > 0 {
+		tx = tx.Where("id = ?", policy.BucketID)
+	}
+	if err := tx.First(&bucket).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStorageNoBucket
+		}
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+var objectExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// newObjectKey 生成对象路径，形如 chat/2024/01/02/<uuid>.png，原始文件名仅保留扩展名
+func newObjectKey(module string, name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if !objectExtPattern.MatchString(ext) {
+		ext = ""
+	}
+	return fmt.Sprintf("%s/%s/%s%s", module, time.Now().Format("2006/01/02"), uuid.NewString(), ext)
+}
+
+// sniff 读取内容开头用于嗅探 MIME 类型，返回类型及已读取的内容
+func sniff(r io.Reader) (string, []byte, error) {
+	head := make([]byte, storageSniffBytes)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", nil, err
+	}
+	head = head[:n]
+	mimeType, _, _ := strings.Cut(mimetype.Detect(head).String(), ";")
+	return mimeType, head, nil
+}
+
+// Upload 校验并上传文件，文件类型以内容嗅探的结果为准
+func (s *StorageService) Upload(ctx context.Context, userId uint64, module string, name string, size int64, r io.Reader) (*schema.File, error) {
+	policy, err := s.GetModulePolicy(module)
+	if err != nil {
+		return nil, err
+	}
+	if size > policy.MaxSize {
+		return nil, ErrStorageFileTooLarge
+	}
+	mimeType, head, err := sniff(r)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.AllowsType(mimeType) {
+		return nil, ErrStorageTypeNotAllowed
+	}
+	bucket, err := s.getBucket(policy)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := storage_utils.GetBackend(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	file := schema.File{
+		BucketID: bucket.ID,
+		Name:     name,
+		Size:     size,
+		Type:     mimeType,
+		Module:   module,
+		S3Path:   newObjectKey(module, name),
+		OwnerID:  userId,
+		Status:   schema.FileStatusReady,
+	}
+	if err := backend.Put(ctx, bucket, file.S3Path, io.MultiReader(bytes.NewReader(head), r), size, mimeType); err != nil {
+		return nil, err
+	}
+	if err := s.Gorm.Create(&file).Error; err != nil {
+		_ = backend.Delete(ctx, bucket, file.S3Path)
+		return nil, err
+	}
+	file.Bucket = bucket
+	return &file, nil
+}
+
+// CreateUpload 创建待上传的文件并签发预签名上传链接，声明的大小及类型需符合模块限制，上传后需调用 CompleteUpload 确认
+func (s *StorageService) CreateUpload(userId uint64, module string, name string, size int64, mimeType string) (*PresignedUpload, error) {
+	policy, err := s.GetModulePolicy(module)
+	if err != nil {
+		return nil, err
+	}
+	if size > policy.MaxSize {
+		return nil, ErrStorageFileTooLarge
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	if !policy.AllowsType(mimeType) {
+		return nil, ErrStorageTypeNotAllowed
+	}
+	bucket, err := s.getBucket(policy)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := storage_utils.GetBackend(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	file := schema.File{
+		BucketID: bucket.ID,
+		Name:     name,
+		Size:     size,
+		Type:     mimeType,
+		Module:   module,
+		S3Path:   newObjectKey(module, name),
+		OwnerID:  userId,
+		Status:   schema.FileStatusPending,
+	}
+	presigned, err := backend.PresignURL(bucket, http.MethodPut, file.S3Path, storageUploadURLExpire)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Gorm.Create(&file).Error; err != nil {
+		return nil, err
+	}
+	return &PresignedUpload{
+		File:      file,
+		Method:    http.MethodPut,
+		URL:       presigned,
+		ExpiresAt: time.Now().Add(storageUploadURLExpire),
+	}, nil
+}
+
+// CompleteUpload 确认预签名上传，以实际上传的对象校验大小及类型，不符合限制时删除对象及文件
+func (s *StorageService) CompleteUpload(ctx context.Context, file *schema.File) error {
+	if file.Status != schema.FileStatusPending {
+		return ErrStorageUploadCompleted
+	}
+	backend, err := storage_utils.GetBackend(file.Bucket)
+	if err != nil {
+		return err
+	}
+	size, err := backend.Stat(ctx, file.Bucket, file.S3Path)
+	if err != nil {
+		if errors.Is(err, storage_utils.ErrObjectNotFound) {
+			return ErrStorageObjectMissing
+		}
+		return err
+	}
+
+	reject := func(reason error) error {
+		if err := backend.Delete(ctx, file.Bucket, file.S3Path); err != nil {
+			s.Logger.Warn("failed to delete rejected upload", "file_id", file.ID, "error", err.Error())
+		}
+		s.Gorm.Unscoped().Delete(file)
+		return reason
+	}
+	policy, err := s.GetModulePolicy(file.Module)
+	if err != nil {
+		return reject(err)
+	}
+	if size > policy.MaxSize {
+		return reject(ErrStorageFileTooLarge)
+	}
+	reader, err := backend.Get(ctx, file.Bucket, file.S3Path)
+	if err != nil {
+		return err
+	}
+	mimeType, _, err := sniff(reader)
+	_ = reader.Close()
+	if err != nil {
+		return err
+	}
+	if !policy.AllowsType(mimeType) {
+		return reject(ErrStorageTypeNotAllowed)
+	}
+
+	// 仅在仍为待上传状态时确认，避免并发确认
+	result := s.Gorm.Model(file).
+		Where("status = ?", schema.FileStatusPending).
+		Updates(map[string]interface{}{"size": size, "type": mimeType, "status": schema.FileStatusReady})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStorageUploadCompleted
+	}
+	file.Size, file.Type, file.Status = size, mimeType, schema.FileStatusReady
+	return nil
+}
+
+// ReceiveLocalUpload 接收本地储存签名链接上传的内容，仅允许写入待上传的文件，超出模块大小限制时丢弃
+func (s *StorageService) ReceiveLocalUpload(ctx context.Context, bucket *schema.Bucket, key string, r io.Reader) error {
+	var file schema.File
+	if err := s.Gorm.Where("bucket_id = ? AND s3_path = ?", bucket.ID, key).First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrStorageFileNotFound
+		}
+		return err
+	}
+	if file.Status != schema.FileStatusPending {
+		return ErrStorageUploadCompleted
+	}
+	policy, err := s.GetModulePolicy(file.Module)
+	if err != nil {
+		return err
+	}
+	backend, err := storage_utils.GetBackend(bucket)
+	if err != nil {
+		return err
+	}
+	// 多读取一个字节以判断是否超出大小限制
+	limited := &io.LimitedReader{R: r, N: policy.MaxSize + 1}
+	if err := backend.Put(ctx, bucket, key, limited, -1, file.Type); err != nil {
+		return err
+	}
+	if limited.N == 0 {
+		_ = backend.Delete(ctx, bucket, key)
+		return ErrStorageFileTooLarge
+	}
+	return nil
+}
+
+// GetOwnedFile 获取用户上传的文件（含储存桶信息），不存在或不属于该用户时返回 ErrStorageFileNotFound
+func (s *StorageService) GetOwnedFile(userId uint64, fileId uint64) (*schema.File, error) {
+	var file schema.File
+	if err := s.Gorm.Preload("Bucket").Where("id = ?", fileId).First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStorageFileNotFound
+		}
+		return nil, err
+	}
+	if file.OwnerID != userId || file.Bucket == nil {
+		return nil, ErrStorageFileNotFound
+	}
+	return &file, nil
+}
+
+// PresignDownload 生成文件的临时下载链接
+func (s *StorageService) PresignDownload(file *schema.File, expires time.Duration) (string, error) {
+	if expires <= 0 {
+		expires = storageDownloadURLExpire
+	}
+	backend, err := storage_utils.GetBackend(file.Bucket)
+	if err != nil {
+		return "", err
+	}
+	return backend.PresignURL(file.Bucket, http.MethodGet, file.S3Path, expires)
+}
+
+// ReadFile 读取文件内容，最多读取 maxSize 字节
+func (s *StorageService) ReadFile(ctx context.Context, file *schema.File, maxSize int64) ([]byte, error) {
+	backend, err := storage_utils.GetBackend(file.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := backend.Get(ctx, file.Bucket, file.S3Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("object exceeds %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// DeleteFile 删除文件，对象在保留期后由清理任务删除
+func (s *StorageService) DeleteFile(file *schema.File) error {
+	return s.Gorm.Delete(file).Error
+}
+
+// CleanupOrphanedFiles 删除超时未确认的预签名上传，及超过保留期的已删除文件的对象
+func (s *StorageService) CleanupOrphanedFiles() error {
+	var orphans []schema.File
+	return s.Gorm.Unscoped().
+		Where(
+			"(status = ? AND created_at < ?) OR deleted_at < ?",
+			schema.FileStatusPending, time.Now().Add(-storagePendingExpire), time.Now().Add(-storageDeletedRetention),
+		).
+		FindInBatches(
+			&orphans, 100, func(tx *gorm.DB, batch int) error {
+				for _, file := range orphans {
+					s.purgeFile(file)
+				}
+				return nil
+			},
+		).Error
+}
+
+// purgeFile 删除文件的对象及记录，对象删除失败时保留记录以便下次重试
+func (s *StorageService) purgeFile(file schema.File) {
+	var bucket schema.Bucket
+	if err := s.Gorm.Unscoped().Where("id = ?", file.BucketID).First(&bucket).Error; err == nil {
+		backend, err := storage_utils.GetBackend(&bucket)
+		if err != nil {
+			s.Logger.Warn("failed to purge file", "file_id", file.ID, "error", err.Error())
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), storageCleanupTimeout)
+		err = backend.Delete(ctx, &bucket, file.S3Path)
+		cancel()
+		if err != nil {
+			s.Logger.Warn("failed to delete orphaned object", "file_id", file.ID, "error", err.Error())
+			return
+		}
+	}
+	if err := s.Gorm.Unscoped().Delete(&file).Error; err != nil {
+		s.Logger.Warn("failed to delete orphaned file", "file_id", file.ID, "error", err.Error())
+	}
+}
@@ -97,23 +97,6 @@ func InitSystemConfigService(base *BaseService) *SystemConfigService {
 	); err != nil {
 		return nil
 	}
-	if err := systemConfigServiceInstance.RegisterSystemConfig(
-		RegisterConfigParams{
-			Name:        "temp_gift_card",
-			DisplayName: "礼品卡",
-			Schema: map[string]interface{}{
-				"type": "array",
-				"items": map[string]interface{}{
-					"type":        "string",
-					"description": "the gift card code",
-				},
-			},
-			Default:  datatypes.NewJSONType[any]([]string{""}),
-			IsPublic: true,
-		},
-	); err != nil {
-		return nil
-	}
 	return systemConfigServiceInstance
 }
 
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"gorm.io/datatypes"
+)
+
+var (
+	toolRegistryServiceInstance *ToolRegistryService
+	toolRegistryServiceOnce     sync.Once
+)
+
+const (
+	ConfigChatDisabledTools        = "chat_disabled_tools"
+	ConfigChatToolIntentRouter     = "chat_tool_intent_router"
+	ChatToolIntentRoutePresetName  = "chat_tool_intent_route"
+	toolIntentRouterKeyword        = "keyword"
+	toolIntentRouterLLM            = "llm"
+	toolIntentRouterDefaultSetting = toolIntentRouterKeyword
+)
+
+// RegisteredTool 注册到工具中心的工具
+type RegisteredTool struct {
+	chat_utils.CompletionTool
+	DisplayName string   // 展示名称
+	Keywords    []string // 关键词意图路由使用，提问包含任一关键词时启用
+}
+
+// Name 工具名称，即 function name
+func (t RegisteredTool) Name() string {
+	return t.Param.Function.Name
+}
+
+// ToolInfo 工具信息，用于对外展示
+type ToolInfo struct {
+	Name        string         `json:"name"`
+	DisplayName string         `json:"display_name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"` // JSON Schema 格式的参数定义
+	Enabled     bool           `json:"enabled"`    // 管理员是否启用
+}
+
+// ToolRegistryService 工具中心，管理可供模型调用的工具
+type ToolRegistryService struct {
+	BaseService *BaseService
+	tools       sync.Map // 工具名称 -> RegisteredTool
+}
+
+// InitToolRegistryService 初始化工具中心
+func InitToolRegistryService(base *BaseService) *ToolRegistryService {
+	toolRegistryServiceOnce.Do(
+		func() {
+			toolRegistryServiceInstance = &ToolRegistryService{
+				BaseService: base,
+			}
+			registerToolSystemConfig()
+			GetPresetService().RegisterBuiltinPresetsSimple(
+				ChatToolIntentRoutePresetName, "工具意图识别", 1, "", []chat_utils.Message{
+					chat_utils.UserMessage(
+						`
+你的任务是根据用户消息，从下方工具列表中选出回答该消息需要用到的工具，若无需工具则输出为空。
+工具列表（每行一个，格式为 名称: 描述）：
+{TOOLS}
+用户消息：{CONTENT}
+所需工具的名称以英文逗号分隔，输出在<tools></tools>中
+`,
+					),
+				},
+			)
+		},
+	)
+	return toolRegistryServiceInstance
+}
+
+// GetToolRegistryService 获取工具中心
+func GetToolRegistryService() *ToolRegistryService {
+	if toolRegistryServiceInstance == nil {
+		panic("ToolRegistryService not initialized")
+	}
+	return toolRegistryServiceInstance
+}
+
+func registerToolSystemConfig() {
+	if err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigChatDisabledTools,
+			DisplayName: "禁用的对话工具",
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":        "string",
+					"description": "the name of the tool",
+				},
+			},
+			Default:  datatypes.NewJSONType[any]([]string{}),
+			IsPublic: false,
+		},
+	); err != nil {
+		return
+	}
+	if err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigChatToolIntentRouter,
+			DisplayName: "工具意图识别方式",
+			Schema: map[string]interface{}{
+				"type": "string",
+				"enum": []string{toolIntentRouterKeyword, toolIntentRouterLLM},
+			},
+			Default:  datatypes.NewJSONType[any](toolIntentRouterDefaultSetting),
+			IsPublic: false,
+		},
+	); err != nil {
+		return
+	}
+}
+
+// RegisterTool 注册工具，名称不可重复
+func (s *ToolRegistryService) RegisterTool(tool RegisteredTool) error {
+	name := tool.Name()
+	if name == "" {
+		return fmt.Errorf("tool name is empty")
+	}
+	if tool.Handler == nil {
+		return fmt.Errorf("tool %s has no handler", name)
+	}
+	if _, loaded := s.tools.LoadOrStore(name, tool); loaded {
+		return fmt.Errorf("tool %s already registered", name)
+	}
+	return nil
+}
+
+// RegisterTools 批量注册工具
+func (s *ToolRegistryService) RegisterTools(tools ...RegisteredTool) error {
+	for _, tool := range tools {
+		if err := s.RegisterTool(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTools 列出全部已注册工具
+func (s *ToolRegistryService) ListTools() []ToolInfo {
+	disabled := s.disabledTools()
+	var infos []ToolInfo
+	s.tools.Range(
+		func(_, value any) bool {
+			tool := value.(RegisteredTool)
+			infos = append(
+				infos, ToolInfo{
+					Name:        tool.Name(),
+					DisplayName: tool.DisplayName,
+					Description: tool.Param.Function.Description.Value,
+					Parameters:  tool.Param.Function.Parameters,
+					Enabled:     !disabled[tool.Name()],
+				},
+			)
+			return true
+		},
+	)
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// EnabledTools 获取全部已启用的工具
+func (s *ToolRegistryService) EnabledTools() []RegisteredTool {
+	disabled := s.disabledTools()
+	var tools []RegisteredTool
+	s.tools.Range(
+		func(_, value any) bool {
+			tool := value.(RegisteredTool)
+			if !disabled[tool.Name()] {
+				tools = append(tools, tool)
+			}
+			return true
+		},
+	)
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name() < tools[j].Name() })
+	return tools
+}
+
+// ResolveTools 按名称获取工具，忽略未注册或已禁用的工具
+func (s *ToolRegistryService) ResolveTools(names []string) []chat_utils.CompletionTool {
+	disabled := s.disabledTools()
+	var tools []chat_utils.CompletionTool
+	for _, name := range slice.Unique(names) {
+		value, ok := s.tools.Load(name)
+		if !ok || disabled[name] {
+			continue
+		}
+		tools = append(tools, value.(RegisteredTool).CompletionTool)
+	}
+	return tools
+}
+
+// RouteTools 根据提问内容从已启用的工具中选出可能需要的工具
+//
+// 默认使用关键词匹配，系统配置为 llm 时使用模型进行意图识别，识别失败时回退到关键词匹配
+func (s *ToolRegistryService) RouteTools(question string) []chat_utils.CompletionTool {
+	candidates := s.EnabledTools()
+	if len(candidates) == 0 {
+		return nil
+	}
+	if s.intentRouter() == toolIntentRouterLLM {
+		if tools, err := s.routeToolsByLLM(question, candidates); err == nil {
+			return tools
+		}
+	}
+	var tools []chat_utils.CompletionTool
+	for _, tool := range candidates {
+		if slice.Some(
+			tool.Keywords, func(_ int, keyword string) bool { return strings.Contains(question, keyword) },
+		) {
+			tools = append(tools, tool.CompletionTool)
+		}
+	}
+	return tools
+}
+
+// routeToolsByLLM 使用内置预设进行意图识别
+func (s *ToolRegistryService) routeToolsByLLM(question string, candidates []RegisteredTool) ([]chat_utils.CompletionTool, error) {
+	toolLines := slice.Map(
+		candidates, func(_ int, tool RegisteredTool) string {
+			return tool.Name() + ": " + tool.Param.Function.Description.Value
+		},
+	)
+	completion, _, err := BuiltinPresetCompletion(
+		ChatToolIntentRoutePresetName, map[string]string{
+			"TOOLS":   strings.Join(toolLines, "\n"),
+			"CONTENT": question,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Split(chat_utils.ExtractTagContent(completion, "tools"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return s.ResolveTools(names), nil
+}
+
+// disabledTools 读取管理员禁用的工具
+func (s *ToolRegistryService) disabledTools() map[string]bool {
+	disabled := make(map[string]bool)
+	config, err := GetSystemConfigService().GetConfig(ConfigChatDisabledTools)
+	if err != nil {
+		return disabled
+	}
+	var names []string
+	if err := json.Unmarshal(config.Value, &names); err != nil {
+		return disabled
+	}
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return disabled
+}
+
+// intentRouter 读取意图识别方式
+func (s *ToolRegistryService) intentRouter() string {
+	config, err := GetSystemConfigService().GetConfig(ConfigChatToolIntentRouter)
+	if err != nil {
+		return toolIntentRouterDefaultSetting
+	}
+	var router string
+	if err := json.Unmarshal(config.Value, &router); err != nil {
+		return toolIntentRouterDefaultSetting
+	}
+	return router
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"github.com/fcraft/open-chat/internal/utils/fetch_utils"
+	"github.com/openai/openai-go"
+	"gorm.io/datatypes"
+)
+
+var (
+	urlFetchServiceInstance *URLFetchService
+	urlFetchServiceOnce     sync.Once
+)
+
+// URLFetchService 读取网址正文
+//
+// 下载受大小、超时及域名策略限制，拒绝访问内网地址；提取的正文按网址缓存在 Redis 中。
+// 提问中的链接在补全前自动读取并注入上下文，模型也可以通过 fetch_url 工具主动读取
+type URLFetchService struct {
+	*BaseService
+}
+
+const (
+	ConfigURLFetchDomains = "chat_url_fetch_domains"
+
+	urlFetchMaxBytes        = 5 << 20          // 单个网址响应体的最大字节数
+	urlFetchTimeout         = 10 * time.Second // 单个网址的下载超时时间
+	urlFetchMaxRedirects    = 5                // 最大重定向次数
+	urlFetchCacheExpiration = time.Hour        // 正文缓存时间
+	urlFetchMaxURLs         = 3                // 每条提问最多自动读取的链接数
+	urlFetchTokenBudget     = 4000             // 注入上下文的正文总 token 预算
+)
+
+var ErrURLNotAllowed = errors.New("url not allowed by domain policy")
+
+// urlPattern 匹配文本中的 http(s) 链接，不包含常见的中英文结尾标点
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `，。；！？、）】》]+`)
+
+// URLDomainPolicy 域名策略，域名同时匹配其子域名；deny 优先，allow 非空时仅允许其中的域名
+type URLDomainPolicy struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Allows 是否允许访问该域名
+func (p URLDomainPolicy) Allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if slice.Some(p.Deny, func(_ int, domain string) bool { return matchDomain(host, domain) }) {
+		return false
+	}
+	return len(p.Allow) == 0 || slice.Some(p.Allow, func(_ int, domain string) bool { return matchDomain(host, domain) })
+}
+
+func matchDomain(host string, domain string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "*."))
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+// FetchedURL 读取链接的结果，用于告知客户端引用的链接
+type FetchedURL struct {
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"` // 正文因大小或 token 预算被截断
+	Error     string `json:"error,omitempty"`
+}
+
+// URLContext 提问中链接的读取结果
+type URLContext struct {
+	Prompt  string       `json:"-"` // 包含正文的提示词，全部读取失败时为空
+	Sources []FetchedURL `json:"sources"`
+}
+
+func InitURLFetchService(base *BaseService) *URLFetchService {
+	urlFetchServiceOnce.Do(
+		func() {
+			urlFetchServiceInstance = &URLFetchService{
+				BaseService: base,
+			}
+			registerURLFetchConfig()
+			err := GetToolRegistryService().RegisterTool(
+				RegisteredTool{
+					CompletionTool: urlFetchServiceInstance.FetchURLTool(),
+					DisplayName:    "读取网页",
+					Keywords:       []string{"http", "链接", "网页", "网址", "文章"},
+				},
+			)
+			if err != nil {
+				base.Logger.Error("failed to register url fetch tool", "error", err.Error())
+			}
+		},
+	)
+	return urlFetchServiceInstance
+}
+
+func GetURLFetchService() *URLFetchService {
+	return urlFetchServiceInstance
+}
+
+func registerURLFetchConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigURLFetchDomains,
+			DisplayName: "链接读取域名策略",
+			Schema: map[string]interface{}{
+				"type":        "object",
+				"description": "domains match their subdomains; deny wins, and a non-empty allow list permits only listed domains",
+				"properties": map[string]interface{}{
+					"allow": map[string]interface{}{
+						"type":  "array",
+						"items": map[string]string{"type": "string"},
+					},
+					"deny": map[string]interface{}{
+						"type":  "array",
+						"items": map[string]string{"type": "string"},
+					},
+				},
+			},
+			Default:  datatypes.NewJSONType[any](URLDomainPolicy{Allow: []string{}, Deny: []string{}}),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getDomainPolicy 获取域名策略，未配置时不限制
+func (s *URLFetchService) getDomainPolicy() URLDomainPolicy {
+	var policy URLDomainPolicy
+	if config, err := GetSystemConfigService().GetConfig(ConfigURLFetchDomains); err == nil {
+		_ = json.Unmarshal(config.Value, &policy)
+	}
+	return policy
+}
+
+// ExtractURLs 提取文本中的链接，去重并去掉结尾的标点
+func ExtractURLs(text string, limit int) []string {
+	var urls []string
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		match = strings.TrimRight(match, ".,;:!?)]}")
+		if _, err := url.Parse(match); err != nil || slice.Contain(urls, match) {
+			continue
+		}
+		urls = append(urls, match)
+		if limit > 0 && len(urls) >= limit {
+			break
+		}
+	}
+	return urls
+}
+
+// FetchDocument 读取网址正文，优先使用缓存
+func (s *URLFetchService) FetchDocument(ctx context.Context, rawURL string) (*fetch_utils.Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	policy := s.getDomainPolicy()
+	if !policy.Allows(u.Hostname()) {
+		return nil, ErrURLNotAllowed
+	}
+	if data, err := s.RedisStore.GetFetchedDocument(rawURL); err == nil && data != nil {
+		var doc fetch_utils.Document
+		if err := json.Unmarshal(data, &doc); err == nil {
+			return &doc, nil
+		}
+	}
+
+	doc, err := fetch_utils.Fetch(
+		ctx, rawURL, fetch_utils.FetchOptions{
+			MaxBytes:     urlFetchMaxBytes,
+			Timeout:      urlFetchTimeout,
+			MaxRedirects: urlFetchMaxRedirects,
+			AllowHost:    policy.Allows,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(doc); err == nil {
+		if err := s.RedisStore.CacheFetchedDocument(rawURL, data, urlFetchCacheExpiration); err != nil {
+			s.Logger.Warn("failed to cache fetched document", "url", rawURL, "error", err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// QuestionTokens 读取提问中的链接最多注入提示词的 token 数，提问不含链接时为 0，用于在组装上下文时预留
+func (s *URLFetchService) QuestionTokens(question string) int64 {
+	if len(ExtractURLs(question, 1)) == 0 {
+		return 0
+	}
+	return urlFetchTokenBudget
+}
+
+// FetchForQuestion 读取提问中的链接，按 token 预算截断后组装为提示词；提问不含链接时返回 nil
+func (s *URLFetchService) FetchForQuestion(ctx context.Context, question string) *URLContext {
+	urls := ExtractURLs(question, urlFetchMaxURLs)
+	if len(urls) == 0 {
+		return nil
+	}
+	docs := make([]*fetch_utils.Document, len(urls))
+	result := &URLContext{Sources: make([]FetchedURL, len(urls))}
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.Sources[i].URL = rawURL
+			doc, err := s.FetchDocument(ctx, rawURL)
+			if err != nil {
+				s.Logger.Info("failed to fetch url", "url", rawURL, "error", err.Error())
+				result.Sources[i].Error = err.Error()
+				return
+			}
+			docs[i] = doc
+		}()
+	}
+	wg.Wait()
+
+	fetched := len(slice.Filter(docs, func(_ int, doc *fetch_utils.Document) bool { return doc != nil }))
+	if fetched == 0 {
+		return result
+	}
+	var sb strings.Builder
+	sb.WriteString("以下是我提供的链接中的内容：\n\n")
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		content, truncated := truncateToTokens(doc.Content, urlFetchTokenBudget/int64(fetched))
+		result.Sources[i].Title = doc.Title
+		result.Sources[i].Truncated = doc.Truncated || truncated
+		fmt.Fprintf(&sb, "<document url=%q title=%q>\n%s\n</document>\n\n", urls[i], doc.Title, content)
+	}
+	sb.WriteString("请结合以上内容回答我的问题。")
+	result.Prompt = sb.String()
+	return result
+}
+
+// truncateToTokens 按比例截断文本，使估算的 token 数不超过预算
+func truncateToTokens(text string, budget int64) (string, bool) {
+	tokens := chat_utils.EstimateTokens(text)
+	if tokens <= budget {
+		return text, false
+	}
+	runes := []rune(text)
+	keep := int(float64(len(runes)) * float64(budget) / float64(tokens))
+	for keep > 0 && chat_utils.EstimateTokens(string(runes[:keep])) > budget {
+		keep = keep * 9 / 10
+	}
+	return string(runes[:keep]) + "…", true
+}
+
+// FetchURLTool 读取网页的工具，供模型在需要时主动读取链接
+func (s *URLFetchService) FetchURLTool() chat_utils.CompletionTool {
+	return chat_utils.CompletionTool{
+		Param: openai.ChatCompletionToolParam{
+			Function: openai.FunctionDefinitionParam{
+				Name:        "fetch_url",
+				Description: openai.String("Fetch a web page or PDF by url and return its readable text content."),
+				Parameters: openai.FunctionParameters{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"url": map[string]string{
+							"type":        "string",
+							"description": "the http or https url to fetch",
+						},
+					},
+					"required": []string{"url"},
+				},
+			},
+		},
+		UserTip: "读取链接中...",
+		Handler: func(args ...interface{}) (*chat_utils.CompletionToolHandlerReturn, error) {
+			if len(args) == 0 {
+				return nil, nil
+			}
+			params := struct {
+				URL string `json:"url"`
+			}{}
+			if err := json.Unmarshal([]byte(args[0].(string)), &params); err != nil {
+				return nil, err
+			}
+			doc, err := s.FetchDocument(context.Background(), params.URL)
+			if err != nil {
+				return nil, err
+			}
+			content, truncated := truncateToTokens(doc.Content, urlFetchTokenBudget)
+			return &chat_utils.CompletionToolHandlerReturn{
+				Data: map[string]any{
+					"url":       doc.URL,
+					"title":     doc.Title,
+					"content":   content,
+					"truncated": doc.Truncated || truncated,
+				},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	usageServiceInstance *UsageService
+	usageServiceOnce     sync.Once
+)
+
+// UsageService 用量流水及计费
+//
+// 每次补全记录一条 schema.UsageRecord，并在同一事务中扣减用户余额，套餐额度优先扣减。模型配置了价格且设置了 ConfigUsageBasePrice 时按价格折算扣减额度，
+// 否则沿用按 token 计费：输入 token 计 1，输出 token 计 defaultCompletionTokenWeight
+type UsageService struct {
+	*BaseService
+}
+
+const (
+	ConfigUsageBasePrice = "usage_base_price"
+
+	defaultCompletionTokenWeight = 4 // 未按价格计费时，一个输出 token 折合的额度
+)
+
+func InitUsageService(base *BaseService) *UsageService {
+	usageServiceOnce.Do(
+		func() {
+			usageServiceInstance = &UsageService{
+				BaseService: base,
+			}
+			registerUsageConfig()
+			registerUsageQuotaConfig()
+		},
+	)
+	return usageServiceInstance
+}
+
+func GetUsageService() *UsageService {
+	return usageServiceInstance
+}
+
+func registerUsageConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigUsageBasePrice,
+			DisplayName: "额度基准价格",
+			Schema: map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "price of 1M quota tokens, in the same currency as model prices; 0 disables price-based charging",
+			},
+			Default:  datatypes.NewJSONType[any](0),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getUsageBasePrice 获取每百万额度对应的价格，未配置时返回 0
+func getUsageBasePrice() float64 {
+	config, err := GetSystemConfigService().GetConfig(ConfigUsageBasePrice)
+	if err != nil {
+		return 0
+	}
+	var price float64
+	if err := json.Unmarshal(config.Value, &price); err != nil || price < 0 {
+		return 0
+	}
+	return price
+}
+
+// UsageRecordParams 记录用量的参数，模型名称、提供商、价格及扣减额度由服务计算
+type UsageRecordParams struct {
+	UserID         uint64
+	Source         schema.UsageRecordSource
+	SessionID      string
+	MessageID      uint64
+	PresetID       uint64
+	PresetRecordID uint64
+	ModelID        uint64
+	APIKeyID       uint64
+	Usage          chat_utils.DoneResponseUsage
+}
+
+// RecordUsage 记录一次补全的用量并扣减用户余额
+func (s *UsageService) RecordUsage(params UsageRecordParams) (*schema.UsageRecord, error) {
+	record := &schema.UsageRecord{
+		UserID:           params.UserID,
+		Source:           params.Source,
+		SessionID:        params.SessionID,
+		MessageID:        params.MessageID,
+		PresetID:         params.PresetID,
+		PresetRecordID:   params.PresetRecordID,
+		ModelID:          params.ModelID,
+		APIKeyID:         params.APIKeyID,
+		PromptTokens:     params.Usage.PromptTokens,
+		CompletionTokens: params.Usage.CompletionTokens,
+		ReasoningTokens:  params.Usage.ReasoningTokens,
+	}
+	if params.ModelID > 0 {
+		// 已删除的模型仍按原价格计费
+		var model schema.Model
+		if err := s.Gorm.Unscoped().Where("id = ?", params.ModelID).First(&model).Error; err == nil {
+			record.Model = model.Name
+			record.ProviderID = model.ProviderID
+			record.InputPrice = model.Config.InputPrice
+			record.OutputPrice = model.Config.OutputPrice
+		}
+	}
+	record.Cost = (float64(record.PromptTokens)*record.InputPrice + float64(record.CompletionTokens)*record.OutputPrice) / 1e6
+	record.Charge = computeUsageCharge(record, getUsageBasePrice())
+
+	err := s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+			if record.UserID == 0 || record.Charge == 0 {
+				return nil
+			}
+			// 优先扣减套餐额度，不足部分从余额扣减
+			return tx.Model(&schema.UserUsage{}).
+				Where("user_id = ?", record.UserID).
+				UpdateColumns(
+					map[string]interface{}{
+						"plan_token": gorm.Expr("GREATEST(plan_token - ?, 0)", record.Charge),
+						"token":      gorm.Expr("token - GREATEST(? - plan_token, 0)", record.Charge),
+					},
+				).Error
+		},
+	)
+	if err != nil {
+		s.Logger.Error("failed to record usage", "user_id", record.UserID, "source", record.Source, "error", err.Error())
+		return nil, err
+	}
+	return record, nil
+}
+
+// computeUsageCharge 计算扣减的额度
+func computeUsageCharge(record *schema.UsageRecord, basePrice float64) int64 {
+	if basePrice > 0 && (record.InputPrice > 0 || record.OutputPrice > 0) {
+		return int64(math.Ceil(record.Cost * 1e6 / basePrice))
+	}
+	return record.PromptTokens + record.CompletionTokens*defaultCompletionTokenWeight
+}
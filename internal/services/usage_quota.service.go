@@ -0,0 +1,248 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/schema"
+	"gorm.io/datatypes"
+)
+
+const (
+	ConfigUsageRoleQuota                = "usage_role_quota"
+	ConfigUsageEstimateCompletionTokens = "usage_estimate_completion_tokens"
+
+	defaultEstimateCompletionTokens = 1024 // 预估额度时为输出预留的 token 数
+)
+
+// RoleQuota 角色的周期额度上限，0 表示不限制
+type RoleQuota struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// QuotaPeriod 周期额度的使用情况
+type QuotaPeriod struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"` // 下次重置时间
+}
+
+// QuotaStatus 用户的余额及周期额度
+type QuotaStatus struct {
+	Balance     int64        `json:"balance"`       // 可用额度，含套餐额度
+	PlanBalance int64        `json:"plan_balance"`  // 套餐当月剩余额度
+	PlanResetAt *time.Time   `json:"plan_reset_at"` // 套餐额度上次重置时间
+	Plan        *schema.Plan `json:"plan"`          // 当前套餐，未配置套餐时为空
+	Unlimited   bool         `json:"unlimited"`     // 是否不受余额及额度限制
+	Daily       *QuotaPeriod `json:"daily"`         // 每日额度，未限制时为空
+	Monthly     *QuotaPeriod `json:"monthly"`       // 每月额度，未限制时为空
+}
+
+func registerUsageQuotaConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigUsageRoleQuota,
+			DisplayName: "角色用量额度",
+			Schema: map[string]interface{}{
+				"type":        "object",
+				"description": "daily/monthly quota by role name, 0 means unlimited; users with several roles get the most permissive limit",
+				"additionalProperties": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"daily":   map[string]interface{}{"type": "integer", "minimum": 0},
+						"monthly": map[string]interface{}{"type": "integer", "minimum": 0},
+					},
+				},
+			},
+			Default:  datatypes.NewJSONType[any](map[string]RoleQuota{}),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+	err = GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigUsageEstimateCompletionTokens,
+			DisplayName: "额度预估的输出 token 数",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "completion tokens reserved when checking quota before a request; a smaller max_tokens of the request takes precedence",
+			},
+			Default:  datatypes.NewJSONType[any](defaultEstimateCompletionTokens),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// getEstimateCompletionTokens 获取预估额度时为输出预留的 token 数
+func getEstimateCompletionTokens() int64 {
+	config, err := GetSystemConfigService().GetConfig(ConfigUsageEstimateCompletionTokens)
+	if err != nil {
+		return defaultEstimateCompletionTokens
+	}
+	var tokens int64
+	if err := json.Unmarshal(config.Value, &tokens); err != nil || tokens < 0 {
+		return defaultEstimateCompletionTokens
+	}
+	return tokens
+}
+
+// getRoleQuotas 获取各角色的额度配置
+func getRoleQuotas() map[string]RoleQuota {
+	config, err := GetSystemConfigService().GetConfig(ConfigUsageRoleQuota)
+	if err != nil {
+		return nil
+	}
+	var quotas map[string]RoleQuota
+	if err := json.Unmarshal(config.Value, &quotas); err != nil {
+		return nil
+	}
+	return quotas
+}
+
+// resolveRoleQuota 多个角色时取最宽松的限制，任一角色未配置或为 0 即不限制
+func resolveRoleQuota(roles []schema.Role, quotas map[string]RoleQuota) RoleQuota {
+	var result RoleQuota
+	dailyLimited, monthlyLimited := len(roles) > 0, len(roles) > 0
+	for _, role := range roles {
+		quota := quotas[role.Name]
+		if quota.Daily <= 0 {
+			dailyLimited = false
+		}
+		if quota.Monthly <= 0 {
+			monthlyLimited = false
+		}
+		result.Daily = max(result.Daily, quota.Daily)
+		result.Monthly = max(result.Monthly, quota.Monthly)
+	}
+	if !dailyLimited {
+		result.Daily = 0
+	}
+	if !monthlyLimited {
+		result.Monthly = 0
+	}
+	return result
+}
+
+// EstimateCharge 估算一次请求需要扣减的额度，用于发起补全前的检查
+//
+// 请求可能故障转移到任一候选模型，按其中最贵的模型估算；输出按系统配置预留 token 数，
+// maxTokens 大于 0 且更小时以 maxTokens 为准
+func (s *UsageService) EstimateCharge(candidates []schema.Model, promptTokens int64, maxTokens int64) int64 {
+	completionTokens := getEstimateCompletionTokens()
+	if maxTokens > 0 {
+		completionTokens = min(completionTokens, maxTokens)
+	}
+	return estimateCharge(candidates, promptTokens, completionTokens)
+}
+
+// EstimateEmbeddingCharge 估算一次向量化请求需要扣减的额度，向量化没有输出 token
+func (s *UsageService) EstimateEmbeddingCharge(candidates []schema.Model, tokens int64) int64 {
+	return estimateCharge(candidates, tokens, 0)
+}
+
+// estimateCharge 按候选模型中最贵的模型估算额度
+func estimateCharge(candidates []schema.Model, promptTokens int64, completionTokens int64) int64 {
+	basePrice := getUsageBasePrice()
+	var charge int64
+	for _, model := range candidates {
+		record := &schema.UsageRecord{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			InputPrice:       model.Config.InputPrice,
+			OutputPrice:      model.Config.OutputPrice,
+		}
+		record.Cost = (float64(promptTokens)*record.InputPrice + float64(completionTokens)*record.OutputPrice) / 1e6
+		charge = max(charge, computeUsageCharge(record, basePrice))
+	}
+	return charge
+}
+
+// GetQuotaStatus 获取用户的余额及周期额度使用情况
+func (s *UsageService) GetQuotaStatus(userId uint64) (*QuotaStatus, error) {
+	roles, err := s.Helper.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+	status := &QuotaStatus{
+		Unlimited: slice.Some(roles, func(_ int, role schema.Role) bool { return role.Name == "SUPER_ADMIN" }),
+	}
+	usage, err := s.GormStore.GetUserUsage(userId)
+	if err != nil {
+		return nil, err
+	}
+	status.Balance = usage.Token + usage.PlanToken
+	status.PlanBalance = usage.PlanToken
+	status.PlanResetAt = usage.PlanResetAt
+	if status.Plan, err = GetPlanService().GetUserPlan(userId); err != nil {
+		return nil, err
+	}
+	if status.Unlimited {
+		return status, nil
+	}
+
+	quota := resolveRoleQuota(roles, getRoleQuotas())
+	now := time.Now()
+	if quota.Daily > 0 {
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if status.Daily, err = s.getQuotaPeriod(userId, quota.Daily, start, start.AddDate(0, 0, 1)); err != nil {
+			return nil, err
+		}
+	}
+	if quota.Monthly > 0 {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		if status.Monthly, err = s.getQuotaPeriod(userId, quota.Monthly, start, start.AddDate(0, 1, 0)); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// getQuotaPeriod 统计周期内的用量
+func (s *UsageService) getQuotaPeriod(userId uint64, limit int64, start time.Time, resetAt time.Time) (*QuotaPeriod, error) {
+	var used int64
+	if err := s.Gorm.Model(&schema.UsageRecord{}).
+		Where("user_id = ? AND created_at >= ?", userId, start).
+		Select("COALESCE(SUM(charge), 0)").
+		Scan(&used).Error; err != nil {
+		return nil, err
+	}
+	return &QuotaPeriod{
+		Limit:     limit,
+		Used:      used,
+		Remaining: max(limit-used, 0),
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// CheckQuota 发起补全前检查余额及周期额度是否足够支付预估的费用，预估见 EstimateCharge
+//
+// 额度不足时返回 constants.BizErrInsufficientBalance、constants.BizErrDailyQuotaExceeded 或 constants.BizErrMonthlyQuotaExceeded
+func (s *UsageService) CheckQuota(userId uint64, estimatedCharge int64) error {
+	status, err := s.GetQuotaStatus(userId)
+	if err != nil {
+		return err
+	}
+	if status.Unlimited {
+		return nil
+	}
+	if status.Balance <= 0 || status.Balance < estimatedCharge {
+		return constants.BizErrInsufficientBalance
+	}
+	if status.Daily != nil && (status.Daily.Remaining <= 0 || status.Daily.Remaining < estimatedCharge) {
+		return constants.BizErrDailyQuotaExceeded
+	}
+	if status.Monthly != nil && (status.Monthly.Remaining <= 0 || status.Monthly.Remaining < estimatedCharge) {
+		return constants.BizErrMonthlyQuotaExceeded
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/fcraft/open-chat/internal/constants"
+	"github.com/fcraft/open-chat/internal/schema"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	voucherServiceInstance *VoucherService
+	voucherServiceOnce     sync.Once
+)
+
+// VoucherService 兑换码
+//
+// 兑换在数据库事务中完成：按条件递增兑换次数防止超额兑换，唯一索引防止同一用户重复兑换，多实例部署下同样有效
+type VoucherService struct {
+	*BaseService
+}
+
+const (
+	voucherCodeAlphabet      = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去除易混淆的 I/O/0/1，长度为 32 保证取模无偏
+	voucherCodeGroups        = 4                                  // 兑换码分组数
+	voucherCodeGroupLength   = 4                                  // 每组字符数
+	voucherMaxBatchCount     = 1000                               // 单批次最多生成的兑换码数量
+	voucherMaxRedeemFailures = 10                                 // 窗口期内允许的兑换失败次数，防止暴力猜测
+	voucherRedeemFailWindow  = time.Hour                          // 兑换失败次数的统计窗口
+
+	ConfigLegacyGiftCardValue = "legacy_gift_card_value"
+	legacyGiftCardConfig      = "temp_gift_card" // 旧版礼品卡配置，保存礼品卡码列表
+	legacyGiftCardBatchName   = "旧版礼品卡（自动迁移）"    // 迁移生成的批次名称，同时用于判断是否已迁移
+)
+
+func InitVoucherService(base *BaseService) *VoucherService {
+	voucherServiceOnce.Do(
+		func() {
+			voucherServiceInstance = &VoucherService{
+				BaseService: base,
+			}
+			registerVoucherConfig()
+			voucherServiceInstance.migrateLegacyGiftCards()
+		},
+	)
+	return voucherServiceInstance
+}
+
+func registerVoucherConfig() {
+	err := GetSystemConfigService().RegisterSystemConfig(
+		RegisterConfigParams{
+			Name:        ConfigLegacyGiftCardValue,
+			DisplayName: "旧版礼品卡面额",
+			Schema: map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "quota granted by each legacy temp_gift_card code once migrated to vouchers; 0 postpones the migration",
+			},
+			Default:  datatypes.NewJSONType[any](0),
+			IsPublic: false,
+		},
+	)
+	if err != nil {
+		return
+	}
+}
+
+// migrateLegacyGiftCards 将旧版 temp_gift_card 配置中的礼品卡码迁移为一个兑换码批次，仅执行一次
+//
+// 旧配置未记录面额，需先在 legacy_gift_card_value 中配置，未配置时跳过并在下次启动时重试
+func (s *VoucherService) migrateLegacyGiftCards() {
+	var legacy schema.SystemConfig
+	if err := s.Gorm.Where("name = ?", legacyGiftCardConfig).First(&legacy).Error; err != nil {
+		return
+	}
+	var rawCodes []string
+	if err := json.Unmarshal(legacy.Value, &rawCodes); err != nil {
+		return
+	}
+	codes := slice.Unique(
+		slice.FilterMap(
+			rawCodes, func(_ int, code string) (string, bool) {
+				code = normalizeVoucherCode(code)
+				return code, code != ""
+			},
+		),
+	)
+	if len(codes) == 0 {
+		return
+	}
+	var migrated int64
+	if err := s.Gorm.Model(&schema.VoucherBatch{}).Where("name = ?", legacyGiftCardBatchName).Count(&migrated).Error; err != nil || migrated > 0 {
+		return
+	}
+
+	var value int64
+	if config, err := GetSystemConfigService().GetConfig(ConfigLegacyGiftCardValue); err == nil {
+		_ = json.Unmarshal(config.Value, &value)
+	}
+	if value <= 0 {
+		s.Logger.Warn(
+			"legacy gift cards are not redeemable until legacy_gift_card_value is configured",
+			"count", len(codes),
+		)
+		return
+	}
+
+	batch := &schema.VoucherBatch{
+		Name:           legacyGiftCardBatchName,
+		Value:          value,
+		Count:          len(codes),
+		MaxRedemptions: 1,
+	}
+	err := s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(batch).Error; err != nil {
+				return err
+			}
+			vouchers := slice.Map(
+				codes, func(_ int, code string) schema.Voucher {
+					return schema.Voucher{
+						BatchID:        batch.ID,
+						Code:           code,
+						Value:          value,
+						MaxRedemptions: 1,
+					}
+				},
+			)
+			return tx.CreateInBatches(&vouchers, 100).Error
+		},
+	)
+	if err != nil {
+		s.Logger.Error("failed to migrate legacy gift cards", "error", err.Error())
+		return
+	}
+	s.Logger.Info("legacy gift cards migrated", "batch_id", batch.ID, "count", len(codes))
+}
+
+func GetVoucherService() *VoucherService {
+	return voucherServiceInstance
+}
+
+// generateVoucherCode 生成形如 XXXX-XXXX-XXXX-XXXX 的随机兑换码
+func generateVoucherCode() (string, error) {
+	buf := make([]byte, voucherCodeGroups*voucherCodeGroupLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, b := range buf {
+		if i > 0 && i%voucherCodeGroupLength == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(voucherCodeAlphabet[int(b)%len(voucherCodeAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+// normalizeVoucherCode 忽略大小写、空格及分隔符，统一为生成时的格式
+func normalizeVoucherCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	if len(code) != voucherCodeGroups*voucherCodeGroupLength {
+		return code
+	}
+	groups := make([]string, 0, voucherCodeGroups)
+	for i := 0; i < len(code); i += voucherCodeGroupLength {
+		groups = append(groups, code[i:i+voucherCodeGroupLength])
+	}
+	return strings.Join(groups, "-")
+}
+
+// CreateBatchParams 生成兑换码批次的参数
+type CreateBatchParams struct {
+	Name           string
+	Value          int64
+	Count          int
+	MaxRedemptions int
+	ExpiresAt      *time.Time
+	CreatedBy      uint64
+}
+
+// CreateBatch 生成一批兑换码
+func (s *VoucherService) CreateBatch(params CreateBatchParams) (*schema.VoucherBatch, []schema.Voucher, error) {
+	if params.Value <= 0 {
+		return nil, nil, errors.New("value must be positive")
+	}
+	if params.Count <= 0 || params.Count > voucherMaxBatchCount {
+		return nil, nil, errors.New("count must be between 1 and 1000")
+	}
+	if params.MaxRedemptions <= 0 {
+		params.MaxRedemptions = 1
+	}
+	if params.ExpiresAt != nil && params.ExpiresAt.Before(time.Now()) {
+		return nil, nil, errors.New("expires_at must be in the future")
+	}
+
+	batch := &schema.VoucherBatch{
+		Name:           params.Name,
+		Value:          params.Value,
+		Count:          params.Count,
+		MaxRedemptions: params.MaxRedemptions,
+		ExpiresAt:      params.ExpiresAt,
+		CreatedBy:      params.CreatedBy,
+	}
+	vouchers := make([]schema.Voucher, 0, params.Count)
+	err := s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(batch).Error; err != nil {
+				return err
+			}
+			for range params.Count {
+				code, err := generateVoucherCode()
+				if err != nil {
+					return err
+				}
+				vouchers = append(
+					vouchers, schema.Voucher{
+						BatchID:        batch.ID,
+						Code:           code,
+						Value:          batch.Value,
+						MaxRedemptions: batch.MaxRedemptions,
+						ExpiresAt:      batch.ExpiresAt,
+					},
+				)
+			}
+			return tx.CreateInBatches(&vouchers, 100).Error
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, vouchers, nil
+}
+
+// DisableVoucher 停用兑换码，已兑换的额度不受影响
+func (s *VoucherService) DisableVoucher(voucherId uint64) error {
+	result := s.Gorm.Model(&schema.Voucher{}).Where("id = ?", voucherId).Update("disabled", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DisableBatch 停用批次内的全部兑换码
+func (s *VoucherService) DisableBatch(batchId uint64) error {
+	return s.Gorm.Model(&schema.Voucher{}).Where("batch_id = ?", batchId).Update("disabled", true).Error
+}
+
+// RedeemVoucher 兑换兑换码，将额度计入用户余额
+//
+// 兑换失败时返回 constants.BizErrVoucher* 错误；窗口期内无效兑换码过多时拒绝兑换
+func (s *VoucherService) RedeemVoucher(userId uint64, code string) (*schema.VoucherRedemption, error) {
+	if failures, err := s.RedisStore.GetVoucherRedeemFailures(userId); err == nil && failures >= voucherMaxRedeemFailures {
+		return nil, constants.BizErrVoucherTooManyAttempts
+	}
+
+	redemption, err := s.redeem(userId, normalizeVoucherCode(code))
+	if errors.Is(err, constants.BizErrVoucherInvalid) {
+		if err := s.RedisStore.IncrVoucherRedeemFailures(userId, voucherRedeemFailWindow); err != nil {
+			s.Logger.Warn("failed to record voucher redeem failure", "user_id", userId, "error", err.Error())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.Info("voucher redeemed", "user_id", userId, "voucher_id", redemption.VoucherID, "value", redemption.Value)
+	return redemption, nil
+}
+
+func (s *VoucherService) redeem(userId uint64, code string) (*schema.VoucherRedemption, error) {
+	var redemption *schema.VoucherRedemption
+	err := s.Gorm.Transaction(
+		func(tx *gorm.DB) error {
+			var voucher schema.Voucher
+			if err := tx.Where("code = ?", code).First(&voucher).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return constants.BizErrVoucherInvalid
+				}
+				return err
+			}
+			if voucher.Disabled {
+				return constants.BizErrVoucherInvalid
+			}
+			if voucher.ExpiresAt != nil && voucher.ExpiresAt.Before(time.Now()) {
+				return constants.BizErrVoucherExpired
+			}
+			var redeemed int64
+			if err := tx.Model(&schema.VoucherRedemption{}).
+				Where("voucher_id = ? AND user_id = ?", voucher.ID, userId).
+				Count(&redeemed).Error; err != nil {
+				return err
+			}
+			if redeemed > 0 {
+				return constants.BizErrVoucherRedeemed
+			}
+
+			// 条件更新保证兑换次数不超过上限
+			result := tx.Model(&schema.Voucher{}).
+				Where("id = ? AND redeemed < max_redemptions", voucher.ID).
+				UpdateColumn("redeemed", gorm.Expr("redeemed + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return constants.BizErrVoucherExhausted
+			}
+
+			// 计入余额
+			usage := schema.UserUsage{UserID: userId}
+			if err := tx.Where("user_id = ?", userId).FirstOrCreate(&usage).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&schema.UserUsage{}).
+				Where("user_id = ?", userId).
+				UpdateColumn("token", gorm.Expr("token + ?", voucher.Value)).Error; err != nil {
+				return err
+			}
+			var balance int64
+			if err := tx.Model(&schema.UserUsage{}).
+				Where("user_id = ?", userId).
+				Select("token").
+				Scan(&balance).Error; err != nil {
+				return err
+			}
+
+			// 记录兑换，唯一索引兜底并发的重复兑换
+			redemption = &schema.VoucherRedemption{
+				VoucherID: voucher.ID,
+				UserID:    userId,
+				BatchID:   voucher.BatchID,
+				Code:      voucher.Code,
+				Value:     voucher.Value,
+				Balance:   balance,
+			}
+			if err := tx.Create(redemption).Error; err != nil {
+				if translator, ok := tx.Dialector.(gorm.ErrorTranslator); ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+					// 并发兑换时后提交者违反唯一索引
+					return constants.BizErrVoucherRedeemed
+				}
+				return err
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
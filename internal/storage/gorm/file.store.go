@@ -0,0 +1,14 @@
+package gorm
+
+import "github.com/fcraft/open-chat/internal/schema"
+
+// GetFilesByIDs 批量获取上传完成的文件（含储存桶信息）
+func (s *GormStore) GetFilesByIDs(fileIds []uint64) ([]schema.File, error) {
+	var files []schema.File
+	if len(fileIds) == 0 {
+		return files, nil
+	}
+	return files, s.Db.Preload("Bucket").
+		Where("id IN ? AND status = ?", fileIds, schema.FileStatusReady).
+		Find(&files).Error
+}
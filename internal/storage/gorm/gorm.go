@@ -49,6 +49,10 @@ func NewGormStore() *GormStore {
 	db.Set("gorm:table_options", "AUTO_INCREMENT=100000000")
 	// 注册自定义序列化器
 	InitSerializer()
+	// 自定义关联表，需在迁移前注册
+	if err := db.SetupJoinTable(&schema.ModelCollection{}, "Models", &schema.ModelCollectionModel{}); err != nil {
+		store.Logger.Error("failed to setup join table", "error", err.Error())
+	}
 	// 自动迁移表结构
 	if err := db.AutoMigrate(
 		&schema.Bucket{}, &schema.File{},
@@ -57,13 +61,17 @@ func NewGormStore() *GormStore {
 		&schema.Message{},
 		&schema.User{},
 		&schema.Role{}, &schema.Permission{},
-		&schema.UserRole{},
+		&schema.UserRole{}, &schema.AccessToken{},
 		&schema.Provider{}, &schema.APIKey{},
-		&schema.Model{}, &schema.ModelCollection{},
+		&schema.Model{}, &schema.ModelCollection{}, &schema.ModelCollectionModel{},
+		&schema.ModelHealthCheck{},
 		&schema.Preset{}, &schema.PresetCompletionRecord{},
 		&schema.Schedule{},
 		&schema.UserSession{},
-		&schema.UserUsage{},
+		&schema.UserUsage{}, &schema.UsageRecord{},
+		&schema.Plan{}, &schema.UserPlan{}, &schema.RolePlan{},
+		&schema.VoucherBatch{}, &schema.Voucher{}, &schema.VoucherRedemption{},
+		&schema.KnowledgeBase{}, &schema.KnowledgeDocument{}, &schema.KnowledgeChunk{},
 		&schema.Problem{}, &schema.ProblemUserRecord{}, &schema.ProblemMakeRecord{},
 		&schema.Resource{},
 		&schema.Exam{}, &schema.ExamProblem{}, &schema.ExamUserRecord{}, &schema.ExamUserRecordAnswer{},
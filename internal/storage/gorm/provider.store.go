@@ -104,3 +104,24 @@ func (s *GormStore) DeleteModel(modelId uint64) error {
 func (s *GormStore) DeleteModelsByProvider(providerId uint64) error {
 	return s.Db.Where("provider_id = ?", providerId).Delete(&schema.Model{}).Error
 }
+
+// UpdateCollectionModelSettings 更新模型在集合内的负载均衡参数，仅更新已关联的模型
+func (s *GormStore) UpdateCollectionModelSettings(collectionId uint64, settings []schema.ModelCollectionModel) error {
+	return s.Db.Transaction(
+		func(tx *gorm.DB) error {
+			for _, setting := range settings {
+				if err := tx.Model(&schema.ModelCollectionModel{}).
+					Where("model_collection_id = ? AND model_id = ?", collectionId, setting.ModelID).
+					Updates(
+						map[string]any{
+							"weight":   setting.Weight,
+							"priority": setting.Priority,
+						},
+					).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
@@ -199,16 +199,156 @@ func (s *GormStore) DeleteMessages(sessionId string, messageIds []uint64) error
 	return s.Db.Where("session_id = ? AND id in (?)", sessionId, messageIds).Delete(&schema.Message{}).Error
 }
 
-// GetLatestMessages 获取会话的最新消息
+// CreateMessageChain 依次创建消息，并将每条消息挂载到前一条消息下（首条消息挂载到 parentId 下）
+func (s *GormStore) CreateMessageChain(parentId uint64, msgs *[]schema.Message) error {
+	return s.Db.Transaction(
+		func(tx *gorm.DB) error {
+			for i := range *msgs {
+				(*msgs)[i].ParentID = parentId
+				if err := tx.Create(&(*msgs)[i]).Error; err != nil {
+					return err
+				}
+				parentId = (*msgs)[i].ID
+			}
+			return nil
+		},
+	)
+}
+
+// LinkSessionMessages 将旧版线性会话的消息按 ID 顺序串联为消息树，并返回当前分支末端消息 ID
+//
+//	仅在会话尚未记录 current_message_id 时执行，已是消息树的会话直接返回
+func (s *GormStore) LinkSessionMessages(session *schema.Session) (uint64, error) {
+	if session.CurrentMessageID > 0 {
+		return session.CurrentMessageID, nil
+	}
+	var lastId uint64
+	err := s.Db.Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Exec(
+				`UPDATE messages SET parent_id = linked.prev_id FROM (
+					SELECT id, LAG(id, 1, 0) OVER (ORDER BY id) AS prev_id
+					FROM messages WHERE session_id = ? AND deleted_at IS NULL
+				) AS linked WHERE messages.id = linked.id`,
+				session.ID,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&schema.Message{}).
+				Where("session_id = ?", session.ID).
+				Select("COALESCE(MAX(id), 0)").
+				Scan(&lastId).Error; err != nil {
+				return err
+			}
+			return tx.Model(&schema.Session{}).
+				Where("id = ?", session.ID).
+				Update("current_message_id", lastId).Error
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	session.CurrentMessageID = lastId
+	return lastId, nil
+}
+
+// GetLatestMessages 获取会话当前选中分支上的最新消息
 func (s *GormStore) GetLatestMessages(sessionID string, limit int) ([]schema.Message, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.CurrentMessageID == 0 {
+		// 旧版线性会话，按时间顺序取最新的消息
+		var messages []schema.Message
+		err := s.Db.Where("session_id = ?", sessionID).
+			Order("created_at DESC").
+			Limit(limit).
+			Find(&messages).Error
+		slice.Reverse(messages)
+		return messages, err
+	}
+	return s.GetBranchMessages(session.CurrentMessageID, limit)
+}
+
+// GetBranchMessages 从分支末端消息沿父消息向上回溯，获取分支上最新的 limit 条消息（按时间正序）
+func (s *GormStore) GetBranchMessages(leafId uint64, limit int) ([]schema.Message, error) {
 	var messages []schema.Message
-	err := s.Db.Where("session_id = ?", sessionID).
-		Order("created_at ASC").
-		Limit(limit).
-		Find(&messages).Error
+	if leafId == 0 {
+		return messages, nil
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	err := s.Db.Raw(
+		`WITH RECURSIVE branch AS (
+			SELECT messages.*, 0 AS depth FROM messages WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT m.*, branch.depth + 1 FROM messages m
+			INNER JOIN branch ON m.id = branch.parent_id
+			WHERE m.deleted_at IS NULL
+		)
+		SELECT * FROM branch ORDER BY depth ASC LIMIT NULLIF(?, -1)`,
+		leafId,
+		limit,
+	).Scan(&messages).Error
+	slice.Reverse(messages)
 	return messages, err
 }
 
+// IsMessageOnBranch 判断消息是否位于以 leafId 为末端的分支上（含末端消息本身）
+func (s *GormStore) IsMessageOnBranch(messageId uint64, leafId uint64) (bool, error) {
+	var count int64
+	if messageId == 0 || leafId == 0 {
+		return false, nil
+	}
+	err := s.Db.Raw(
+		`WITH RECURSIVE branch AS (
+			SELECT id, parent_id FROM messages WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT m.id, m.parent_id FROM messages m
+			INNER JOIN branch ON m.id = branch.parent_id
+			WHERE m.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM branch WHERE id = ?`,
+		leafId,
+		messageId,
+	).Scan(&count).Error
+	return count > 0, err
+}
+
+// FindBranchLeaf 从指定消息向下查找分支末端消息（每层选择最新创建的子消息）
+func (s *GormStore) FindBranchLeaf(messageId uint64) (uint64, error) {
+	var leafId uint64
+	err := s.Db.Raw(
+		`WITH RECURSIVE leaf AS (
+			SELECT id, 0 AS depth FROM messages WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT (
+				SELECT m.id FROM messages m
+				WHERE m.parent_id = leaf.id AND m.deleted_at IS NULL
+				ORDER BY m.id DESC LIMIT 1
+			), leaf.depth + 1 FROM leaf WHERE leaf.id IS NOT NULL
+		)
+		SELECT id FROM leaf WHERE id IS NOT NULL ORDER BY depth DESC LIMIT 1`,
+		messageId,
+	).Scan(&leafId).Error
+	return leafId, err
+}
+
+// GetMessage 获取单条消息
+func (s *GormStore) GetMessage(messageId uint64) (*schema.Message, error) {
+	var message schema.Message
+	return &message, s.Db.Where("id = ?", messageId).First(&message).Error
+}
+
+// UpdateSessionCurrentMessage 切换会话当前选中的分支
+func (s *GormStore) UpdateSessionCurrentMessage(sessionID string, messageId uint64) error {
+	return s.Db.Model(&schema.Session{}).
+		Where("id = ?", sessionID).
+		Update("current_message_id", messageId).Error
+}
+
 // GetMessagesByPage 分页获取消息
 func (s *GormStore) GetMessagesByPage(sessionID string, param entity.ParamPagingSort) ([]schema.Message, *int64, error) {
 	return gorm_utils.GetByPageContinuous[schema.Message](
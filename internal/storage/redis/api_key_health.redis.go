@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const apiKeyHealthExpire = 7 * 24 * time.Hour // 健康数据过期时间，长期无失败的密钥重新统计
+
+func apiKeyHealthKey(apiKeyId uint64) string {
+	return fmt.Sprintf("api-key-health:%d", apiKeyId)
+}
+
+// resetAPIKeyFailuresScript 调用成功后清零连续失败次数，无健康数据时不创建
+var resetAPIKeyFailuresScript = redis.NewScript(
+	`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], 'failures', 0)
+	redis.call('HDEL', KEYS[1], 'cooldown_until')
+end
+return 0
+`,
+)
+
+// RecordAPIKeySuccess 记录一次成功的调用，结束冷却
+func (r *RedisStore) RecordAPIKeySuccess(apiKeyId uint64) error {
+	return resetAPIKeyFailuresScript.Run(context.Background(), r.Client, []string{apiKeyHealthKey(apiKeyId)}).Err()
+}
+
+// RecordAPIKeyFailure 记录一次失败的调用，并按连续失败次数指数增加冷却时间
+//
+//	Parameters:
+//		- apiKeyId: API Key ID
+//		- kind: 失败类型，如 429/timeout
+//		- errMsg: 错误信息
+//		- baseCooldown: 首次失败的冷却时间
+//		- maxCooldown: 冷却时间上限
+//	Returns:
+//		- time.Duration: 本次的冷却时间
+func (r *RedisStore) RecordAPIKeyFailure(apiKeyId uint64, kind string, errMsg string, baseCooldown time.Duration, maxCooldown time.Duration) (time.Duration, error) {
+	ctx := context.Background()
+	key := apiKeyHealthKey(apiKeyId)
+	failures, err := r.Client.HIncrBy(ctx, key, "failures", 1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	cooldown := maxCooldown
+	if shift := failures - 1; shift < 32 {
+		cooldown = min(baseCooldown<<shift, maxCooldown)
+	}
+	now := time.Now()
+	pipe := r.Client.TxPipeline()
+	pipe.HIncrBy(ctx, key, "count:"+kind, 1)
+	pipe.HSet(
+		ctx, key,
+		"cooldown_until", now.Add(cooldown).UnixMilli(),
+		"last_error", errMsg,
+		"last_failed_at", now.UnixMilli(),
+	)
+	pipe.Expire(ctx, key, apiKeyHealthExpire)
+	_, err = pipe.Exec(ctx)
+	return cooldown, err
+}
+
+// DisableAPIKeyHealth 标记密钥已停用，缓存中的密钥数据可能尚未刷新，选择密钥时以此为准
+func (r *RedisStore) DisableAPIKeyHealth(apiKeyId uint64, reason string) error {
+	ctx := context.Background()
+	key := apiKeyHealthKey(apiKeyId)
+	pipe := r.Client.TxPipeline()
+	pipe.HSet(ctx, key, "disabled", 1, "last_error", reason, "last_failed_at", time.Now().UnixMilli())
+	pipe.Persist(ctx, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ResetAPIKeyHealth 清除密钥的健康数据
+func (r *RedisStore) ResetAPIKeyHealth(apiKeyId uint64) error {
+	return r.Client.Del(context.Background(), apiKeyHealthKey(apiKeyId)).Err()
+}
+
+// GetAPIKeyHealth 批量获取密钥的健康状态，无健康数据的密钥视为可用
+func (r *RedisStore) GetAPIKeyHealth(apiKeyIds []uint64) (map[uint64]schema.APIKeyHealth, error) {
+	ctx := context.Background()
+	pipe := r.Client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(apiKeyIds))
+	for i, id := range apiKeyIds {
+		cmds[i] = pipe.HGetAll(ctx, apiKeyHealthKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	parseTime := func(value string) *time.Time {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil
+		}
+		t := time.UnixMilli(ms)
+		return &t
+	}
+	result := make(map[uint64]schema.APIKeyHealth, len(apiKeyIds))
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		health := schema.APIKeyHealth{
+			Status: schema.APIKeyStatusActive,
+			Counts: map[string]int64{},
+		}
+		health.Failures, _ = strconv.ParseInt(values["failures"], 10, 64)
+		health.LastError = values["last_error"]
+		health.LastFailedAt = parseTime(values["last_failed_at"])
+		for field, value := range values {
+			if kind, ok := strings.CutPrefix(field, "count:"); ok {
+				health.Counts[kind], _ = strconv.ParseInt(value, 10, 64)
+			}
+		}
+		if cooldownUntil := parseTime(values["cooldown_until"]); cooldownUntil != nil && cooldownUntil.After(now) {
+			health.Status = schema.APIKeyStatusCooling
+			health.CooldownUntil = cooldownUntil
+		}
+		if values["disabled"] == "1" {
+			health.Status = schema.APIKeyStatusDisabled
+		}
+		result[apiKeyIds[i]] = health
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+func embeddingKey(namespace string, hash string) string {
+	return "embedding:" + namespace + ":" + hash
+}
+
+// GetEmbeddings 批量获取缓存的向量，未缓存的哈希不在结果中
+func (r *RedisStore) GetEmbeddings(namespace string, hashes []string) (map[string][]float32, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(hashes))
+	for i, hash := range hashes {
+		keys[i] = embeddingKey(namespace, hash)
+	}
+	values, err := r.Client.MGet(context.Background(), keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]float32, len(hashes))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok || len(data) == 0 || len(data)%4 != 0 {
+			continue
+		}
+		vec := make([]float32, len(data)/4)
+		for j := range vec {
+			vec[j] = math.Float32frombits(binary.LittleEndian.Uint32([]byte(data[j*4 : j*4+4])))
+		}
+		result[hashes[i]] = vec
+	}
+	return result, nil
+}
+
+// CacheEmbeddings 批量缓存向量，以小端序 float32 的二进制形式存储
+func (r *RedisStore) CacheEmbeddings(namespace string, embeddings map[string][]float32, expiration time.Duration) error {
+	ctx := context.Background()
+	pipe := r.Client.Pipeline()
+	for hash, vec := range embeddings {
+		data := make([]byte, len(vec)*4)
+		for i, f := range vec {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(f))
+		}
+		pipe.Set(ctx, embeddingKey(namespace, hash), data, expiration)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
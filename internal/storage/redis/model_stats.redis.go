@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	modelStatsExpire = 24 * time.Hour // 统计数据过期时间，长期无请求的模型重新统计
+	modelStatsAlpha  = 0.2            // 指数滑动平均的平滑系数
+)
+
+// ModelStats 模型的实时调用统计
+type ModelStats struct {
+	Requests  int64   `json:"requests"`   // 请求次数
+	Errors    int64   `json:"errors"`     // 失败次数
+	LatencyMs float64 `json:"latency_ms"` // 首个响应的延迟（指数滑动平均）
+	ErrorRate float64 `json:"error_rate"` // 错误率（指数滑动平均）
+}
+
+func modelStatsKey(modelId uint64) string {
+	return fmt.Sprintf("model-stats:%d", modelId)
+}
+
+// recordModelStatScript 原子地更新请求计数及滑动平均值，首次记录时直接使用样本值
+var recordModelStatScript = redis.NewScript(
+	`
+local alpha = tonumber(ARGV[3])
+local requests = redis.call('HINCRBY', KEYS[1], 'requests', 1)
+local failed = tonumber(ARGV[2])
+if failed == 1 then
+	redis.call('HINCRBY', KEYS[1], 'errors', 1)
+end
+local errorRate = tonumber(redis.call('HGET', KEYS[1], 'error_rate') or failed)
+if requests > 1 then
+	errorRate = errorRate * (1 - alpha) + failed * alpha
+end
+redis.call('HSET', KEYS[1], 'error_rate', tostring(errorRate))
+if failed == 0 then
+	local latency = tonumber(ARGV[1])
+	local avg = redis.call('HGET', KEYS[1], 'latency_ms')
+	if avg then
+		latency = tonumber(avg) * (1 - alpha) + latency * alpha
+	end
+	redis.call('HSET', KEYS[1], 'latency_ms', tostring(latency))
+end
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return requests
+`,
+)
+
+// RecordModelStat 记录一次模型调用，失败的调用不计入延迟
+func (r *RedisStore) RecordModelStat(modelId uint64, latency time.Duration, success bool) error {
+	failed := 1
+	if success {
+		failed = 0
+	}
+	return recordModelStatScript.Run(
+		context.Background(), r.Client, []string{modelStatsKey(modelId)},
+		latency.Milliseconds(), failed, modelStatsAlpha, int(modelStatsExpire.Seconds()),
+	).Err()
+}
+
+// GetModelStats 批量获取模型的调用统计，无统计数据的模型不在结果中
+func (r *RedisStore) GetModelStats(modelIds []uint64) (map[uint64]ModelStats, error) {
+	ctx := context.Background()
+	pipe := r.Client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(modelIds))
+	for i, id := range modelIds {
+		cmds[i] = pipe.HGetAll(ctx, modelStatsKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]ModelStats, len(modelIds))
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		if len(values) == 0 {
+			continue
+		}
+		var stats ModelStats
+		stats.Requests, _ = strconv.ParseInt(values["requests"], 10, 64)
+		stats.Errors, _ = strconv.ParseInt(values["errors"], 10, 64)
+		stats.LatencyMs, _ = strconv.ParseFloat(values["latency_ms"], 64)
+		stats.ErrorRate, _ = strconv.ParseFloat(values["error_rate"], 64)
+		result[modelIds[i]] = stats
+	}
+	return result, nil
+}
+
+// NextCollectionRoundRobin 获取模型集合的轮询计数
+func (r *RedisStore) NextCollectionRoundRobin(collectionId uint64) (int64, error) {
+	return r.Client.Incr(context.Background(), fmt.Sprintf("model-collection-rr:%d", collectionId)).Result()
+}
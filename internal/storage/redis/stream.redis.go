@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CompletionStreamEvent 缓存于 Redis Stream 中的 SSE 事件
+type CompletionStreamEvent struct {
+	ID    string // Redis Stream 条目 ID，同时作为 SSE 的事件 ID
+	Event string // SSE 事件名称
+	Data  string // SSE 事件数据（已编码）
+}
+
+func completionStreamKey(messageId uint64) string {
+	return fmt.Sprintf("completion-stream:%d", messageId)
+}
+
+// AppendCompletionEvent 向回复消息的事件流追加一条事件
+func (r *RedisStore) AppendCompletionEvent(messageId uint64, event string, data string, expire time.Duration) (string, error) {
+	ctx := context.Background()
+	key := completionStreamKey(messageId)
+	id, err := r.Client.XAdd(
+		ctx, &redis.XAddArgs{
+			Stream: key,
+			Values: map[string]interface{}{
+				"event": event,
+				"data":  data,
+			},
+		},
+	).Result()
+	if err != nil {
+		return "", err
+	}
+	return id, r.Client.Expire(ctx, key, expire).Err()
+}
+
+// ReadCompletionEvents 读取回复消息事件流中 lastId 之后的事件，没有新事件时最多阻塞 block 时长
+//
+//	Returns:
+//		[]CompletionStreamEvent 事件列表，阻塞超时返回空列表
+func (r *RedisStore) ReadCompletionEvents(ctx context.Context, messageId uint64, lastId string, block time.Duration) ([]CompletionStreamEvent, error) {
+	if lastId == "" {
+		lastId = "0"
+	}
+	streams, err := r.Client.XRead(
+		ctx, &redis.XReadArgs{
+			Streams: []string{completionStreamKey(messageId), lastId},
+			Count:   100,
+			Block:   block,
+		},
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var events []CompletionStreamEvent
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			event, _ := msg.Values["event"].(string)
+			data, _ := msg.Values["data"].(string)
+			events = append(
+				events, CompletionStreamEvent{
+					ID:    msg.ID,
+					Event: event,
+					Data:  data,
+				},
+			)
+		}
+	}
+	return events, nil
+}
+
+// ExistsCompletionStream 判断回复消息的事件流是否仍然存在
+func (r *RedisStore) ExistsCompletionStream(messageId uint64) bool {
+	count, err := r.Client.Exists(context.Background(), completionStreamKey(messageId)).Result()
+	return err == nil && count > 0
+}
+
+// ExpireCompletionStream 设置回复消息事件流的过期时间
+func (r *RedisStore) ExpireCompletionStream(messageId uint64, expire time.Duration) error {
+	return r.Client.Expire(context.Background(), completionStreamKey(messageId), expire).Err()
+}
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func fetchedDocumentKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "fetched-document:" + hex.EncodeToString(sum[:])
+}
+
+// GetFetchedDocument 获取缓存的网址正文，未缓存时返回 nil
+func (r *RedisStore) GetFetchedDocument(url string) ([]byte, error) {
+	data, err := r.Client.Get(context.Background(), fetchedDocumentKey(url)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// CacheFetchedDocument 缓存网址正文
+func (r *RedisStore) CacheFetchedDocument(url string, data []byte, expiration time.Duration) error {
+	return r.Client.Set(context.Background(), fetchedDocumentKey(url), data, expiration).Err()
+}
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func voucherRedeemFailuresKey(userId uint64) string {
+	return fmt.Sprintf("voucher-redeem-failures:%d", userId)
+}
+
+// GetVoucherRedeemFailures 获取用户在窗口期内兑换失败的次数
+func (r *RedisStore) GetVoucherRedeemFailures(userId uint64) (int64, error) {
+	count, err := r.Client.Get(context.Background(), voucherRedeemFailuresKey(userId)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// IncrVoucherRedeemFailures 记录一次兑换失败，窗口期从首次失败开始计算
+func (r *RedisStore) IncrVoucherRedeemFailures(userId uint64, window time.Duration) error {
+	ctx := context.Background()
+	key := voucherRedeemFailuresKey(userId)
+	count, err := r.Client.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		return r.Client.Expire(ctx, key, window).Err()
+	}
+	return nil
+}
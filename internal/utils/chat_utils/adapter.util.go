@@ -0,0 +1,256 @@
+package chat_utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/utils/fetch_utils"
+)
+
+// 提供商协议类型，对应 schema.Provider.Type
+const (
+	ProviderTypeOpenAI    = "openai"    // OpenAI Chat Completions 及兼容格式
+	ProviderTypeAnthropic = "anthropic" // Anthropic Messages API
+	ProviderTypeGemini    = "gemini"    // Google Gemini API
+	ProviderTypeOllama    = "ollama"    // Ollama 原生 API
+)
+
+// ToolCall 模型发起的工具调用
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON 格式的参数
+}
+
+// StepRequest 一轮模型请求
+type StepRequest struct {
+	Messages   []Message        // 完整的消息列表，包含系统消息
+	Tools      []CompletionTool // 可用的工具
+	ToolChoice string           // 工具选择策略：auto/none，仅在 Tools 非空时有效
+}
+
+// StepResult 一轮模型请求的完整结果
+type StepResult struct {
+	Content          string
+	ReasoningContent string
+	ToolCalls        []ToolCall
+	Usage            DoneResponseUsage
+}
+
+// ProviderAdapter 提供商协议适配器，负责将通用的请求转换为提供商原生协议，并将响应转换为通用的事件流
+type ProviderAdapter interface {
+	// StreamStep 发起一轮流式请求，过程中发送内容及思考内容事件，返回本轮的完整结果
+	StreamStep(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, error)
+}
+
+var providerAdapters sync.Map // 协议类型 -> ProviderAdapter
+
+func init() {
+	RegisterProviderAdapter(ProviderTypeOpenAI, &openAIAdapter{})
+	RegisterProviderAdapter(ProviderTypeAnthropic, &anthropicAdapter{})
+	RegisterProviderAdapter(ProviderTypeGemini, &geminiAdapter{})
+	RegisterProviderAdapter(ProviderTypeOllama, &ollamaAdapter{})
+}
+
+// RegisterProviderAdapter 注册提供商协议适配器，同名覆盖
+func RegisterProviderAdapter(providerType string, adapter ProviderAdapter) {
+	providerAdapters.Store(providerType, adapter)
+}
+
+// GetProviderAdapter 获取提供商协议适配器，类型为空时使用 OpenAI 格式
+func GetProviderAdapter(providerType string) (ProviderAdapter, error) {
+	if providerType == "" {
+		providerType = ProviderTypeOpenAI
+	}
+	adapter, ok := providerAdapters.Load(providerType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
+	}
+	return adapter.(ProviderAdapter), nil
+}
+
+// providerHeaderTimeout 等待提供商响应头的超时时间
+const providerHeaderTimeout = 60 * time.Second
+
+// httpClient 提供商请求使用的 HTTP 客户端，流式请求不设置整体超时，由 ctx 控制
+var httpClient = func() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = providerHeaderTimeout
+	return &http.Client{Transport: transport}
+}()
+
+// ProviderHTTPError 提供商返回的非 2xx 响应
+type ProviderHTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderHTTPError) Error() string {
+	return fmt.Sprintf("provider responded %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON 以 JSON 格式发送请求，非 2xx 响应返回 ProviderHTTPError
+func postJSON(ctx context.Context, url string, headers map[string]string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(resp.Body)
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &ProviderHTTPError{StatusCode: resp.StatusCode, Body: string(errBody)}
+	}
+	return resp, nil
+}
+
+// readSSE 逐条读取 SSE 事件，handler 返回 false 时停止读取
+func readSSE(r io.Reader, handler func(event string, data []byte) (bool, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var event string
+	var data bytes.Buffer
+	dispatch := func() (bool, error) {
+		if data.Len() == 0 {
+			event = ""
+			return true, nil
+		}
+		next, err := handler(event, bytes.Clone(data.Bytes()))
+		event = ""
+		data.Reset()
+		return next, err
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if next, err := dispatch(); err != nil || !next {
+				return err
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	_, err := dispatch()
+	return err
+}
+
+const (
+	maxAttachmentDownloadSize = 20 << 20         // 需要内联的附件的下载大小上限
+	attachmentDownloadTimeout = 30 * time.Second // 下载附件的超时时间
+)
+
+// attachmentClient 下载附件使用的 HTTP 客户端，附件链接可能由用户提供，拒绝连接内网地址
+var attachmentClient = fetch_utils.NewSafeClient(attachmentDownloadTimeout, 3)
+
+// loadAttachmentData 读取附件内容，支持 data URL 及 http(s) 链接，链接不能指向内网地址
+//
+//	Returns:
+//		- string: MIME 类型
+//		- []byte: 文件内容
+func loadAttachmentData(ctx context.Context, attachment Attachment) (string, []byte, error) {
+	if rest, ok := strings.CutPrefix(attachment.URL, "data:"); ok {
+		meta, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(meta, ";base64") {
+			return "", nil, errors.New("unsupported data url")
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		return strings.TrimSuffix(meta, ";base64"), data, err
+	}
+
+	u, err := url.Parse(attachment.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", nil, fetch_utils.ErrUnsupportedScheme
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := attachmentClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("download attachment failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentDownloadSize+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) > maxAttachmentDownloadSize {
+		return "", nil, errors.New("attachment too large")
+	}
+	mimeType := attachment.MimeType
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return mimeType, data, nil
+}
+
+// attachmentNote 无法以原生格式发送的附件，以文本形式告知模型
+func attachmentNote(attachment Attachment) string {
+	if strings.HasPrefix(attachment.URL, "data:") {
+		return fmt.Sprintf("[附件: %s]", attachment.Name)
+	}
+	return fmt.Sprintf("[附件: %s](%s)", attachment.Name, attachment.URL)
+}
+
+// toolParameters 获取工具的 JSON Schema 参数定义
+func toolParameters(tool CompletionTool) map[string]any {
+	if tool.Param.Function.Parameters == nil {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return tool.Param.Function.Parameters
+}
+
+// splitSystemMessages 分离系统消息，部分协议要求系统提示单独传递
+func splitSystemMessages(messages []Message) (string, []Message) {
+	var systemParts []string
+	var rest []Message
+	for _, m := range messages {
+		if m.Role == "system" {
+			systemParts = append(systemParts, m.Content)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	return strings.Join(systemParts, "\n\n"), rest
+}
@@ -0,0 +1,296 @@
+package chat_utils
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicVersion          = "2023-06-01"
+	anthropicDefaultMaxTokens = 4096 // Messages API 要求必须指定 max_tokens
+)
+
+// anthropicAdapter Anthropic Messages API
+type anthropicAdapter struct{}
+
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Source    *anthropicMedia `json:"source,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicMedia struct {
+	Type      string `json:"type"` // url/base64
+	URL       string `json:"url,omitempty"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int64              `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  map[string]string  `json:"tool_choice,omitempty"`
+	Stream      bool               `json:"stream"`
+}
+
+// anthropicStreamEvent 流式事件，仅解析需要的字段
+type anthropicStreamEvent struct {
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Message struct {
+		Usage struct {
+			InputTokens int64 `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Usage struct {
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (a *anthropicAdapter) StreamStep(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, error) {
+	system, messages := splitSystemMessages(req.Messages)
+	body := anthropicRequest{
+		Model:     opts.Model,
+		MaxTokens: opts.MaxTokens,
+		System:    system,
+		Messages:  a.convertMessages(messages),
+		Stream:    true,
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = anthropicDefaultMaxTokens
+	}
+	if opts.Temperature > 0 {
+		// Anthropic 的温度范围为 0~1
+		temperature := min(opts.Temperature, 1)
+		body.Temperature = &temperature
+	}
+	if len(req.Tools) > 0 {
+		for _, tool := range req.Tools {
+			body.Tools = append(
+				body.Tools, anthropicTool{
+					Name:        tool.Param.Function.Name,
+					Description: tool.Param.Function.Description.Value,
+					InputSchema: toolParameters(tool),
+				},
+			)
+		}
+		body.ToolChoice = map[string]string{"type": req.ToolChoice}
+	}
+
+	resp, err := postJSON(
+		ctx, strings.TrimSuffix(opts.Provider.BaseUrl, "/")+"/v1/messages", map[string]string{
+			"x-api-key":         opts.Provider.ApiKey,
+			"anthropic-version": anthropicVersion,
+		}, body,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	result := &StepResult{}
+	var content strings.Builder
+	toolCalls := map[int]*ToolCall{} // content block 序号 -> 工具调用
+	var toolOrder []int
+	err = readSSE(
+		resp.Body, func(_ string, data []byte) (bool, error) {
+			var event anthropicStreamEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				return false, err
+			}
+			switch event.Type {
+			case "message_start":
+				result.Usage.PromptTokens = event.Message.Usage.InputTokens
+			case "content_block_start":
+				if event.ContentBlock.Type == "tool_use" {
+					toolCalls[event.Index] = &ToolCall{ID: event.ContentBlock.ID, Name: event.ContentBlock.Name}
+					toolOrder = append(toolOrder, event.Index)
+				}
+			case "content_block_delta":
+				switch event.Delta.Type {
+				case "text_delta":
+					content.WriteString(event.Delta.Text)
+					eventChan <- StreamEvent{Type: ContentEventType, Content: event.Delta.Text}
+				case "thinking_delta":
+					result.ReasoningContent += event.Delta.Thinking
+					eventChan <- StreamEvent{Type: ReasoningContentEventType, Content: event.Delta.Thinking}
+				case "input_json_delta":
+					if toolCall, ok := toolCalls[event.Index]; ok {
+						toolCall.Arguments += event.Delta.PartialJSON
+					}
+				}
+			case "message_delta":
+				result.Usage.CompletionTokens = event.Usage.OutputTokens
+			case "message_stop":
+				return false, nil
+			case "error":
+				return false, anthropicStreamError(event.Error.Type, event.Error.Message)
+			}
+			return true, nil
+		},
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, err
+	}
+
+	result.Content = content.String()
+	for _, idx := range toolOrder {
+		toolCall := toolCalls[idx]
+		if toolCall.Arguments == "" {
+			toolCall.Arguments = "{}"
+		}
+		result.ToolCalls = append(result.ToolCalls, *toolCall)
+	}
+	return result, nil
+}
+
+// anthropicStreamError 将流中的错误事件转换为对应的 HTTP 错误，便于判断是否可重试
+func anthropicStreamError(errType string, message string) error {
+	statusCode := map[string]int{
+		"invalid_request_error": http.StatusBadRequest,
+		"authentication_error":  http.StatusUnauthorized,
+		"permission_error":      http.StatusForbidden,
+		"rate_limit_error":      http.StatusTooManyRequests,
+		"api_error":             http.StatusInternalServerError,
+		"overloaded_error":      529,
+	}[errType]
+	if statusCode == 0 {
+		return errors.New(errType + ": " + message)
+	}
+	return &ProviderHTTPError{StatusCode: statusCode, Body: errType + ": " + message}
+}
+
+// convertMessages 转换为 Anthropic 消息格式，工具结果以 user 消息发送，连续的同角色消息需要合并
+func (a *anthropicAdapter) convertMessages(messages []Message) []anthropicMessage {
+	var result []anthropicMessage
+	appendBlocks := func(role string, blocks ...anthropicContentBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+			return
+		}
+		result = append(result, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	for _, m := range messages {
+		switch m.Role {
+		case "tool":
+			appendBlocks(
+				"user", anthropicContentBlock{
+					Type:      "tool_result",
+					ToolUseID: m.ToolCallID,
+					Content:   m.Content,
+				},
+			)
+		case "user":
+			var blocks []anthropicContentBlock
+			for _, attachment := range m.Attachments {
+				blocks = append(blocks, a.convertAttachment(attachment))
+			}
+			if m.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+			}
+			appendBlocks("user", blocks...)
+		default:
+			var blocks []anthropicContentBlock
+			if m.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+			}
+			for _, toolCall := range m.ToolCalls {
+				input := json.RawMessage(toolCall.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(
+					blocks, anthropicContentBlock{
+						Type:  "tool_use",
+						ID:    toolCall.ID,
+						Name:  toolCall.Name,
+						Input: input,
+					},
+				)
+			}
+			appendBlocks("assistant", blocks...)
+		}
+	}
+	return result
+}
+
+// convertAttachment 图片使用 image 块，PDF 使用 document 块，其余以文本提示
+func (a *anthropicAdapter) convertAttachment(attachment Attachment) anthropicContentBlock {
+	var blockType string
+	switch {
+	case attachment.IsImage():
+		blockType = "image"
+	case attachment.MimeType == "application/pdf":
+		blockType = "document"
+	default:
+		return anthropicContentBlock{Type: "text", Text: attachmentNote(attachment)}
+	}
+
+	if rest, ok := strings.CutPrefix(attachment.URL, "data:"); ok {
+		meta, payload, _ := strings.Cut(rest, ",")
+		if _, err := base64.StdEncoding.DecodeString(payload); err == nil {
+			return anthropicContentBlock{
+				Type: blockType,
+				Source: &anthropicMedia{
+					Type:      "base64",
+					MediaType: strings.TrimSuffix(meta, ";base64"),
+					Data:      payload,
+				},
+			}
+		}
+		return anthropicContentBlock{Type: "text", Text: attachmentNote(attachment)}
+	}
+	return anthropicContentBlock{
+		Type:   blockType,
+		Source: &anthropicMedia{Type: "url", URL: attachment.URL},
+	}
+}
@@ -0,0 +1,296 @@
+package chat_utils
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// geminiAdapter Google Gemini API（generateContent）
+type geminiAdapter struct{}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiInlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiFunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent `json:"contents"`
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Tools             []struct {
+		FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+	} `json:"tools,omitempty"`
+	ToolConfig *struct {
+		FunctionCallingConfig struct {
+			Mode string `json:"mode"`
+		} `json:"functionCallingConfig"`
+	} `json:"toolConfig,omitempty"`
+	GenerationConfig struct {
+		Temperature     *float64 `json:"temperature,omitempty"`
+		MaxOutputTokens int64    `json:"maxOutputTokens,omitempty"`
+	} `json:"generationConfig"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int64 `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (a *geminiAdapter) StreamStep(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, error) {
+	system, messages := splitSystemMessages(req.Messages)
+	body := geminiRequest{
+		Contents: a.convertMessages(ctx, messages),
+	}
+	if system != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: system}}}
+	}
+	if opts.Temperature > 0 {
+		temperature := opts.Temperature
+		body.GenerationConfig.Temperature = &temperature
+	}
+	body.GenerationConfig.MaxOutputTokens = opts.MaxTokens
+	if len(req.Tools) > 0 {
+		var declarations []geminiFunctionDeclaration
+		for _, tool := range req.Tools {
+			declarations = append(
+				declarations, geminiFunctionDeclaration{
+					Name:        tool.Param.Function.Name,
+					Description: tool.Param.Function.Description.Value,
+					Parameters:  toolParameters(tool),
+				},
+			)
+		}
+		body.Tools = append(
+			body.Tools, struct {
+				FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+			}{FunctionDeclarations: declarations},
+		)
+		body.ToolConfig = &struct {
+			FunctionCallingConfig struct {
+				Mode string `json:"mode"`
+			} `json:"functionCallingConfig"`
+		}{}
+		body.ToolConfig.FunctionCallingConfig.Mode = strings.ToUpper(req.ToolChoice)
+	}
+
+	endpoint := fmt.Sprintf(
+		"%s/v1beta/models/%s:streamGenerateContent?alt=sse",
+		strings.TrimSuffix(opts.Provider.BaseUrl, "/"), url.PathEscape(opts.Model),
+	)
+	resp, err := postJSON(ctx, endpoint, map[string]string{"x-goog-api-key": opts.Provider.ApiKey}, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	result := &StepResult{}
+	var content strings.Builder
+	err = readSSE(
+		resp.Body, func(_ string, data []byte) (bool, error) {
+			var chunk geminiResponse
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return false, err
+			}
+			if chunk.Error != nil {
+				return false, &ProviderHTTPError{StatusCode: chunk.Error.Code, Body: chunk.Error.Message}
+			}
+			// 用量为累计值，以最后一次为准
+			if chunk.UsageMetadata.PromptTokenCount > 0 {
+				result.Usage.PromptTokens = chunk.UsageMetadata.PromptTokenCount
+				result.Usage.CompletionTokens = chunk.UsageMetadata.CandidatesTokenCount + chunk.UsageMetadata.ThoughtsTokenCount
+				result.Usage.ReasoningTokens = chunk.UsageMetadata.ThoughtsTokenCount
+			}
+			if len(chunk.Candidates) == 0 {
+				return true, nil
+			}
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				switch {
+				case part.FunctionCall != nil:
+					args, _ := json.Marshal(part.FunctionCall.Args)
+					result.ToolCalls = append(
+						result.ToolCalls, ToolCall{
+							// Gemini 不返回调用 ID，按顺序生成
+							ID:        fmt.Sprintf("call_%d", len(result.ToolCalls)),
+							Name:      part.FunctionCall.Name,
+							Arguments: string(args),
+						},
+					)
+				case part.Thought && part.Text != "":
+					result.ReasoningContent += part.Text
+					eventChan <- StreamEvent{Type: ReasoningContentEventType, Content: part.Text}
+				case part.Text != "":
+					content.WriteString(part.Text)
+					eventChan <- StreamEvent{Type: ContentEventType, Content: part.Text}
+				}
+			}
+			return true, nil
+		},
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, err
+	}
+	result.Content = content.String()
+	return result, nil
+}
+
+// convertMessages 转换为 Gemini 的 contents 格式，assistant 对应 model，工具结果以 user 角色的 functionResponse 发送
+func (a *geminiAdapter) convertMessages(ctx context.Context, messages []Message) []geminiContent {
+	var contents []geminiContent
+	toolNames := map[string]string{} // 工具调用 ID -> 工具名称
+	appendParts := func(role string, parts ...geminiPart) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			return
+		}
+		contents = append(contents, geminiContent{Role: role, Parts: parts})
+	}
+
+	for _, m := range messages {
+		switch m.Role {
+		case "tool":
+			var response map[string]any
+			if err := json.Unmarshal([]byte(m.Content), &response); err != nil {
+				response = map[string]any{"result": m.Content}
+			}
+			appendParts(
+				"user", geminiPart{
+					FunctionResponse: &geminiFunctionResponse{Name: toolNames[m.ToolCallID], Response: response},
+				},
+			)
+		case "user":
+			var parts []geminiPart
+			for _, attachment := range m.Attachments {
+				mimeType, data, err := loadAttachmentData(ctx, attachment)
+				if err != nil {
+					parts = append(parts, geminiPart{Text: attachmentNote(attachment)})
+					continue
+				}
+				parts = append(
+					parts, geminiPart{
+						InlineData: &geminiInlineData{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(data)},
+					},
+				)
+			}
+			if m.Content != "" {
+				parts = append(parts, geminiPart{Text: m.Content})
+			}
+			appendParts("user", parts...)
+		default:
+			var parts []geminiPart
+			if m.Content != "" {
+				parts = append(parts, geminiPart{Text: m.Content})
+			}
+			for _, toolCall := range m.ToolCalls {
+				toolNames[toolCall.ID] = toolCall.Name
+				var args map[string]any
+				_ = json.Unmarshal([]byte(toolCall.Arguments), &args)
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: toolCall.Name, Args: args}})
+			}
+			appendParts("model", parts...)
+		}
+	}
+	return contents
+}
+
+type geminiEmbedRequest struct {
+	Model                string        `json:"model"`
+	Content              geminiContent `json:"content"`
+	OutputDimensionality int64         `json:"outputDimensionality,omitempty"`
+}
+
+func (a *geminiAdapter) Embed(ctx context.Context, target EmbeddingTarget, texts []string) (*EmbeddingResult, error) {
+	model := "models/" + target.Model
+	requests := make([]geminiEmbedRequest, len(texts))
+	for i, text := range texts {
+		requests[i] = geminiEmbedRequest{
+			Model:                model,
+			Content:              geminiContent{Parts: []geminiPart{{Text: text}}},
+			OutputDimensionality: target.Dimensions,
+		}
+	}
+
+	endpoint := fmt.Sprintf(
+		"%s/v1beta/models/%s:batchEmbedContents",
+		strings.TrimSuffix(target.Provider.BaseUrl, "/"), url.PathEscape(target.Model),
+	)
+	resp, err := postJSON(
+		ctx, endpoint, map[string]string{"x-goog-api-key": target.Provider.ApiKey},
+		map[string]any{"requests": requests},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	var data struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+	// Gemini 不返回向量化的用量
+	result := &EmbeddingResult{PromptTokens: estimateEmbeddingTokens(texts)}
+	for _, item := range data.Embeddings {
+		result.Embeddings = append(result.Embeddings, item.Values)
+	}
+	return result, nil
+}
+
+func (a *geminiAdapter) MaxEmbeddingBatch() int {
+	return 100
+}
@@ -0,0 +1,219 @@
+package chat_utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ollamaAdapter Ollama 原生 API（/api/chat），响应为 NDJSON 流
+type ollamaAdapter struct{}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model    string           `json:"model"`
+	Messages []ollamaMessage  `json:"messages"`
+	Tools    []map[string]any `json:"tools,omitempty"`
+	Stream   bool             `json:"stream"`
+	Options  map[string]any   `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message struct {
+		Content   string           `json:"content"`
+		Thinking  string           `json:"thinking"`
+		ToolCalls []ollamaToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int64  `json:"prompt_eval_count"`
+	EvalCount       int64  `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+func (a *ollamaAdapter) StreamStep(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, error) {
+	body := ollamaRequest{
+		Model:    opts.Model,
+		Messages: a.convertMessages(ctx, req.Messages),
+		Stream:   true,
+		Options:  map[string]any{},
+	}
+	if opts.Temperature > 0 {
+		body.Options["temperature"] = opts.Temperature
+	}
+	if opts.MaxTokens > 0 {
+		body.Options["num_predict"] = opts.MaxTokens
+	}
+	// Ollama 不支持 tool_choice，禁用工具时直接不传递
+	if len(req.Tools) > 0 && req.ToolChoice != "none" {
+		for _, tool := range req.Tools {
+			body.Tools = append(
+				body.Tools, map[string]any{
+					"type": "function",
+					"function": map[string]any{
+						"name":        tool.Param.Function.Name,
+						"description": tool.Param.Function.Description.Value,
+						"parameters":  toolParameters(tool),
+					},
+				},
+			)
+		}
+	}
+
+	headers := map[string]string{}
+	if opts.Provider.ApiKey != "" {
+		headers["Authorization"] = "Bearer " + opts.Provider.ApiKey
+	}
+	resp, err := postJSON(ctx, strings.TrimSuffix(opts.Provider.BaseUrl, "/")+"/api/chat", headers, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	result := &StepResult{}
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return result, err
+		}
+		if chunk.Error != "" {
+			return result, errors.New(chunk.Error)
+		}
+		if chunk.Message.Thinking != "" {
+			result.ReasoningContent += chunk.Message.Thinking
+			eventChan <- StreamEvent{Type: ReasoningContentEventType, Content: chunk.Message.Thinking}
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			eventChan <- StreamEvent{Type: ContentEventType, Content: chunk.Message.Content}
+		}
+		for _, toolCall := range chunk.Message.ToolCalls {
+			args, _ := json.Marshal(toolCall.Function.Arguments)
+			result.ToolCalls = append(
+				result.ToolCalls, ToolCall{
+					// Ollama 不返回调用 ID，按顺序生成
+					ID:        fmt.Sprintf("call_%d", len(result.ToolCalls)),
+					Name:      toolCall.Function.Name,
+					Arguments: string(args),
+				},
+			)
+		}
+		if chunk.Done {
+			result.Usage = DoneResponseUsage{
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+			}
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, err
+	}
+	result.Content = content.String()
+	return result, nil
+}
+
+// convertMessages 转换为 Ollama 消息格式，图片以 base64 传递，其余附件以文本提示
+func (a *ollamaAdapter) convertMessages(ctx context.Context, messages []Message) []ollamaMessage {
+	result := make([]ollamaMessage, 0, len(messages))
+	toolNames := map[string]string{} // 工具调用 ID -> 工具名称
+	for _, m := range messages {
+		message := ollamaMessage{Role: m.Role, Content: m.Content}
+		switch m.Role {
+		case "tool":
+			message.ToolName = toolNames[m.ToolCallID]
+		case "user":
+			var notes []string
+			for _, attachment := range m.Attachments {
+				if attachment.IsImage() {
+					if _, data, err := loadAttachmentData(ctx, attachment); err == nil {
+						message.Images = append(message.Images, base64.StdEncoding.EncodeToString(data))
+						continue
+					}
+				}
+				notes = append(notes, attachmentNote(attachment))
+			}
+			if len(notes) > 0 {
+				message.Content = strings.Join(append(notes, m.Content), "\n")
+			}
+		case "assistant":
+			for _, toolCall := range m.ToolCalls {
+				toolNames[toolCall.ID] = toolCall.Name
+				var call ollamaToolCall
+				call.Function.Name = toolCall.Name
+				_ = json.Unmarshal([]byte(toolCall.Arguments), &call.Function.Arguments)
+				message.ToolCalls = append(message.ToolCalls, call)
+			}
+		}
+		result = append(result, message)
+	}
+	return result
+}
+
+func (a *ollamaAdapter) Embed(ctx context.Context, target EmbeddingTarget, texts []string) (*EmbeddingResult, error) {
+	body := map[string]any{
+		"model": target.Model,
+		"input": texts,
+	}
+	if target.Dimensions > 0 {
+		body["dimensions"] = target.Dimensions
+	}
+	headers := map[string]string{}
+	if target.Provider.ApiKey != "" {
+		headers["Authorization"] = "Bearer " + target.Provider.ApiKey
+	}
+	resp, err := postJSON(ctx, strings.TrimSuffix(target.Provider.BaseUrl, "/")+"/api/embed", headers, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	var data struct {
+		Embeddings      [][]float32 `json:"embeddings"`
+		PromptEvalCount int64       `json:"prompt_eval_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+	result := &EmbeddingResult{Embeddings: data.Embeddings, PromptTokens: data.PromptEvalCount}
+	if result.PromptTokens == 0 {
+		result.PromptTokens = estimateEmbeddingTokens(texts)
+	}
+	return result, nil
+}
+
+func (a *ollamaAdapter) MaxEmbeddingBatch() int {
+	return 512
+}
@@ -0,0 +1,230 @@
+package chat_utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/ssestream"
+)
+
+// openAIAdapter OpenAI Chat Completions 格式，兼容大部分第三方服务
+type openAIAdapter struct{}
+
+func (a *openAIAdapter) StreamStep(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, error) {
+	client := openai.NewClient(
+		option.WithBaseURL(opts.Provider.BaseUrl),
+		option.WithAPIKey(opts.Provider.ApiKey),
+		option.WithHTTPClient(httpClient),
+	)
+
+	// 构造参数，非必要不传递
+	params := openai.ChatCompletionNewParams{
+		Messages: slice.Map(req.Messages, func(_ int, m Message) openai.ChatCompletionMessageParamUnion { return toOpenAIMessage(m) }),
+		Model:    opts.Model,
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Opt(true),
+		},
+	}
+	if opts.Temperature > 0 {
+		params.Temperature = openai.Opt(opts.Temperature)
+	}
+	if opts.MaxTokens > 0 {
+		params.MaxTokens = openai.Opt(opts.MaxTokens)
+	}
+	if len(req.Tools) > 0 {
+		params.Tools = slice.Map(
+			req.Tools, func(_ int, tool CompletionTool) openai.ChatCompletionToolParam {
+				return tool.Param
+			},
+		)
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{
+			OfAuto: openai.Opt(req.ToolChoice),
+		}
+	}
+
+	// 获取提供商的流式响应
+	stream := client.Chat.Completions.NewStreaming(
+		ctx, params,
+	)
+	if stream.Err() != nil {
+		return nil, fmt.Errorf("failed to create stream: %w", stream.Err())
+	}
+	defer func(stream *ssestream.Stream[openai.ChatCompletionChunk]) {
+		err := stream.Close()
+		if err != nil {
+
+		}
+	}(stream)
+
+	acc := openai.ChatCompletionAccumulator{}
+	result := &StepResult{}
+
+	// 处理流式响应
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+		// 接收下一个响应，若流式结束，退出循环
+		if !stream.Next() {
+			break
+		}
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		// 额外解析含有 reasoning_content 的结构
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choiceDelta := &chunk.Choices[0].Delta
+		// 发送内容事件
+		if reasoningContent := choiceDelta.JSON.ExtraFields["reasoning_content"].Raw(); reasoningContent != "" && reasoningContent != "null" {
+			reasoningContent, _ = strings.CutPrefix(reasoningContent, "\"")
+			reasoningContent, _ = strings.CutSuffix(reasoningContent, "\"")
+			result.ReasoningContent += reasoningContent
+			eventChan <- StreamEvent{
+				Type:    ReasoningContentEventType,
+				Content: reasoningContent,
+			}
+		} else if choiceDelta.Content != "" {
+			eventChan <- StreamEvent{
+				Type:    ContentEventType,
+				Content: choiceDelta.Content,
+			}
+		}
+	}
+	if stream.Err() != nil {
+		return result, fmt.Errorf("stream error: %w", stream.Err())
+	}
+
+	result.Usage = DoneResponseUsage{
+		PromptTokens:     acc.Usage.PromptTokens,
+		CompletionTokens: acc.Usage.CompletionTokens,
+		ReasoningTokens:  acc.Usage.CompletionTokensDetails.ReasoningTokens,
+	}
+	if len(acc.Choices) > 0 {
+		message := acc.Choices[0].Message
+		result.Content = message.Content
+		result.ToolCalls = slice.Map(
+			message.ToolCalls, func(_ int, toolCall openai.ChatCompletionMessageToolCall) ToolCall {
+				return ToolCall{
+					ID:        toolCall.ID,
+					Name:      toolCall.Function.Name,
+					Arguments: toolCall.Function.Arguments,
+				}
+			},
+		)
+	}
+	return result, nil
+}
+
+// toOpenAIMessage 将通用消息转换为 OpenAI 的请求格式
+func toOpenAIMessage(m Message) openai.ChatCompletionMessageParamUnion {
+	switch m.Role {
+	case "user":
+		if len(m.Attachments) > 0 {
+			// 含附件的消息使用 content parts 格式
+			return openai.UserMessage(buildContentParts(m))
+		}
+		return openai.UserMessage(m.Content)
+	case "system":
+		return openai.SystemMessage(m.Content)
+	case "tool":
+		return openai.ToolMessage(m.Content, m.ToolCallID)
+	default:
+		message := openai.AssistantMessage(m.Content)
+		if len(m.ToolCalls) > 0 {
+			message.OfAssistant.ToolCalls = slice.Map(
+				m.ToolCalls, func(_ int, toolCall ToolCall) openai.ChatCompletionMessageToolCallParam {
+					return openai.ChatCompletionMessageToolCallParam{
+						ID: toolCall.ID,
+						Function: openai.ChatCompletionMessageToolCallFunctionParam{
+							Name:      toolCall.Name,
+							Arguments: toolCall.Arguments,
+						},
+					}
+				},
+			)
+		}
+		return message
+	}
+}
+
+// buildContentParts 将文本及附件转换为 OpenAI 的 content parts
+func buildContentParts(m Message) []openai.ChatCompletionContentPartUnionParam {
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(m.Attachments)+1)
+	if m.Content != "" {
+		parts = append(parts, openai.TextContentPart(m.Content))
+	}
+	for _, attachment := range m.Attachments {
+		switch {
+		case attachment.IsImage():
+			parts = append(
+				parts, openai.ImageContentPart(
+					openai.ChatCompletionContentPartImageImageURLParam{
+						URL: attachment.URL,
+					},
+				),
+			)
+		case strings.HasPrefix(attachment.URL, "data:"):
+			parts = append(
+				parts, openai.FileContentPart(
+					openai.ChatCompletionContentPartFileFileParam{
+						FileData: openai.String(attachment.URL),
+						Filename: openai.String(attachment.Name),
+					},
+				),
+			)
+		default:
+			// 无法内联的文件，仅以文本形式告知模型
+			parts = append(parts, openai.TextContentPart(attachmentNote(attachment)))
+		}
+	}
+	return parts
+}
+
+func (a *openAIAdapter) Embed(ctx context.Context, target EmbeddingTarget, texts []string) (*EmbeddingResult, error) {
+	client := openai.NewClient(
+		option.WithBaseURL(target.Provider.BaseUrl),
+		option.WithAPIKey(target.Provider.ApiKey),
+		option.WithHTTPClient(httpClient),
+	)
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: target.Model,
+	}
+	if target.Dimensions > 0 {
+		params.Dimensions = openai.Opt(target.Dimensions)
+	}
+	resp, err := client.Embeddings.New(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EmbeddingResult{
+		Embeddings:   make([][]float32, len(texts)),
+		PromptTokens: resp.Usage.PromptTokens,
+	}
+	for _, item := range resp.Data {
+		if item.Index < 0 || int(item.Index) >= len(texts) {
+			return nil, fmt.Errorf("embedding index out of range: %d", item.Index)
+		}
+		result.Embeddings[item.Index] = slice.Map(item.Embedding, func(_ int, f float64) float32 { return float32(f) })
+	}
+	if slice.Some(result.Embeddings, func(_ int, vec []float32) bool { return vec == nil }) {
+		return nil, fmt.Errorf("embedding count mismatch: expected %d, got %d", len(texts), len(resp.Data))
+	}
+	if result.PromptTokens == 0 {
+		result.PromptTokens = estimateEmbeddingTokens(texts)
+	}
+	return result, nil
+}
+
+func (a *openAIAdapter) MaxEmbeddingBatch() int {
+	return 2048
+}
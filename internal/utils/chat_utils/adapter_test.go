@@ -0,0 +1,243 @@
+package chat_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestProvider 启动返回固定响应的提供商服务，返回的 requests 记录收到的请求路径
+func newTestProvider(t *testing.T, status int, contentType string, body string) (Provider, *[]string) {
+	t.Helper()
+	var requests []string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+				_, _ = io.Copy(io.Discard, r.Body)
+				w.Header().Set("Content-Type", contentType)
+				w.WriteHeader(status)
+				_, _ = io.WriteString(w, body)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return Provider{BaseUrl: server.URL, ApiKey: "test-key"}, &requests
+}
+
+// sseBody 将 data 列表组装为 SSE 响应体
+func sseBody(events ...string) string {
+	var sb strings.Builder
+	for _, event := range events {
+		fmt.Fprintf(&sb, "data: %s\n\n", event)
+	}
+	return sb.String()
+}
+
+// runStep 执行一轮请求，返回结果及按顺序收到的内容和思考内容
+func runStep(t *testing.T, providerType string, provider Provider) (*StepResult, string, string, error) {
+	t.Helper()
+	adapter, err := GetProviderAdapter(providerType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.Type = providerType
+	eventChan := make(chan StreamEvent, 100)
+	result, err := adapter.StreamStep(
+		context.Background(), CompletionOptions{Provider: provider, Model: "test-model"},
+		StepRequest{Messages: []Message{{Role: "user", Content: "hi"}}, ToolChoice: "auto"}, eventChan,
+	)
+	close(eventChan)
+	var content, reasoning strings.Builder
+	for event := range eventChan {
+		switch event.Type {
+		case ContentEventType:
+			content.WriteString(event.Content)
+		case ReasoningContentEventType:
+			reasoning.WriteString(event.Content)
+		}
+	}
+	return result, content.String(), reasoning.String(), err
+}
+
+func assertToolCalls(t *testing.T, got []ToolCall, want []ToolCall) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("tool calls = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tool call %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func assertProviderStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var httpErr *ProviderHTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != status {
+		t.Fatalf("err = %v, want ProviderHTTPError %d", err, status)
+	}
+}
+
+func TestAnthropicStreamStep(t *testing.T) {
+	provider, requests := newTestProvider(
+		t, http.StatusOK, "text/event-stream", sseBody(
+			`{"type":"message_start","message":{"usage":{"input_tokens":12}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking"}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"let me "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"think"}}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"text"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":", world"}}`,
+			`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_1","name":"search"}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"\"go\"}"}}`,
+			`{"type":"content_block_start","index":3,"content_block":{"type":"tool_use","id":"toolu_2","name":"now"}}`,
+			`{"type":"message_delta","usage":{"output_tokens":34}}`,
+			`{"type":"message_stop"}`,
+		),
+	)
+	result, content, reasoning, err := runStep(t, ProviderTypeAnthropic, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*requests)[0] != "/v1/messages" {
+		t.Errorf("path = %s", (*requests)[0])
+	}
+	if result.Content != "Hello, world" || content != result.Content {
+		t.Errorf("content = %q, events = %q", result.Content, content)
+	}
+	if result.ReasoningContent != "let me think" || reasoning != result.ReasoningContent {
+		t.Errorf("reasoning = %q, events = %q", result.ReasoningContent, reasoning)
+	}
+	assertToolCalls(
+		t, result.ToolCalls, []ToolCall{
+			{ID: "toolu_1", Name: "search", Arguments: `{"q":"go"}`},
+			{ID: "toolu_2", Name: "now", Arguments: "{}"},
+		},
+	)
+	if result.Usage.PromptTokens != 12 || result.Usage.CompletionTokens != 34 {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+}
+
+func TestAnthropicStreamStepError(t *testing.T) {
+	provider, _ := newTestProvider(
+		t, http.StatusTooManyRequests, "application/json",
+		`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+	)
+	_, _, _, err := runStep(t, ProviderTypeAnthropic, provider)
+	assertProviderStatus(t, err, http.StatusTooManyRequests)
+	if !IsRetryableError(err) {
+		t.Errorf("429 should be retryable")
+	}
+
+	// 流中的错误事件按错误类型转换为状态码
+	provider, _ = newTestProvider(
+		t, http.StatusOK, "text/event-stream",
+		sseBody(`{"type":"error","error":{"type":"overloaded_error","message":"overloaded"}}`),
+	)
+	_, _, _, err = runStep(t, ProviderTypeAnthropic, provider)
+	assertProviderStatus(t, err, 529)
+}
+
+func TestGeminiStreamStep(t *testing.T) {
+	provider, requests := newTestProvider(
+		t, http.StatusOK, "text/event-stream", sseBody(
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"planning","thought":true}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":1}}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":", world"},{"functionCall":{"name":"search","args":{"q":"go"}}}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"now","args":{}}}]}}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":7,"thoughtsTokenCount":3}}`,
+		),
+	)
+	result, content, reasoning, err := runStep(t, ProviderTypeGemini, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*requests)[0] != "/v1beta/models/test-model:streamGenerateContent" {
+		t.Errorf("path = %s", (*requests)[0])
+	}
+	if result.Content != "Hello, world" || content != result.Content {
+		t.Errorf("content = %q, events = %q", result.Content, content)
+	}
+	if result.ReasoningContent != "planning" || reasoning != result.ReasoningContent {
+		t.Errorf("reasoning = %q, events = %q", result.ReasoningContent, reasoning)
+	}
+	assertToolCalls(
+		t, result.ToolCalls, []ToolCall{
+			{ID: "call_0", Name: "search", Arguments: `{"q":"go"}`},
+			{ID: "call_1", Name: "now", Arguments: "{}"},
+		},
+	)
+	// 用量为累计值，以最后一次为准，输出 token 包含思考 token
+	want := DoneResponseUsage{PromptTokens: 5, CompletionTokens: 10, ReasoningTokens: 3}
+	if result.Usage != want {
+		t.Errorf("usage = %+v, want %+v", result.Usage, want)
+	}
+}
+
+func TestGeminiStreamStepError(t *testing.T) {
+	provider, _ := newTestProvider(
+		t, http.StatusBadRequest, "application/json",
+		`{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`,
+	)
+	_, _, _, err := runStep(t, ProviderTypeGemini, provider)
+	assertProviderStatus(t, err, http.StatusBadRequest)
+	if IsRetryableError(err) {
+		t.Errorf("400 should not be retryable")
+	}
+
+	provider, _ = newTestProvider(
+		t, http.StatusOK, "text/event-stream",
+		sseBody(`{"error":{"code":503,"message":"model overloaded"}}`),
+	)
+	_, _, _, err = runStep(t, ProviderTypeGemini, provider)
+	assertProviderStatus(t, err, http.StatusServiceUnavailable)
+}
+
+func TestOllamaStreamStep(t *testing.T) {
+	lines := []string{
+		`{"message":{"role":"assistant","content":"","thinking":"hmm"},"done":false}`,
+		`{"message":{"role":"assistant","content":"Hello"},"done":false}`,
+		`{"message":{"role":"assistant","content":", world"},"done":false}`,
+		`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"search","arguments":{"q":"go"}}}]},"done":false}`,
+		`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":9,"eval_count":21}`,
+	}
+	provider, requests := newTestProvider(t, http.StatusOK, "application/x-ndjson", strings.Join(lines, "\n")+"\n")
+	result, content, reasoning, err := runStep(t, ProviderTypeOllama, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*requests)[0] != "/api/chat" {
+		t.Errorf("path = %s", (*requests)[0])
+	}
+	if result.Content != "Hello, world" || content != result.Content {
+		t.Errorf("content = %q, events = %q", result.Content, content)
+	}
+	if result.ReasoningContent != "hmm" || reasoning != result.ReasoningContent {
+		t.Errorf("reasoning = %q, events = %q", result.ReasoningContent, reasoning)
+	}
+	assertToolCalls(t, result.ToolCalls, []ToolCall{{ID: "call_0", Name: "search", Arguments: `{"q":"go"}`}})
+	if result.Usage.PromptTokens != 9 || result.Usage.CompletionTokens != 21 {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+}
+
+func TestOllamaStreamStepError(t *testing.T) {
+	provider, _ := newTestProvider(t, http.StatusNotFound, "application/json", `{"error":"model \"test-model\" not found"}`)
+	_, _, _, err := runStep(t, ProviderTypeOllama, provider)
+	assertProviderStatus(t, err, http.StatusNotFound)
+
+	// 流中的错误以 error 字段返回
+	provider, _ = newTestProvider(t, http.StatusOK, "application/x-ndjson", `{"error":"out of memory"}`+"\n")
+	_, _, _, err = runStep(t, ProviderTypeOllama, provider)
+	if err == nil || err.Error() != "out of memory" {
+		t.Errorf("err = %v", err)
+	}
+}
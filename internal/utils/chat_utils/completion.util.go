@@ -2,106 +2,47 @@ package chat_utils
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"github.com/duke-git/lancet/v2/slice"
-	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
 )
 
-// Completion 非流式聊天完成
+// Completion 非流式聊天完成，内部以流式请求实现并汇总结果
 func Completion(ctx context.Context, opts CompletionOptions) (*CompletionResponse, error) {
 	// 参数校验
 	if err := validateOptions(opts); err != nil {
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
-	// 初始化客户端
-	client := openai.NewClient(option.WithBaseURL(opts.Provider.BaseUrl), option.WithAPIKey(opts.Provider.ApiKey))
-
-	// 构建请求消息
-	messages := buildMessages(opts)
-
-	// 构建请求参数
-	reqMessages := slice.Map(
-		messages, func(_ int, m Message) openai.ChatCompletionMessageParamUnion {
-			if m.Role == "user" {
-				return openai.UserMessage(m.Content)
-			} else {
-				return openai.AssistantMessage(m.Content)
-			}
-		},
-	)
-
-	availableTools := slice.Map(
-		opts.Tools, func(_ int, tool CompletionTool) openai.ChatCompletionToolParam {
-			return tool.Param
-		},
-	)
-	params := openai.ChatCompletionNewParams{
-		Messages: reqMessages,
-		Model:    opts.Model,
-	}
-	if opts.Temperature > 0 {
-		params.Temperature = openai.Opt(opts.Temperature)
-	}
-	if opts.MaxTokens > 0 {
-		params.MaxTokens = openai.Opt(opts.MaxTokens)
-	}
-	if len(opts.Tools) > 0 {
-		params.Tools = availableTools
-		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{
-			OfAuto: openai.Opt("auto"),
-		}
+	req := StepRequest{
+		Messages:   buildMessages(opts),
+		Tools:      opts.Tools,
+		ToolChoice: "auto",
 	}
 
-	// 发送请求
-	completion, err := client.Chat.Completions.New(ctx, params)
+	// 丢弃增量事件，仅使用汇总结果
+	eventChan := make(chan StreamEvent)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range eventChan {
+		}
+	}()
+	result, opts, err := streamWithFailover(ctx, opts, req, eventChan)
+	close(eventChan)
+	<-done
 	if err != nil {
 		return nil, fmt.Errorf("failed to create completion: %w", err)
 	}
 
-	toolCalls := completion.Choices[0].Message.ToolCalls
-
-	if len(toolCalls) != 0 {
-		// If there is a was a function call, continue the conversation
-		params.Messages = append(params.Messages, completion.Choices[0].Message.ToParam())
-		for _, toolCall := range toolCalls {
-			if toolCall.Function.Name == "gen_single_choice_problem" {
-				// Extract the location from the function call arguments
-				var args map[string]interface{}
-				err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-				if err != nil {
-					panic(err)
-				}
-				location := args["topic"].(string)
-
-				questionData, err := opts.Tools[0].Handler(location)
-
-				fmt.Printf("gened question in %s\n", questionData)
-			}
-		}
-	}
-
-	// 构建响应
-	if len(completion.Choices) == 0 {
-		return nil, errors.New("no choices in response")
-	}
-
-	// 提取 reasoning_content
-	reasoningContent := ""
-	if _, exists := completion.Choices[0].Message.JSON.ExtraFields["reasoning_content"]; exists {
-		reasoningContent = completion.Choices[0].Message.JSON.ExtraFields["reasoning_content"].Raw()
-	}
-
 	return &CompletionResponse{
-		Content:          completion.Choices[0].Message.Content,
-		ReasoningContent: reasoningContent,
+		Content:          result.Content,
+		ReasoningContent: result.ReasoningContent,
 		Usage: CompletionUsage{
-			PromptTokens:     completion.Usage.PromptTokens,
-			CompletionTokens: completion.Usage.CompletionTokens,
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			ReasoningTokens:  result.Usage.ReasoningTokens,
 		},
+		ModelID:  opts.ModelID,
+		APIKeyID: opts.Provider.ApiKeyID,
 	}, nil
 }
 
@@ -110,10 +51,13 @@ type CompletionResponse struct {
 	Content          string          `json:"content"`
 	ReasoningContent string          `json:"reasoning_content"`
 	Usage            CompletionUsage `json:"usage"`
+	ModelID          uint64          `json:"-"` // 实际回答的模型 ID，用于记录用量
+	APIKeyID         uint64          `json:"-"` // 实际使用的 API Key ID，用于记录用量
 }
 
 // CompletionUsage 使用统计
 type CompletionUsage struct {
 	PromptTokens     int64 `json:"prompt_tokens"`
 	CompletionTokens int64 `json:"completion_tokens"`
+	ReasoningTokens  int64 `json:"reasoning_tokens"`
 }
@@ -2,14 +2,15 @@ package chat_utils
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/duke-git/lancet/v2/slice"
 	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
-	"github.com/openai/openai-go/packages/ssestream"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 )
 
 // CompletionStream 流式聊天
@@ -19,14 +20,16 @@ func CompletionStream(ctx context.Context, opts CompletionOptions, eventChan cha
 		return fmt.Errorf("invalid options: %w", err)
 	}
 
-	// 初始化客户端
-	client := openai.NewClient(option.WithBaseURL(opts.Provider.BaseUrl), option.WithAPIKey(opts.Provider.ApiKey))
+	// 校验提供商类型
+	if _, err := GetProviderAdapter(opts.Provider.Type); err != nil {
+		return err
+	}
 
 	// 构建请求消息
 	messages := buildMessages(opts)
 
 	// 启动协程处理流式请求
-	go processStreaming(ctx, &client, messages, opts, eventChan)
+	go processStreaming(ctx, messages, opts, eventChan)
 
 	return nil
 }
@@ -44,7 +47,7 @@ func validateOptions(opts CompletionOptions) error {
 
 // 消息预处理
 func buildMessages(opts CompletionOptions) []Message {
-	messages := make([]Message, 0, len(opts.Messages)+1)
+	messages := make([]Message, 0, len(opts.Messages)+2)
 
 	// 添加系统提示
 	if opts.SystemPrompt != "" && (len(opts.Messages) <= 0 || opts.Messages[0].Role != "system") {
@@ -56,185 +59,237 @@ func buildMessages(opts CompletionOptions) []Message {
 		)
 	}
 
+	// 添加记忆，位于系统提示之后、上下文之前
+	if opts.Memory != "" {
+		messages = append(messages, SystemMessage(opts.Memory))
+	}
+
 	// 添加上下文消息
 	messages = append(messages, opts.Messages...)
-	slog.Default().Info("messages: ", messages)
+	slog.Default().Debug("build messages", "count", len(messages))
 	return messages
 }
 
 // 流式处理核心逻辑
-func processStreaming(ctx context.Context, client *openai.Client, messages []Message, opts CompletionOptions, eventChan chan<- StreamEvent) {
+//
+// 模型返回工具调用时执行对应工具，并将调用及结果追加到消息列表后再次请求模型，
+// 直到模型给出最终回答或达到最大步数。首轮请求失败时按 opts.Fallbacks 进行故障转移
+func processStreaming(ctx context.Context, messages []Message, opts CompletionOptions, eventChan chan<- StreamEvent) {
 	defer close(eventChan) // 确保通道关闭
 
-	// 将消息转换为 OpenAI 的请求格式，ChatCompletionMessage 是 ChatCompletionMessageParamUnion 的特例
-	reqMessages := slice.Map(
-		messages, func(_ int, m Message) openai.ChatCompletionMessageParamUnion {
-			if m.Role == "user" {
-				return openai.UserMessage(m.Content)
-			} else {
-				return openai.AssistantMessage(m.Content)
-			}
-		},
-	)
-
-	availableTools := slice.Map(
-		opts.Tools, func(_ int, tool CompletionTool) openai.ChatCompletionToolParam {
-			return tool.Param
-		},
-	)
 	toolsMap := ConvertToolsToMap(opts.Tools)
-
-	// 构造参数，非必要不传递
-	params := openai.ChatCompletionNewParams{
-		Messages: reqMessages,
-		Model:    opts.Model,
-		StreamOptions: openai.ChatCompletionStreamOptionsParam{
-			IncludeUsage: openai.Opt(true),
-		},
-	}
-	if opts.Temperature > 0 {
-		params.Temperature = openai.Opt(opts.Temperature)
-	}
-	if opts.MaxTokens > 0 {
-		params.MaxTokens = openai.Opt(opts.MaxTokens)
+	maxToolSteps := opts.MaxToolSteps
+	if maxToolSteps <= 0 {
+		maxToolSteps = DefaultMaxToolSteps
 	}
-	if len(opts.Tools) > 0 {
-		params.Tools = availableTools
-		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{
-			OfAuto: openai.Opt("auto"),
-		}
-	}
-
-	// 获取提供商的流式响应
-	stream := client.Chat.Completions.NewStreaming(
-		ctx, params,
-	)
-	if stream.Err() != nil {
-		sendError(eventChan, fmt.Errorf("failed to create stream: %w", stream.Err()))
-		return
-	}
-	defer func(stream *ssestream.Stream[openai.ChatCompletionChunk]) {
-		err := stream.Close()
-		if err != nil {
 
-		}
-	}(stream)
-
-	acc := openai.ChatCompletionAccumulator{}
+	var finalContent strings.Builder
 	accReasoningContent := ""
-
 	replaceMsg := "" // 用于在输出结果为空时作为结果，通常在 tool_calls 时使用
 	extra := map[string]any{}
+	usage := DoneResponseUsage{}
+	var adapter ProviderAdapter
+
+	for step := 0; ; step++ {
+		req := StepRequest{
+			Messages:   messages,
+			Tools:      opts.Tools,
+			ToolChoice: "auto",
+		}
+		if step >= maxToolSteps {
+			// 达到最大步数，禁止继续调用工具，要求模型给出最终回答
+			req.ToolChoice = "none"
+		}
 
-	// 处理流式响应
-streamingLoop:
-	for {
-		select {
-		case <-ctx.Done():
-			sendError(eventChan, ctx.Err())
-			return
-		default:
-			// 接收下一个响应，若流式结束，退出循环
-			if !stream.Next() {
-				break streamingLoop
-			}
-			if stream.Err() != nil {
-				sendError(eventChan, fmt.Errorf("stream error: %w", stream.Err()))
-				return
-			}
-			chunk := stream.Current()
-			acc.AddChunk(chunk)
-
-			// 检测到一个完整的 tool call
-			if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason == "tool_calls" && len(acc.ChatCompletion.Choices[0].Message.ToolCalls) > 0 {
-				toolcall := acc.Choices[0].Message.ToolCalls[0]
-				tool, toolOk := toolsMap[toolcall.Function.Name]
-				if toolOk {
-					if tool.UserTip != "" {
-						eventChan <- StreamEvent{
-							Type:    CommandEventType,
-							Content: "tooltip",
-							Metadata: map[string]string{
-								"tooltip": tool.UserTip,
-							},
-						}
-					}
-					res, err := tool.Handler(toolcall.Function.Arguments)
-					if err != nil || res == nil {
-						eventChan <- StreamEvent{
-							Type:    ErrorEventType,
-							Content: "tool_error",
-							Error:   err,
-						}
-						return
-					}
-					replaceMsg = res.ReplaceMessage
-					if acc.Choices[0].Message.Content == "" {
-						// 发送 msg
-						eventChan <- StreamEvent{
-							Type:    ContentEventType,
-							Content: replaceMsg,
-						}
-					}
-					// 把函数处理结果存入 extra，可能被用于存入数据库
-					extra[res.Type] = res.Data
-					// 发送 cmd
-					eventChan <- StreamEvent{
-						Type:     CommandEventType,
-						Content:  "tool:" + res.Type,
-						Metadata: res.Data,
-					}
+		var result *StepResult
+		var err error
+		if step == 0 {
+			// 首轮确定回答的模型，后续轮次沿用
+			result, opts, err = streamWithFailover(ctx, opts, req, eventChan)
+			adapter, _ = GetProviderAdapter(opts.Provider.Type)
+		} else {
+			result, err = adapter.StreamStep(ctx, opts, req, eventChan)
+		}
+		if result != nil {
+			accReasoningContent += result.ReasoningContent
+			usage.PromptTokens += result.Usage.PromptTokens
+			usage.CompletionTokens += result.Usage.CompletionTokens
+			usage.ReasoningTokens += result.Usage.ReasoningTokens
+		}
+		if err != nil {
+			if usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+				// 已产生用量，随错误事件返回以便计费
+				if result != nil {
+					finalContent.WriteString(result.Content)
+				}
+				eventChan <- StreamEvent{
+					Type:  ErrorEventType,
+					Error: err,
+					Metadata: DoneResponse{
+						Content:          finalContent.String(),
+						ReasoningContent: accReasoningContent,
+						Usage:            usage,
+						ModelID:          opts.ModelID,
+						APIKeyID:         opts.Provider.ApiKeyID,
+					},
 				}
-				println(
-					"Tool call stream finished:",
-					toolcall.ID,
-					toolcall.Type,
-					toolcall.Function.Name,
-					toolcall.Function.Arguments,
-				)
+				return
 			}
+			sendError(eventChan, err)
+			return
+		}
+		finalContent.WriteString(result.Content)
+		if len(result.ToolCalls) == 0 || step >= maxToolSteps {
+			break
+		}
 
-			// 额外解析含有 reasoning_content 的结构
-			if len(chunk.Choices) == 0 {
-				continue
-			}
-			choiceDelta := &chunk.Choices[0].Delta
-			// 发送内容事件
-			if reasoningContent := choiceDelta.JSON.ExtraFields["reasoning_content"].Raw(); reasoningContent != "" && reasoningContent != "null" {
-				reasoningContent, _ = strings.CutPrefix(reasoningContent, "\"")
-				reasoningContent, _ = strings.CutSuffix(reasoningContent, "\"")
-				accReasoningContent += reasoningContent
-				eventChan <- StreamEvent{
-					Type:    ReasoningContentEventType,
-					Content: reasoningContent,
+		// 执行本轮的全部工具调用，并将结果回传给模型
+		messages = append(
+			messages, Message{
+				Role:      "assistant",
+				Content:   result.Content,
+				ToolCalls: result.ToolCalls,
+			},
+		)
+		results := runToolCalls(step, result.ToolCalls, toolsMap, eventChan)
+		for i, toolResult := range results {
+			if toolResult.Return != nil {
+				if toolResult.Return.ReplaceMessage != "" {
+					replaceMsg = toolResult.Return.ReplaceMessage
 				}
-			} else {
-				eventChan <- StreamEvent{
-					Type:    ContentEventType,
-					Content: choiceDelta.Content,
+				// 把函数处理结果存入 extra，可能被用于存入数据库
+				if toolResult.Return.Type != "" {
+					extra[toolResult.Return.Type] = toolResult.Return.Data
 				}
 			}
+			messages = append(
+				messages, Message{
+					Role:       "tool",
+					Content:    toolResult.Content,
+					ToolCallID: result.ToolCalls[i].ID,
+				},
+			)
 		}
 	}
 
-	var finalContent string
-	if len(acc.Choices) > 0 && acc.Choices[0].Message.Content != "" {
-		finalContent = acc.Choices[0].Message.Content
-	} else {
-		finalContent = replaceMsg
+	if finalContent.Len() == 0 && replaceMsg != "" {
+		// 模型没有回复，使用工具提供的替代回复
+		finalContent.WriteString(replaceMsg)
+		eventChan <- StreamEvent{
+			Type:    ContentEventType,
+			Content: replaceMsg,
+		}
 	}
 	// 发送完成事件
 	eventChan <- StreamEvent{
 		Type: DoneEventType, Metadata: DoneResponse{
-			Content:          finalContent,
+			Content:          finalContent.String(),
 			ReasoningContent: accReasoningContent,
 			Extra:            extra,
-			Usage: DoneResponseUsage{
-				PromptTokens:     acc.Usage.PromptTokens,
-				CompletionTokens: acc.Usage.CompletionTokens,
-			},
+			Usage:            usage,
+			ModelID:          opts.ModelID,
+			APIKeyID:         opts.Provider.ApiKeyID,
+		},
+	}
+}
+
+// toolCallResult 单个工具调用的执行结果
+type toolCallResult struct {
+	Return  *CompletionToolHandlerReturn
+	Content string // 回传给模型的结果
+}
+
+// runToolCalls 并行执行同一轮中的多个工具调用，结果顺序与调用顺序一致
+func runToolCalls(step int, toolCalls []ToolCall, toolsMap map[string]CompletionTool, eventChan chan<- StreamEvent) []toolCallResult {
+	// 发送 cmd：本轮调用的工具
+	eventChan <- StreamEvent{
+		Type:    CommandEventType,
+		Content: "tool_calls",
+		Metadata: map[string]any{
+			"step": step,
+			"calls": slice.Map(
+				toolCalls, func(_ int, toolCall ToolCall) map[string]string {
+					return map[string]string{
+						"id":        toolCall.ID,
+						"name":      toolCall.Name,
+						"arguments": toolCall.Arguments,
+					}
+				},
+			),
 		},
 	}
+
+	results := make([]toolCallResult, len(toolCalls))
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		go func(i int, toolCall ToolCall) {
+			defer wg.Done()
+			results[i] = runToolCall(step, toolCall, toolsMap, eventChan)
+		}(i, toolCall)
+	}
+	wg.Wait()
+	return results
+}
+
+// runToolCall 执行单个工具调用，出错时将错误信息回传给模型而不是中断对话
+func runToolCall(step int, toolCall ToolCall, toolsMap map[string]CompletionTool, eventChan chan<- StreamEvent) toolCallResult {
+	sendResult := func(errMsg string) {
+		metadata := map[string]any{
+			"step": step,
+			"id":   toolCall.ID,
+			"name": toolCall.Name,
+		}
+		if errMsg != "" {
+			metadata["error"] = errMsg
+		}
+		eventChan <- StreamEvent{
+			Type:     CommandEventType,
+			Content:  "tool_result",
+			Metadata: metadata,
+		}
+	}
+	errorResult := func(errMsg string) toolCallResult {
+		sendResult(errMsg)
+		content, _ := json.Marshal(map[string]string{"error": errMsg})
+		return toolCallResult{Content: string(content)}
+	}
+
+	tool, ok := toolsMap[toolCall.Name]
+	if !ok {
+		return errorResult("tool not found: " + toolCall.Name)
+	}
+	if tool.UserTip != "" {
+		eventChan <- StreamEvent{
+			Type:    CommandEventType,
+			Content: "tooltip",
+			Metadata: map[string]string{
+				"tooltip": tool.UserTip,
+			},
+		}
+	}
+	res, err := tool.Handler(toolCall.Arguments)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	if res == nil {
+		return errorResult("tool returned no result")
+	}
+
+	content, err := json.Marshal(res.Data)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	if res.Type != "" {
+		// 发送 cmd：工具返回的数据
+		eventChan <- StreamEvent{
+			Type:     CommandEventType,
+			Content:  "tool:" + res.Type,
+			Metadata: res.Data,
+		}
+	}
+	sendResult("")
+	return toolCallResult{Return: res, Content: string(content)}
 }
 
 // 发送错误事件
@@ -247,14 +302,31 @@ func sendError(ch chan<- StreamEvent, err error) {
 
 // Provider 提供商信息
 type Provider struct {
-	BaseUrl string
-	ApiKey  string
+	Type     string // 协议类型，见 ProviderType* 常量，为空时使用 OpenAI 格式
+	BaseUrl  string
+	ApiKey   string
+	ApiKeyID uint64 // API Key ID，用于记录密钥的健康状态
 }
 
 // Message 消息结构体
 type Message struct {
-	Role    string
-	Content string
+	Role        string
+	Content     string
+	Attachments []Attachment // 附件，仅 user 消息有效
+	ToolCalls   []ToolCall   // 模型发起的工具调用，仅 assistant 消息有效
+	ToolCallID  string       // 对应的工具调用 ID，仅 tool 消息有效
+}
+
+// Attachment 消息附件
+type Attachment struct {
+	Name     string // 文件名
+	MimeType string // 文件类型，如 image/png
+	URL      string // 访问链接，可以是预签名链接或 data URL
+}
+
+// IsImage 是否为图片附件
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MimeType, "image/")
 }
 
 func SystemMessage(content string) Message {
@@ -291,7 +363,7 @@ type StreamEvent struct {
 	Type     StreamEventType // 事件类型：content/error/done
 	Content  string          // 内容（当 Type=content 时有效）
 	Error    error           // 错误对象（当 Type=error 时有效）
-	Metadata interface{}     // 附加元数据
+	Metadata interface{}     // 附加元数据，error 事件在出错前已产生用量时为 DoneResponse
 }
 
 // CompletionOptions 流式请求配置
@@ -300,15 +372,25 @@ type CompletionOptions struct {
 	Model        string    // 模型名称
 	Messages     []Message // 消息列表
 	SystemPrompt string    // 系统提示词
+	Memory       string    // 记忆（如早期对话摘要），以系统消息的形式插入在系统提示之后
 	CompletionModelConfig
 
-	Tools []CompletionTool // 工具列表
+	ModelID   uint64             // 模型 ID，在 model 命令中告知客户端
+	Fallbacks []CompletionTarget // 备用模型，首轮请求在输出任何内容前失败时依次尝试
+	// OnAttempt 首轮请求每次尝试结束后回调，latency 为首个响应的延迟，可用于统计模型的可用性
+	OnAttempt func(target CompletionTarget, latency time.Duration, err error)
+
+	Tools        []CompletionTool // 工具列表
+	MaxToolSteps int              // 工具调用的最大轮数，0 表示使用 DefaultMaxToolSteps
 }
 
+// DefaultMaxToolSteps 默认的工具调用最大轮数
+const DefaultMaxToolSteps = 5
+
 type CompletionToolHandlerReturn struct {
 	Data           interface{}
 	ReplaceMessage string // 调用工具可能模型没有回复，此时使用替代回复
-	Type           string // TODO: 支持区分回发 command / 继续请求对话 等等
+	Type           string // 结果类型，非空时以 tool:<Type> 命令发送给客户端并存入消息 extra
 }
 
 type CompletionTool struct {
@@ -328,8 +410,12 @@ type DoneResponse struct {
 	ReasoningContent string            `json:"reasoning_content"`
 	Extra            map[string]any    `json:"extra"`
 	Usage            DoneResponseUsage `json:"usage"`
+	ModelID          uint64            `json:"-"` // 实际回答的模型 ID，用于记录用量
+	APIKeyID         uint64            `json:"-"` // 实际使用的 API Key ID，用于记录用量
 }
 type DoneResponseUsage struct {
-	PromptTokens     int64 `json:"prompt_tokens"`
-	CompletionTokens int64 `json:"completion_tokens"`
+	PromptTokens          int64 `json:"prompt_tokens"`
+	CompletionTokens      int64 `json:"completion_tokens"`       // 包含思考内容的 token 数
+	ReasoningTokens       int64 `json:"reasoning_tokens"`        // 思考内容的 token 数，提供商未返回时为 0
+	EstimatedPromptTokens int64 `json:"estimated_prompt_tokens"` // 组装上下文时估算的 prompt token 数
 }
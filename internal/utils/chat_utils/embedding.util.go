@@ -0,0 +1,220 @@
+package chat_utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// EmbeddingAdapter 提供商向量化协议适配器
+type EmbeddingAdapter interface {
+	// Embed 向量化一批文本，返回的向量与输入顺序一致，文本数量不超过 MaxEmbeddingBatch
+	Embed(ctx context.Context, target EmbeddingTarget, texts []string) (*EmbeddingResult, error)
+	// MaxEmbeddingBatch 提供商单次请求允许的最大文本数
+	MaxEmbeddingBatch() int
+}
+
+// EmbeddingResult 一次向量化请求的结果
+type EmbeddingResult struct {
+	Embeddings   [][]float32
+	PromptTokens int64 // 提供商未返回时为估算值
+}
+
+var embeddingAdapters sync.Map // 协议类型 -> EmbeddingAdapter
+
+func init() {
+	RegisterEmbeddingAdapter(ProviderTypeOpenAI, &openAIAdapter{})
+	RegisterEmbeddingAdapter(ProviderTypeGemini, &geminiAdapter{})
+	RegisterEmbeddingAdapter(ProviderTypeOllama, &ollamaAdapter{})
+}
+
+// RegisterEmbeddingAdapter 注册提供商向量化协议适配器，同名覆盖
+func RegisterEmbeddingAdapter(providerType string, adapter EmbeddingAdapter) {
+	embeddingAdapters.Store(providerType, adapter)
+}
+
+// GetEmbeddingAdapter 获取提供商向量化协议适配器，类型为空时使用 OpenAI 格式
+func GetEmbeddingAdapter(providerType string) (EmbeddingAdapter, error) {
+	if providerType == "" {
+		providerType = ProviderTypeOpenAI
+	}
+	adapter, ok := embeddingAdapters.Load(providerType)
+	if !ok {
+		return nil, fmt.Errorf("embeddings not supported by provider type: %s", providerType)
+	}
+	return adapter.(EmbeddingAdapter), nil
+}
+
+// EmbeddingTarget 可用于向量化的模型，用于故障转移
+type EmbeddingTarget struct {
+	ModelID    uint64   // 模型 ID
+	Model      string   // 模型名称
+	Provider   Provider // 服务提供商
+	Dimensions int64    // 输出维度，0 表示使用模型默认维度，仅部分模型支持
+	BatchSize  int      // 单次请求的最大文本数，0 表示使用提供商的上限
+}
+
+// cacheNamespace 缓存的命名空间，不同模型或维度的向量不可混用
+func (t EmbeddingTarget) cacheNamespace() string {
+	if t.Dimensions > 0 {
+		return fmt.Sprintf("%s:%d", t.Model, t.Dimensions)
+	}
+	return t.Model
+}
+
+// EmbeddingCache 向量缓存，按模型命名空间及文本内容哈希存取
+type EmbeddingCache interface {
+	// GetEmbeddings 批量读取缓存，未命中的哈希不出现在结果中
+	GetEmbeddings(ctx context.Context, namespace string, hashes []string) (map[string][]float32, error)
+	// SetEmbeddings 批量写入缓存
+	SetEmbeddings(ctx context.Context, namespace string, embeddings map[string][]float32) error
+}
+
+// EmbeddingOptions 向量化请求配置
+type EmbeddingOptions struct {
+	EmbeddingTarget
+	Input []string // 待向量化的文本
+
+	Fallbacks []EmbeddingTarget // 备用模型，请求失败时依次尝试
+	// OnAttempt 每个模型尝试结束后回调，latency 为该模型全部请求的耗时，可用于统计模型的可用性
+	OnAttempt func(target EmbeddingTarget, latency time.Duration, err error)
+	Cache     EmbeddingCache // 向量缓存，为 nil 时不使用缓存
+}
+
+// EmbeddingResponse 向量化响应
+type EmbeddingResponse struct {
+	Embeddings   [][]float32 // 与输入顺序一致
+	PromptTokens int64       // 实际请求的 token 数，不含缓存命中的文本
+	Cached       int         // 缓存命中的文本数
+	ModelID      uint64      // 实际使用的模型 ID，用于记录用量
+	APIKeyID     uint64      // 实际使用的 API Key ID，用于记录用量
+}
+
+// EmbeddingHash 文本内容的哈希，作为向量缓存的键
+func EmbeddingHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Embeddings 向量化文本
+//
+// 相同内容的文本只请求一次，已缓存的文本不再请求；未缓存的文本按提供商的上限分批请求。
+// 同一次调用的全部向量来自同一模型，模型失败时整体切换到备用模型
+func Embeddings(ctx context.Context, opts EmbeddingOptions) (*EmbeddingResponse, error) {
+	if len(opts.Input) == 0 {
+		return &EmbeddingResponse{ModelID: opts.ModelID, APIKeyID: opts.Provider.ApiKeyID}, nil
+	}
+	if opts.Model == "" {
+		return nil, fmt.Errorf("invalid options: model is required")
+	}
+
+	var lastErr error
+	for _, target := range append([]EmbeddingTarget{opts.EmbeddingTarget}, opts.Fallbacks...) {
+		start := time.Now()
+		resp, err := embedWithTarget(ctx, target, opts)
+		if opts.OnAttempt != nil {
+			opts.OnAttempt(target, time.Since(start), err)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !IsRetryableError(err) || ctx.Err() != nil {
+			break
+		}
+		slog.Default().Warn("embedding failed, trying fallback", "model", target.Model, "error", err.Error())
+	}
+	return nil, fmt.Errorf("failed to create embeddings: %w", lastErr)
+}
+
+// embedWithTarget 使用指定模型向量化全部文本
+func embedWithTarget(ctx context.Context, target EmbeddingTarget, opts EmbeddingOptions) (*EmbeddingResponse, error) {
+	adapter, err := GetEmbeddingAdapter(target.Provider.Type)
+	if err != nil {
+		return nil, err
+	}
+	namespace := target.cacheNamespace()
+
+	// 按内容去重
+	hashes := make([]string, len(opts.Input))
+	texts := make(map[string]string, len(opts.Input))
+	var unique []string
+	for i, text := range opts.Input {
+		hashes[i] = EmbeddingHash(text)
+		if _, ok := texts[hashes[i]]; !ok {
+			texts[hashes[i]] = text
+			unique = append(unique, hashes[i])
+		}
+	}
+
+	vectors := make(map[string][]float32, len(unique))
+	if opts.Cache != nil {
+		cached, err := opts.Cache.GetEmbeddings(ctx, namespace, unique)
+		if err != nil {
+			slog.Default().Warn("failed to read embedding cache", "error", err.Error())
+		}
+		for hash, vec := range cached {
+			vectors[hash] = vec
+		}
+	}
+	var missing []string
+	for _, hash := range unique {
+		if _, ok := vectors[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+
+	resp := &EmbeddingResponse{
+		Cached:   len(unique) - len(missing),
+		ModelID:  target.ModelID,
+		APIKeyID: target.Provider.ApiKeyID,
+	}
+	batchSize := adapter.MaxEmbeddingBatch()
+	if target.BatchSize > 0 && target.BatchSize < batchSize {
+		batchSize = target.BatchSize
+	}
+	created := make(map[string][]float32, len(missing))
+	for start := 0; start < len(missing); start += batchSize {
+		batch := missing[start:min(start+batchSize, len(missing))]
+		batchTexts := make([]string, len(batch))
+		for i, hash := range batch {
+			batchTexts[i] = texts[hash]
+		}
+		result, err := adapter.Embed(ctx, target, batchTexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("embedding count mismatch: expected %d, got %d", len(batch), len(result.Embeddings))
+		}
+		for i, hash := range batch {
+			created[hash] = result.Embeddings[i]
+			vectors[hash] = result.Embeddings[i]
+		}
+		resp.PromptTokens += result.PromptTokens
+	}
+	if opts.Cache != nil && len(created) > 0 {
+		if err := opts.Cache.SetEmbeddings(ctx, namespace, created); err != nil {
+			slog.Default().Warn("failed to write embedding cache", "error", err.Error())
+		}
+	}
+
+	resp.Embeddings = make([][]float32, len(hashes))
+	for i, hash := range hashes {
+		resp.Embeddings[i] = vectors[hash]
+	}
+	return resp, nil
+}
+
+// estimateEmbeddingTokens 提供商未返回用量时估算 token 数
+func estimateEmbeddingTokens(texts []string) int64 {
+	var tokens int64
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+	return tokens
+}
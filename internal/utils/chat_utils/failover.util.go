@@ -0,0 +1,155 @@
+package chat_utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// CompletionTarget 可用于补全的模型，用于故障转移
+type CompletionTarget struct {
+	ModelID  uint64   // 模型 ID
+	Model    string   // 模型名称
+	Provider Provider // 服务提供商
+	CompletionModelConfig
+}
+
+// target 当前配置对应的补全目标
+func (opts CompletionOptions) target() CompletionTarget {
+	return CompletionTarget{
+		ModelID:               opts.ModelID,
+		Model:                 opts.Model,
+		Provider:              opts.Provider,
+		CompletionModelConfig: opts.CompletionModelConfig,
+	}
+}
+
+// withTarget 使用指定的补全目标替换模型相关配置
+func (opts CompletionOptions) withTarget(target CompletionTarget) CompletionOptions {
+	opts.ModelID = target.ModelID
+	opts.Model = target.Model
+	opts.Provider = target.Provider
+	opts.CompletionModelConfig = target.CompletionModelConfig
+	return opts
+}
+
+// IsRetryableError 是否为可以切换模型重试的错误：连接错误、5xx 及 429
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if statusCode := ErrorStatusCode(err); statusCode != 0 {
+		return isRetryableStatus(statusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// ErrorStatusCode 获取提供商返回的 HTTP 状态码，非 HTTP 错误返回 0
+func ErrorStatusCode(err error) int {
+	var httpErr *ProviderHTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsTimeoutError 是否为请求超时
+func IsTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// streamWithFailover 执行首轮请求，若在输出任何内容前以可重试的错误失败，依次切换到备用模型
+//
+// 确定回答的模型后发送 model 命令，后续轮次应使用返回的配置
+//
+//	Returns:
+//		- *StepResult: 本轮结果
+//		- CompletionOptions: 实际使用的配置
+//		- error: 最后一次尝试的错误
+func streamWithFailover(ctx context.Context, opts CompletionOptions, req StepRequest, eventChan chan<- StreamEvent) (*StepResult, CompletionOptions, error) {
+	targets := append([]CompletionTarget{opts.target()}, opts.Fallbacks...)
+	var result *StepResult
+	var err error
+	for i, target := range targets {
+		attemptOpts := opts.withTarget(target)
+		var adapter ProviderAdapter
+		adapter, err = GetProviderAdapter(target.Provider.Type)
+		if err != nil {
+			continue
+		}
+
+		// 转发本次尝试的事件，在首个事件前发送 model 命令，并记录是否已有输出及首个响应的延迟
+		start := time.Now()
+		var latency time.Duration
+		attemptChan := make(chan StreamEvent)
+		emittedChan := make(chan bool)
+		go func() {
+			emitted := false
+			for event := range attemptChan {
+				if !emitted {
+					emitted = true
+					latency = time.Since(start)
+					eventChan <- modelCommandEvent(target, i+1)
+				}
+				eventChan <- event
+			}
+			emittedChan <- emitted
+		}()
+		result, err = adapter.StreamStep(ctx, attemptOpts, req, attemptChan)
+		close(attemptChan)
+		emitted := <-emittedChan
+		if !emitted {
+			latency = time.Since(start)
+		}
+		if opts.OnAttempt != nil && !errors.Is(err, context.Canceled) {
+			opts.OnAttempt(target, latency, err)
+		}
+
+		if err == nil {
+			if !emitted {
+				eventChan <- modelCommandEvent(target, i+1)
+			}
+			return result, attemptOpts, nil
+		}
+		if emitted || !IsRetryableError(err) || i == len(targets)-1 {
+			return result, attemptOpts, err
+		}
+		slog.Default().Warn(
+			"completion failed, switching to fallback model",
+			"model", target.Model, "fallback", targets[i+1].Model, "error", err.Error(),
+		)
+	}
+	return result, opts, err
+}
+
+// modelCommandEvent 告知客户端实际回答的模型
+func modelCommandEvent(target CompletionTarget, attempts int) StreamEvent {
+	return StreamEvent{
+		Type:    CommandEventType,
+		Content: "model",
+		Metadata: map[string]any{
+			"model_id": target.ModelID,
+			"model":    target.Model,
+			"attempts": attempts,
+		},
+	}
+}
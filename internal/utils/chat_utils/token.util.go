@@ -0,0 +1,111 @@
+package chat_utils
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+const (
+	messageTokenOverhead    = 4    // 每条消息的角色等格式开销
+	replyTokenOverhead      = 3    // 回复的起始标记开销
+	attachmentTokenEstimate = 1000 // 每个附件的估算 token 数
+)
+
+// tokenizer 已加载的分词器，加载完成前为 nil
+var tokenizer atomic.Pointer[tiktoken.Tiktoken]
+
+// InitTokenizer 加载分词器（cl100k_base），首次加载需要下载词表，应在后台执行
+//
+// 加载失败或完成前，token 计数回退到按字符估算
+func InitTokenizer() {
+	encoding, err := tiktoken.GetEncoding(tiktoken.MODEL_CL100K_BASE)
+	if err != nil {
+		slog.Default().Warn("failed to load tokenizer, fallback to estimation", "error", err.Error())
+		return
+	}
+	tokenizer.Store(encoding)
+}
+
+// EstimateTokens 计算文本的 token 数，分词器不可用时粗略估算
+//
+// 估算规则：CJK 字符按每字 1 token 计，其余字符按每 4 个 1 token 计
+func EstimateTokens(text string) int64 {
+	if text == "" {
+		return 0
+	}
+	if encoding := tokenizer.Load(); encoding != nil {
+		return int64(len(encoding.EncodeOrdinary(text)))
+	}
+	var cjk, others int64
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// EstimateMessageTokens 计算单条消息的 token 数
+func EstimateMessageTokens(message Message) int64 {
+	return EstimateTokens(message.Content) +
+		int64(len(message.Attachments))*attachmentTokenEstimate +
+		messageTokenOverhead
+}
+
+// EstimateMessagesTokens 计算消息列表的 token 数
+func EstimateMessagesTokens(messages []Message) int64 {
+	var total int64
+	for _, m := range messages {
+		total += EstimateMessageTokens(m)
+	}
+	return total
+}
+
+// ContextBudget 上下文窗口预算
+type ContextBudget struct {
+	ContextLength int64 // 模型上下文长度
+	ReserveTokens int64 // 为回复预留的 token 数，通常为 MaxTokens
+	ExtraTokens   int64 // 为裁剪后才注入的内容（知识库、链接、搜索结果等）预留的 token 数
+}
+
+// FitContext 在预算内尽可能保留最近的历史消息
+//
+//	Parameters:
+//		- budget: 上下文窗口预算
+//		- systemPrompt: 系统提示词
+//		- fixed: 必须保留的消息，如预设消息
+//		- history: 历史消息，按时间正序
+//		- question: 本次提问
+//	Returns:
+//		- []Message: 裁剪后的历史消息（丢弃最早的部分）
+//		- int64: 裁剪后整个请求的 prompt token 估算值
+func FitContext(budget ContextBudget, systemPrompt string, fixed []Message, history []Message, question Message) ([]Message, int64) {
+	promptTokens := EstimateMessagesTokens(fixed) + EstimateMessageTokens(question) + replyTokenOverhead
+	if systemPrompt != "" {
+		promptTokens += EstimateMessageTokens(SystemMessage(systemPrompt))
+	}
+
+	available := budget.ContextLength - budget.ReserveTokens - budget.ExtraTokens - promptTokens
+	if budget.ContextLength <= 0 {
+		// 未知上下文长度，不裁剪
+		return history, promptTokens + EstimateMessagesTokens(history)
+	}
+
+	// 从最新的消息开始向前累加，直到超出预算
+	start := len(history)
+	for start > 0 {
+		tokens := EstimateMessageTokens(history[start-1])
+		if tokens > available {
+			break
+		}
+		available -= tokens
+		promptTokens += tokens
+		start--
+	}
+	return history[start:], promptTokens
+}
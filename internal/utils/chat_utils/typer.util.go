@@ -6,21 +6,46 @@ import (
 	"github.com/fcraft/open-chat/internal/schema"
 )
 
-// GetCommonCompletionOptions returns the common completion options for the given provider model.
-func GetCommonCompletionOptions(providerModel schema.Model, options CompletionOptions) CompletionOptions {
+// GetCommonCompletionOptions returns the common completion options for the given provider model and api key.
+func GetCommonCompletionOptions(providerModel schema.Model, apiKey schema.APIKey, options CompletionOptions) CompletionOptions {
 	completionOptions := CompletionOptions{}
 	err := convertor.CopyProperties(&completionOptions, options)
-	if err != nil || providerModel.Provider == nil || len(providerModel.Provider.APIKeys) < 1 {
+	if err != nil || providerModel.Provider == nil {
 		return CompletionOptions{}
 	}
-	completionOptions.Provider = Provider{
-		BaseUrl: providerModel.Provider.BaseURL,
-		ApiKey:  providerModel.Provider.APIKeys[0].Key,
-	}
-	completionOptions.Model = providerModel.Name
+	target := GetCompletionTarget(providerModel, apiKey)
+	completionOptions.Provider = target.Provider
+	completionOptions.ModelID = target.ModelID
+	completionOptions.Model = target.Model
 	return completionOptions
 }
 
+// GetCompletionTarget 将模型及其提供商的 API Key 转换为补全目标，providerModel.Provider 不能为空
+func GetCompletionTarget(providerModel schema.Model, apiKey schema.APIKey) CompletionTarget {
+	return CompletionTarget{
+		ModelID: providerModel.ID,
+		Model:   providerModel.Name,
+		Provider: Provider{
+			Type:     providerModel.Provider.Type,
+			BaseUrl:  providerModel.Provider.BaseURL,
+			ApiKey:   apiKey.Key,
+			ApiKeyID: apiKey.ID,
+		},
+	}
+}
+
+// GetEmbeddingTarget 将向量化模型及其提供商的 API Key 转换为向量化目标，providerModel.Provider 不能为空
+func GetEmbeddingTarget(providerModel schema.Model, apiKey schema.APIKey) EmbeddingTarget {
+	target := GetCompletionTarget(providerModel, apiKey)
+	return EmbeddingTarget{
+		ModelID:    target.ModelID,
+		Model:      target.Model,
+		Provider:   target.Provider,
+		Dimensions: providerModel.Config.EmbeddingDimensions,
+		BatchSize:  providerModel.Config.EmbeddingBatchSize,
+	}
+}
+
 // ConvertMessagesToSchema 将 chat_utils.Message 转换为 schema.Message
 //
 //	Parameters:
@@ -0,0 +1,166 @@
+package fetch_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Document 从网址提取的正文
+type Document struct {
+	URL         string    `json:"url"`          // 最终地址（跟随重定向后）
+	Title       string    `json:"title"`        // 标题
+	Content     string    `json:"content"`      // 正文纯文本
+	ContentType string    `json:"content_type"` // 响应的 MIME 类型
+	Truncated   bool      `json:"truncated"`    // 响应超过大小限制被截断
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// FetchOptions 下载限制
+type FetchOptions struct {
+	MaxBytes     int64                  // 响应体最大字节数，超出部分丢弃
+	Timeout      time.Duration          // 整个请求的超时时间
+	MaxRedirects int                    // 最大重定向次数
+	AllowHost    func(host string) bool // 域名策略，每次重定向均会检查，为 nil 时不限制
+}
+
+var (
+	ErrHostNotAllowed     = errors.New("host not allowed")
+	ErrUnsupportedScheme  = errors.New("only http and https urls are supported")
+	ErrUnsupportedContent = errors.New("unsupported content type")
+	ErrPrivateAddress     = errors.New("refusing to connect to a private address")
+)
+
+// safeDialer 拒绝连接回环、内网及链路本地地址，防止通过链接访问内部服务（含 DNS 解析到内网的情况）
+var safeDialer = &net.Dialer{
+	Timeout: 5 * time.Second,
+	Control: func(_, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+			ip.IsUnspecified() || ip.IsMulticast() {
+			return ErrPrivateAddress
+		}
+		return nil
+	},
+}
+
+var transport = &http.Transport{
+	Proxy:                 nil,
+	DialContext:           safeDialer.DialContext,
+	TLSHandshakeTimeout:   5 * time.Second,
+	ResponseHeaderTimeout: 10 * time.Second,
+	MaxIdleConns:          10,
+	IdleConnTimeout:       30 * time.Second,
+}
+
+// NewSafeClient 创建拒绝连接内网地址的 HTTP 客户端，仅允许 http(s) 协议，用于下载用户提供的链接
+func NewSafeClient(timeout time.Duration, maxRedirects int) *http.Client {
+	client := newClient(FetchOptions{MaxRedirects: maxRedirects})
+	client.Timeout = timeout
+	return client
+}
+
+// newClient 创建使用 safeDialer 的客户端，每次重定向均校验协议及域名策略
+func newClient(opts FetchOptions) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return errors.New("too many redirects")
+			}
+			return checkURL(req.URL, opts)
+		},
+	}
+}
+
+// checkURL 校验协议及域名策略
+func checkURL(u *url.URL, opts FetchOptions) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+	if opts.AllowHost != nil && !opts.AllowHost(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// Fetch 下载网址并提取正文，支持 HTML、纯文本及 PDF
+func Fetch(ctx context.Context, rawURL string, opts FetchOptions) (*Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkURL(u, opts); err != nil {
+		return nil, err
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	client := newClient(opts)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; OpenChatFetcher/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/pdf,text/plain;q=0.9,*/*;q=0.5")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	// 多读 1 字节用于判断是否截断
+	body, err := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{
+		URL:       resp.Request.URL.String(),
+		FetchedAt: time.Now(),
+	}
+	if int64(len(body)) > opts.MaxBytes {
+		body = body[:opts.MaxBytes]
+		doc.Truncated = true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	doc.ContentType = mediaType
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc.Title, doc.Content, err = ExtractHTML(body)
+	case mediaType == "application/pdf":
+		doc.Content, err = ExtractPDF(body)
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json":
+		doc.Content = strings.TrimSpace(string(body))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContent, mediaType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc.Title == "" {
+		doc.Title = u.Hostname() + u.Path
+	}
+	return doc, nil
+}
@@ -0,0 +1,209 @@
+package fetch_utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 不属于正文的元素，连同子元素一起忽略
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Select: true, atom.Svg: true,
+	atom.Iframe: true, atom.Canvas: true, atom.Object: true, atom.Embed: true,
+}
+
+// 块级元素，渲染文本时前后换行
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Pre: true, atom.Blockquote: true,
+	atom.Table: true, atom.Tr: true, atom.Br: true, atom.Hr: true, atom.Figcaption: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true,
+}
+
+// 类名或 ID 包含这些词的元素通常是评论、广告、侧栏等
+var unlikelyPattern = regexp.MustCompile(`(?i)comment|sidebar|footer|header|menu|nav|advert|\bads?\b|banner|share|social|related|popup|cookie|breadcrumb`)
+
+var blankLinePattern = regexp.MustCompile(`\n{3,}`)
+
+// ExtractHTML 提取 HTML 页面的标题及可读正文
+//
+// 优先使用 <article>/<main>；否则按简化的 Readability 算法，选出段落文本最多的容器元素
+func ExtractHTML(body []byte) (title string, content string, err error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", "", err
+	}
+	title = extractTitle(doc)
+
+	root := findFirst(doc, atom.Article)
+	if root == nil {
+		root = findFirst(doc, atom.Main)
+	}
+	if root == nil {
+		root = findBestContainer(doc)
+	}
+	if root == nil {
+		if root = findFirst(doc, atom.Body); root == nil {
+			root = doc
+		}
+	}
+
+	var sb strings.Builder
+	renderText(root, &sb)
+	content = strings.TrimSpace(blankLinePattern.ReplaceAllString(sb.String(), "\n\n"))
+	return title, content, nil
+}
+
+// extractTitle 优先使用 og:title，其次 <title>
+func extractTitle(doc *html.Node) string {
+	var title, ogTitle string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if title == "" && n.FirstChild != nil {
+					title = strings.TrimSpace(n.FirstChild.Data)
+				}
+			case atom.Meta:
+				if attr(n, "property") == "og:title" {
+					ogTitle = strings.TrimSpace(attr(n, "content"))
+				}
+			case atom.Body:
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if ogTitle != "" {
+		return ogTitle
+	}
+	return title
+}
+
+// findBestContainer 为每个段落的父元素累加段落文本长度（祖父元素计一半），返回得分最高的元素
+func findBestContainer(doc *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if skippedElements[n.DataAtom] || isUnlikely(n) {
+				return
+			}
+			if n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Blockquote {
+				text := textOf(n)
+				length := float64(len([]rune(strings.TrimSpace(text))))
+				if length >= 25 {
+					// 段落基础分 1，逗号越多、文本越长得分越高
+					score := 1 + float64(strings.Count(text, "，")+strings.Count(text, ", ")) + min(length/100, 3)
+					if parent := n.Parent; parent != nil {
+						scores[parent] += score
+						if grand := parent.Parent; grand != nil {
+							scores[grand] += score / 2
+						}
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var best *html.Node
+	var bestScore float64
+	for node, score := range scores {
+		if score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// isUnlikely 根据类名及 ID 判断元素是否不属于正文
+func isUnlikely(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	hint := attr(n, "class") + " " + attr(n, "id")
+	return strings.TrimSpace(hint) != "" && unlikelyPattern.MatchString(hint)
+}
+
+// renderText 渲染元素的文本，块级元素之间换行
+func renderText(n *html.Node, sb *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text != "" {
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") && !strings.HasSuffix(sb.String(), " ") {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(text)
+		}
+		return
+	case html.ElementNode:
+		if skippedElements[n.DataAtom] || isUnlikely(n) {
+			return
+		}
+		if n.DataAtom == atom.Pre {
+			sb.WriteString("\n" + textOf(n) + "\n")
+			return
+		}
+	}
+	block := n.Type == html.ElementNode && blockElements[n.DataAtom]
+	if block {
+		sb.WriteString("\n")
+		if n.DataAtom == atom.Li {
+			sb.WriteString("- ")
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderText(c, sb)
+	}
+	if block {
+		sb.WriteString("\n")
+	}
+}
+
+// textOf 元素内的全部原始文本
+func textOf(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textOf(c))
+	}
+	return sb.String()
+}
+
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,317 @@
+package fetch_utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const maxInflatedStreamBytes = 8 << 20 // 单个流解压后的最大字节数
+
+var (
+	ErrPDFNoText = errors.New("no extractable text in pdf")
+
+	pdfStreamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	// 不含页面文本的流：图片、字体、交叉引用、对象流、元数据
+	pdfSkippedStreamPattern = regexp.MustCompile(`/Subtype\s*/Image|/FontFile|/Length1|/Type\s*/XRef|/Type\s*/ObjStm|/Type\s*/Metadata`)
+)
+
+// ExtractPDF 尽力提取 PDF 页面内容流中的文本
+//
+// 仅支持未压缩及 FlateDecode 压缩的内容流，按 Tj/TJ/'/" 操作符提取字符串；使用自定义编码的 CID 字体或扫描件无法提取
+func ExtractPDF(body []byte) (string, error) {
+	var sb strings.Builder
+	for _, loc := range pdfStreamPattern.FindAllSubmatchIndex(body, -1) {
+		dict := body[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(body[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		if pdfSkippedStreamPattern.Match(dict) {
+			continue
+		}
+		data := body[start : start+end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			inflated, err := inflate(data)
+			if err != nil {
+				continue
+			}
+			data = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// 其它压缩方式不支持
+			continue
+		}
+		if !bytes.Contains(data, []byte("BT")) {
+			continue
+		}
+		sb.WriteString(extractContentStreamText(data))
+	}
+	text := strings.TrimSpace(blankLinePattern.ReplaceAllString(sb.String(), "\n\n"))
+	if text == "" {
+		return "", ErrPDFNoText
+	}
+	return text, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxInflatedStreamBytes))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return out, nil
+}
+
+// pdfOperand 内容流中的操作数
+type pdfOperand struct {
+	text   string       // 字符串
+	number float64      // 数字
+	array  []pdfOperand // 数组
+	isText bool
+}
+
+// extractContentStreamText 解析内容流，提取 BT/ET 之间的文本
+func extractContentStreamText(data []byte) string {
+	var sb strings.Builder
+	var operands []pdfOperand
+	inText := false
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case isPDFWhitespace(c):
+			i++
+		case c == '%':
+			// 注释
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := readLiteralString(data, i)
+			operands = append(operands, pdfOperand{text: s, isText: true})
+			i = next
+		case c == '<' && i+1 < len(data) && data[i+1] != '<':
+			s, next := readHexString(data, i)
+			operands = append(operands, pdfOperand{text: s, isText: true})
+			i = next
+		case c == '[':
+			arr, next := readArray(data, i)
+			operands = append(operands, pdfOperand{array: arr})
+			i = next
+		case c == '<' || c == '>' || c == ']' || c == '{' || c == '}' || c == ')':
+			i++
+		default:
+			token, next := readToken(data, i)
+			i = next
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				operands = append(operands, pdfOperand{number: n})
+				continue
+			}
+			if strings.HasPrefix(token, "/") {
+				operands = append(operands, pdfOperand{})
+				continue
+			}
+			switch token {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				sb.WriteString("\n")
+			case "Tj":
+				if inText && len(operands) > 0 {
+					sb.WriteString(operands[len(operands)-1].text)
+				}
+			case "'", "\"":
+				if inText && len(operands) > 0 {
+					sb.WriteString("\n" + operands[len(operands)-1].text)
+				}
+			case "TJ":
+				if inText && len(operands) > 0 {
+					for _, item := range operands[len(operands)-1].array {
+						if item.isText {
+							sb.WriteString(item.text)
+						} else if item.number < -200 {
+							// 较大的字距调整通常表示单词间隔
+							sb.WriteString(" ")
+						}
+					}
+				}
+			case "T*":
+				if inText {
+					sb.WriteString("\n")
+				}
+			case "Td", "TD":
+				if inText && len(operands) >= 2 && operands[len(operands)-1].number != 0 {
+					sb.WriteString("\n")
+				} else if inText {
+					sb.WriteString(" ")
+				}
+			}
+			operands = operands[:0]
+		}
+	}
+	return sb.String()
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func readToken(data []byte, i int) (string, int) {
+	start := i
+	if data[i] == '/' {
+		i++
+	}
+	for i < len(data) && !isPDFWhitespace(data[i]) && !isPDFDelimiter(data[i]) {
+		i++
+	}
+	if i == start {
+		i++
+	}
+	return string(data[start:i]), i
+}
+
+// readLiteralString 读取 (...) 字符串，处理转义及嵌套括号
+func readLiteralString(data []byte, i int) (string, int) {
+	var buf []byte
+	depth := 0
+	for i++; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch e := data[i]; e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// 续行
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for j := 0; j < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7'; j++ {
+						n = n*8 + int(data[i]-'0')
+						i++
+					}
+					i--
+					buf = append(buf, byte(n))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+		case '(':
+			depth++
+			buf = append(buf, c)
+		case ')':
+			if depth == 0 {
+				return decodePDFString(buf), i + 1
+			}
+			depth--
+			buf = append(buf, c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return decodePDFString(buf), i
+}
+
+// readHexString 读取 <...> 十六进制字符串
+func readHexString(data []byte, i int) (string, int) {
+	end := bytes.IndexByte(data[i:], '>')
+	if end < 0 {
+		return "", len(data)
+	}
+	hex := make([]byte, 0, end)
+	for _, c := range data[i+1 : i+end] {
+		if !isPDFWhitespace(c) {
+			hex = append(hex, c)
+		}
+	}
+	if len(hex)%2 == 1 {
+		hex = append(hex, '0')
+	}
+	buf := make([]byte, 0, len(hex)/2)
+	for j := 0; j < len(hex); j += 2 {
+		n, err := strconv.ParseUint(string(hex[j:j+2]), 16, 8)
+		if err != nil {
+			return "", i + end + 1
+		}
+		buf = append(buf, byte(n))
+	}
+	return decodePDFString(buf), i + end + 1
+}
+
+// readArray 读取 [...] 数组中的字符串及数字
+func readArray(data []byte, i int) ([]pdfOperand, int) {
+	var items []pdfOperand
+	for i++; i < len(data); {
+		c := data[i]
+		switch {
+		case c == ']':
+			return items, i + 1
+		case isPDFWhitespace(c):
+			i++
+		case c == '(':
+			s, next := readLiteralString(data, i)
+			items = append(items, pdfOperand{text: s, isText: true})
+			i = next
+		case c == '<' && i+1 < len(data) && data[i+1] != '<':
+			s, next := readHexString(data, i)
+			items = append(items, pdfOperand{text: s, isText: true})
+			i = next
+		default:
+			token, next := readToken(data, i)
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				items = append(items, pdfOperand{number: n})
+			}
+			i = next
+		}
+	}
+	return items, i
+}
+
+// decodePDFString 解码字符串：UTF-16BE（带 BOM）或按 Latin-1 处理，丢弃不可打印字符
+func decodePDFString(buf []byte) string {
+	var runes []rune
+	if len(buf) >= 2 && buf[0] == 0xFE && buf[1] == 0xFF {
+		units := make([]uint16, 0, len(buf)/2)
+		for j := 2; j+1 < len(buf); j += 2 {
+			units = append(units, uint16(buf[j])<<8|uint16(buf[j+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		runes = make([]rune, len(buf))
+		for j, b := range buf {
+			runes[j] = rune(b)
+		}
+	}
+	return strings.Map(
+		func(r rune) rune {
+			if r == '\n' || r == '\t' || unicode.IsPrint(r) {
+				return r
+			}
+			return -1
+		}, string(runes),
+	)
+}
@@ -0,0 +1,114 @@
+package knowledge_utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+)
+
+var (
+	paragraphPattern = regexp.MustCompile(`\n\s*\n`)
+	// sentencePattern 匹配句末标点及其后的空白，用于切分过长的段落
+	sentencePattern = regexp.MustCompile(`([。！？；!?;]|[.](\s|$))\s*`)
+)
+
+// SplitText 将文本切分为不超过 chunkTokens 的分段，相邻分段重叠约 overlapTokens
+//
+// 优先在段落边界切分，段落过长时在句子边界切分，句子仍过长时按字符硬切分
+func SplitText(text string, chunkTokens int64, overlapTokens int64) []string {
+	if chunkTokens <= 0 {
+		return nil
+	}
+	if overlapTokens < 0 || overlapTokens >= chunkTokens {
+		overlapTokens = 0
+	}
+
+	var pieces []string
+	for _, paragraph := range paragraphPattern.Split(text, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if chat_utils.EstimateTokens(paragraph) <= chunkTokens {
+			pieces = append(pieces, paragraph)
+			continue
+		}
+		for _, sentence := range splitSentences(paragraph) {
+			if chat_utils.EstimateTokens(sentence) <= chunkTokens {
+				pieces = append(pieces, sentence)
+			} else {
+				pieces = append(pieces, splitRunes(sentence, chunkTokens)...)
+			}
+		}
+	}
+
+	var chunks []string
+	var current []string
+	var currentTokens int64
+	fresh := false // current 中是否有尚未输出的片段
+	flush := func() {
+		if !fresh {
+			return
+		}
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+		// 保留末尾的若干片段作为下一分段的开头
+		var kept []string
+		var keptTokens int64
+		for i := len(current) - 1; i >= 0; i-- {
+			tokens := chat_utils.EstimateTokens(current[i])
+			if keptTokens+tokens > overlapTokens {
+				break
+			}
+			kept = append([]string{current[i]}, kept...)
+			keptTokens += tokens
+		}
+		current, currentTokens, fresh = kept, keptTokens, false
+	}
+	for _, piece := range pieces {
+		tokens := chat_utils.EstimateTokens(piece)
+		if currentTokens+tokens > chunkTokens {
+			flush()
+			if currentTokens+tokens > chunkTokens {
+				// 重叠部分与当前片段无法放入同一分段
+				current, currentTokens = nil, 0
+			}
+		}
+		current = append(current, piece)
+		currentTokens += tokens
+		fresh = true
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences 在句末标点处切分文本，标点保留在句子末尾
+func splitSentences(text string) []string {
+	var sentences []string
+	last := 0
+	for _, loc := range sentencePattern.FindAllStringIndex(text, -1) {
+		if sentence := strings.TrimSpace(text[last:loc[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		last = loc[1]
+	}
+	if sentence := strings.TrimSpace(text[last:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// splitRunes 按字符切分没有合适边界的长文本
+func splitRunes(text string, chunkTokens int64) []string {
+	runes := []rune(text)
+	var parts []string
+	for len(runes) > 0 {
+		size := len(runes)
+		for size > 1 && chat_utils.EstimateTokens(string(runes[:size])) > chunkTokens {
+			size = size * 3 / 4
+		}
+		parts = append(parts, string(runes[:size]))
+		runes = runes[size:]
+	}
+	return parts
+}
@@ -0,0 +1,72 @@
+package knowledge_utils
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fcraft/open-chat/internal/utils/fetch_utils"
+)
+
+var (
+	ErrUnsupportedDocument = errors.New("unsupported document type")
+	ErrEmptyDocument       = errors.New("no text found in document")
+)
+
+// textExtensions 按纯文本读取的文件扩展名
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".markdown": true, ".csv": true, ".json": true, ".log": true,
+	".yaml": true, ".yml": true, ".xml": true, ".rst": true,
+}
+
+// ExtractText 提取上传文档的正文，支持纯文本、Markdown、HTML 及 PDF
+//
+// 文件类型依次根据扩展名、上传时声明的 MIME 类型及内容嗅探确定
+func ExtractText(name string, mimeType string, data []byte) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+
+	var text string
+	switch {
+	case ext == ".pdf" || mimeType == "application/pdf":
+		content, err := fetch_utils.ExtractPDF(data)
+		if err != nil {
+			return "", err
+		}
+		text = content
+	case ext == ".html" || ext == ".htm" || mimeType == "text/html":
+		_, content, err := fetch_utils.ExtractHTML(data)
+		if err != nil {
+			return "", err
+		}
+		text = content
+	case textExtensions[ext] || strings.HasPrefix(mimeType, "text/") || mimeType == "application/json":
+		if !utf8.Valid(data) {
+			return "", ErrUnsupportedDocument
+		}
+		text = string(data)
+	default:
+		return "", ErrUnsupportedDocument
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", ErrEmptyDocument
+	}
+	return text, nil
+}
+
+// Norm 计算向量的模
+func Norm(vec []float32) float64 {
+	var sum float64
+	for _, f := range vec {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,51 @@
+package search_utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Citation 回答引用的来源，Index 与提示词中的编号一致
+type Citation struct {
+	Index       int    `json:"index"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Engine      string `json:"engine,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+// formatEntry 格式化单条带编号的搜索结果
+func formatEntry(index int, result SearchResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%d] %s\n来源：%s\n", index, result.Title, result.URL)
+	if result.PublishedAt != "" {
+		fmt.Fprintf(&sb, "发布时间：%s\n", result.PublishedAt)
+	}
+	if result.Content != "" {
+		sb.WriteString(result.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// BuildCitationPrompt 将搜索结果组装为带编号的提示词，并返回对应的引用列表
+func BuildCitationPrompt(query string, results []SearchResult) (string, []Citation) {
+	citations := make([]Citation, 0, len(results))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "以下是针对「%s」的联网搜索结果，方括号中的数字为引用编号：\n\n", query)
+	for i, result := range results {
+		sb.WriteString(formatEntry(i+1, result))
+		sb.WriteString("\n")
+		citations = append(
+			citations, Citation{
+				Index:       i + 1,
+				Title:       result.Title,
+				URL:         result.URL,
+				Engine:      result.Engine,
+				PublishedAt: result.PublishedAt,
+			},
+		)
+	}
+	sb.WriteString("请参考以上信息回答我的问题。使用某条结果的信息时，在相应语句末尾标注其编号，如 [1]；与问题无关的结果请忽略。")
+	return sb.String(), citations
+}
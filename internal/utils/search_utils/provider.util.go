@@ -0,0 +1,100 @@
+package search_utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 搜索服务类型，对应 ProviderConfig.Type
+const (
+	ProviderTypeSearXNG = "searxng" // SearXNG JSON 接口
+	ProviderTypeBing    = "bing"    // Bing Web Search API v7
+	ProviderTypeBrave   = "brave"   // Brave Search API
+	ProviderTypeFixture = "fixture" // 本地固定结果，用于测试及离线环境
+)
+
+// SearchResult 单条搜索结果
+type SearchResult struct {
+	Title       string  `json:"title"`
+	URL         string  `json:"url"`
+	Content     string  `json:"content"`                // 摘要
+	PublishedAt string  `json:"published_at,omitempty"` // 发布时间，格式取决于搜索服务
+	Engine      string  `json:"engine,omitempty"`       // 来源的搜索服务或引擎
+	Score       float64 `json:"score,omitempty"`        // 排序得分，由 Rank 计算
+}
+
+// SearchRequest 搜索请求
+type SearchRequest struct {
+	Query string
+	Limit int // 期望的结果数量，搜索服务可能返回更少
+}
+
+// SearchProvider 搜索服务，按服务返回的相关性顺序返回结果
+type SearchProvider interface {
+	Search(ctx context.Context, req SearchRequest) ([]SearchResult, error)
+}
+
+// ProviderConfig 搜索服务配置
+type ProviderConfig struct {
+	Type    string         `json:"type"`
+	BaseURL string         `json:"base_url"`          // 服务地址，fixture 类型为结果文件路径
+	APIKey  string         `json:"api_key,omitempty"` // 访问密钥，SearXNG 不需要
+	Weight  float64        `json:"weight,omitempty"`  // 排序权重，默认 1
+	Results []SearchResult `json:"results,omitempty"` // fixture 类型的内联结果
+}
+
+// ProviderFactory 根据配置创建搜索服务
+type ProviderFactory func(cfg ProviderConfig) (SearchProvider, error)
+
+var providerFactories sync.Map // 服务类型 -> ProviderFactory
+
+func init() {
+	RegisterProviderFactory(ProviderTypeSearXNG, newSearXNGProvider)
+	RegisterProviderFactory(ProviderTypeBing, newBingProvider)
+	RegisterProviderFactory(ProviderTypeBrave, newBraveProvider)
+	RegisterProviderFactory(ProviderTypeFixture, newFixtureProvider)
+}
+
+// RegisterProviderFactory 注册搜索服务，同名覆盖
+func RegisterProviderFactory(providerType string, factory ProviderFactory) {
+	providerFactories.Store(providerType, factory)
+}
+
+// NewProvider 根据配置创建搜索服务
+func NewProvider(cfg ProviderConfig) (SearchProvider, error) {
+	factory, ok := providerFactories.Load(cfg.Type)
+	if !ok {
+		return nil, fmt.Errorf("unsupported search provider type: %s", cfg.Type)
+	}
+	return factory.(ProviderFactory)(cfg)
+}
+
+// httpClient 请求搜索服务使用的客户端
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// getJSON 发起 GET 请求并解析 JSON 响应
+func getJSON(ctx context.Context, url string, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("search provider returned %d: %s", resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
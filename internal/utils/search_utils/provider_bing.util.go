@@ -0,0 +1,66 @@
+package search_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const bingDefaultBaseURL = "https://api.bing.microsoft.com"
+
+// bingProvider Bing Web Search API v7
+type bingProvider struct {
+	baseURL string
+	apiKey  string
+}
+
+func newBingProvider(cfg ProviderConfig) (SearchProvider, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("bing api key is required")
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = bingDefaultBaseURL
+	}
+	return &bingProvider{baseURL: strings.TrimRight(baseURL, "/"), apiKey: cfg.APIKey}, nil
+}
+
+func (p *bingProvider) Search(ctx context.Context, req SearchRequest) ([]SearchResult, error) {
+	var resp struct {
+		WebPages struct {
+			Value []struct {
+				Name            string `json:"name"`
+				URL             string `json:"url"`
+				Snippet         string `json:"snippet"`
+				DatePublished   string `json:"datePublished"`
+				DateLastCrawled string `json:"dateLastCrawled"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	endpoint := fmt.Sprintf("%s/v7.0/search?q=%s", p.baseURL, url.QueryEscape(req.Query))
+	if req.Limit > 0 {
+		endpoint += fmt.Sprintf("&count=%d", req.Limit)
+	}
+	if err := getJSON(ctx, endpoint, map[string]string{"Ocp-Apim-Subscription-Key": p.apiKey}, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(resp.WebPages.Value))
+	for _, r := range resp.WebPages.Value {
+		publishedAt := r.DatePublished
+		if publishedAt == "" {
+			publishedAt = r.DateLastCrawled
+		}
+		results = append(
+			results, SearchResult{
+				Title:       r.Name,
+				URL:         r.URL,
+				Content:     r.Snippet,
+				PublishedAt: publishedAt,
+				Engine:      ProviderTypeBing,
+			},
+		)
+	}
+	return limitResults(results, req.Limit), nil
+}
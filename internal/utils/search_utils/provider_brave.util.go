@@ -0,0 +1,61 @@
+package search_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const braveDefaultBaseURL = "https://api.search.brave.com"
+
+// braveProvider Brave Search API
+type braveProvider struct {
+	baseURL string
+	apiKey  string
+}
+
+func newBraveProvider(cfg ProviderConfig) (SearchProvider, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("brave api key is required")
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = braveDefaultBaseURL
+	}
+	return &braveProvider{baseURL: strings.TrimRight(baseURL, "/"), apiKey: cfg.APIKey}, nil
+}
+
+func (p *braveProvider) Search(ctx context.Context, req SearchRequest) ([]SearchResult, error) {
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+				PageAge     string `json:"page_age"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	endpoint := fmt.Sprintf("%s/res/v1/web/search?q=%s", p.baseURL, url.QueryEscape(req.Query))
+	if req.Limit > 0 {
+		endpoint += fmt.Sprintf("&count=%d", min(req.Limit, 20))
+	}
+	if err := getJSON(ctx, endpoint, map[string]string{"X-Subscription-Token": p.apiKey}, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		results = append(
+			results, SearchResult{
+				Title:       r.Title,
+				URL:         r.URL,
+				Content:     r.Description,
+				PublishedAt: r.PageAge,
+				Engine:      ProviderTypeBrave,
+			},
+		)
+	}
+	return limitResults(results, req.Limit), nil
+}
@@ -0,0 +1,41 @@
+package search_utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// fixtureProvider 返回固定的结果，不访问网络；结果来自配置内联的 results 或 base_url 指向的 JSON 文件
+type fixtureProvider struct {
+	results []SearchResult
+}
+
+func newFixtureProvider(cfg ProviderConfig) (SearchProvider, error) {
+	results := cfg.Results
+	if len(results) == 0 && cfg.BaseURL != "" {
+		data, err := os.ReadFile(cfg.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, err
+		}
+	}
+	if len(results) == 0 {
+		return nil, errors.New("fixture provider has no results")
+	}
+	return &fixtureProvider{results: results}, nil
+}
+
+func (p *fixtureProvider) Search(_ context.Context, req SearchRequest) ([]SearchResult, error) {
+	results := make([]SearchResult, len(p.results))
+	for i, r := range p.results {
+		if r.Engine == "" {
+			r.Engine = ProviderTypeFixture
+		}
+		results[i] = r
+	}
+	return limitResults(results, req.Limit), nil
+}
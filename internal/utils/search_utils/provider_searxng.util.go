@@ -0,0 +1,58 @@
+package search_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// searXNGProvider SearXNG 的 JSON 接口，需在实例的 settings.yml 中启用 json 格式
+type searXNGProvider struct {
+	baseURL string
+}
+
+func newSearXNGProvider(cfg ProviderConfig) (SearchProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("searxng base url is required")
+	}
+	return &searXNGProvider{baseURL: strings.TrimRight(cfg.BaseURL, "/")}, nil
+}
+
+func (p *searXNGProvider) Search(ctx context.Context, req SearchRequest) ([]SearchResult, error) {
+	var resp struct {
+		Results []struct {
+			Title         string `json:"title"`
+			URL           string `json:"url"`
+			Content       string `json:"content"`
+			PublishedDate string `json:"publishedDate"`
+			Engine        string `json:"engine"`
+		} `json:"results"`
+	}
+	endpoint := fmt.Sprintf("%s/search?format=json&q=%s", p.baseURL, url.QueryEscape(req.Query))
+	if err := getJSON(ctx, endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(
+			results, SearchResult{
+				Title:       r.Title,
+				URL:         r.URL,
+				Content:     r.Content,
+				PublishedAt: r.PublishedDate,
+				Engine:      r.Engine,
+			},
+		)
+	}
+	return limitResults(results, req.Limit), nil
+}
+
+// limitResults 截取前 limit 条结果，limit 不大于 0 时不截取
+func limitResults(results []SearchResult, limit int) []SearchResult {
+	if limit > 0 && len(results) > limit {
+		return results[:limit]
+	}
+	return results
+}
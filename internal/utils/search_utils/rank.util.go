@@ -0,0 +1,140 @@
+package search_utils
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
+)
+
+const (
+	rankConstant    = 60  // 倒数排名融合的平滑常数
+	maxContentRunes = 600 // 单条结果摘要的最大字符数
+	minEntryTokens  = 48  // 预算不足以容纳完整结果时，截断后至少保留的 token 数
+)
+
+// RankedList 一个搜索服务返回的结果及其权重
+type RankedList struct {
+	Results []SearchResult
+	Weight  float64 // 为 0 时按 1 计算
+}
+
+// Rank 合并多个搜索服务的结果并排序
+//
+// 使用倒数排名融合（RRF）：每条结果按其在各服务中的名次累加 weight/(60+rank)，同一网址的结果合并；
+// 标题及摘要中包含的查询词比例作为附加分，用于区分名次相同的结果
+func Rank(query string, lists []RankedList) []SearchResult {
+	merged := make(map[string]*SearchResult)
+	var order []string
+	for _, list := range lists {
+		weight := list.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for rank, result := range list.Results {
+			if result.URL == "" {
+				continue
+			}
+			key := normalizeURL(result.URL)
+			score := weight / float64(rankConstant+rank+1)
+			if existing, ok := merged[key]; ok {
+				existing.Score += score
+				if len(result.Content) > len(existing.Content) {
+					existing.Content = result.Content
+				}
+				if existing.PublishedAt == "" {
+					existing.PublishedAt = result.PublishedAt
+				}
+				continue
+			}
+			result.Score = score
+			merged[key] = &result
+			order = append(order, key)
+		}
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	results := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		result := merged[key]
+		result.Score += termCoverage(terms, result) / (2 * rankConstant)
+		results = append(results, *result)
+	}
+	sort.SliceStable(
+		results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		},
+	)
+	return results
+}
+
+// termCoverage 标题及摘要中出现的查询词比例
+func termCoverage(terms []string, result *SearchResult) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	text := strings.ToLower(result.Title + " " + result.Content)
+	hit := 0
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(terms))
+}
+
+// normalizeURL 用于判断两个网址是否为同一页面：忽略协议、www 前缀、锚点及末尾斜杠
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimRight(u.Path, "/")
+	if u.RawQuery != "" {
+		return host + path + "?" + u.RawQuery
+	}
+	return host + path
+}
+
+// TruncateToBudget 按顺序保留结果，使格式化后的总 token 数不超过预算，放不下的结果截断摘要或丢弃
+func TruncateToBudget(results []SearchResult, budget int64) []SearchResult {
+	kept := make([]SearchResult, 0, len(results))
+	remaining := budget
+	for _, result := range results {
+		result.Content = truncateRunes(result.Content, maxContentRunes)
+		cost := chat_utils.EstimateTokens(formatEntry(len(kept)+1, result))
+		if cost > remaining {
+			if remaining < minEntryTokens {
+				break
+			}
+			// 按比例截断摘要
+			overhead := cost - chat_utils.EstimateTokens(result.Content)
+			if remaining <= overhead {
+				break
+			}
+			runes := utf8.RuneCountInString(result.Content)
+			result.Content = truncateRunes(result.Content, int(float64(runes)*float64(remaining-overhead)/float64(cost-overhead)))
+			cost = chat_utils.EstimateTokens(formatEntry(len(kept)+1, result))
+			if cost > remaining {
+				break
+			}
+		}
+		kept = append(kept, result)
+		remaining -= cost
+	}
+	return kept
+}
+
+// truncateRunes 截断到指定字符数，超出时以省略号结尾
+func truncateRunes(s string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit]) + "…"
+}
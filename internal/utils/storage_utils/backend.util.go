@@ -0,0 +1,74 @@
+package storage_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/schema"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrInvalidKey     = errors.New("invalid object key")
+)
+
+// Backend 储存后端，对象路径 key 使用 / 分隔，不以 / 开头
+type Backend interface {
+	// Put 写入对象，size 为对象大小，未知时为 -1
+	Put(ctx context.Context, bucket *schema.Bucket, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，对象不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, bucket *schema.Bucket, key string) (io.ReadCloser, error)
+	// Stat 获取对象大小，对象不存在时返回 ErrObjectNotFound
+	Stat(ctx context.Context, bucket *schema.Bucket, key string) (int64, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, bucket *schema.Bucket, key string) error
+	// PresignURL 生成临时访问链接，method 为 GET 或 PUT
+	PresignURL(bucket *schema.Bucket, method string, key string, expires time.Duration) (string, error)
+}
+
+var backends sync.Map // 储存桶类型 -> Backend
+
+func init() {
+	RegisterBackend(schema.BucketTypeS3, &s3Backend{})
+	RegisterBackend(schema.BucketTypeLocal, &localBackend{})
+}
+
+// RegisterBackend 注册储存后端，同名覆盖
+func RegisterBackend(bucketType string, backend Backend) {
+	backends.Store(bucketType, backend)
+}
+
+// GetBackend 获取储存桶对应的储存后端，类型为空时使用 S3
+func GetBackend(bucket *schema.Bucket) (Backend, error) {
+	if bucket == nil {
+		return nil, errors.New("bucket is nil")
+	}
+	bucketType := bucket.Type
+	if bucketType == "" {
+		bucketType = schema.BucketTypeS3
+	}
+	backend, ok := backends.Load(bucketType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported bucket type: %s", bucketType)
+	}
+	return backend.(Backend), nil
+}
+
+// CleanKey 校验并规范化对象路径，拒绝空路径及包含 .. 的路径
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") || strings.Contains(key, "\x00") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,152 @@
+package storage_utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/schema"
+)
+
+// LocalObjectPath 本地储存对象的访问路径前缀，完整路径为 /storage/object/{bucket_id}/{key}
+const LocalObjectPath = "/storage/object"
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrURLExpired       = errors.New("url expired")
+)
+
+// localBackend 本地文件系统，对象保存在 EndpointURL/BucketName 目录下
+//
+// 临时链接指向本服务的 LocalObjectPath，使用储存桶的 SecretAccessKey 签名
+type localBackend struct{}
+
+// objectPath 对象在文件系统中的路径
+func (b *localBackend) objectPath(bucket *schema.Bucket, key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if bucket.EndpointURL == "" {
+		return "", errors.New("local bucket root directory is empty")
+	}
+	return filepath.Join(bucket.EndpointURL, bucket.BucketName, filepath.FromSlash(key)), nil
+}
+
+func (b *localBackend) Put(_ context.Context, bucket *schema.Bucket, key string, r io.Reader, _ int64, _ string) error {
+	name, err := b.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	// 先写入临时文件再重命名，避免读取到未写完的对象
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (b *localBackend) Get(_ context.Context, bucket *schema.Bucket, key string) (io.ReadCloser, error) {
+	name, err := b.objectPath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+func (b *localBackend) Stat(_ context.Context, bucket *schema.Bucket, key string) (int64, error) {
+	name, err := b.objectPath(bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return 0, ErrObjectNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (b *localBackend) Delete(_ context.Context, bucket *schema.Bucket, key string) error {
+	name, err := b.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PresignURL 生成本服务的签名链接（相对路径），由 LocalObjectPath 下的路由校验签名后读写对象
+func (b *localBackend) PresignURL(bucket *schema.Bucket, method string, key string, expires time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if bucket.SecretAccessKey == "" {
+		return "", errors.New("local bucket secret access key is empty")
+	}
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{
+		"method":    {method},
+		"expires":   {expiresAt},
+		"signature": {signLocalURL(bucket, method, key, expiresAt)},
+	}
+	escaped := strings.Split(key, "/")
+	for i, part := range escaped {
+		escaped[i] = url.PathEscape(part)
+	}
+	return fmt.Sprintf("%s/%d/%s?%s", LocalObjectPath, bucket.ID, strings.Join(escaped, "/"), query.Encode()), nil
+}
+
+// VerifyLocalURL 校验本地储存签名链接的方法、有效期及签名
+func VerifyLocalURL(bucket *schema.Bucket, method string, key string, expiresAt string, signature string) error {
+	if bucket.SecretAccessKey == "" {
+		return ErrInvalidSignature
+	}
+	expected := signLocalURL(bucket, method, key, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+func signLocalURL(bucket *schema.Bucket, method string, key string, expiresAt string) string {
+	mac := hmac.New(sha256.New, []byte(bucket.SecretAccessKey))
+	mac.Write([]byte(strings.Join([]string{strings.ToUpper(method), strconv.FormatUint(bucket.ID, 10), key, expiresAt}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package storage_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fcraft/open-chat/internal/schema"
+	"github.com/fcraft/open-chat/internal/utils/s3_utils"
+)
+
+const s3RequestExpire = 15 * time.Minute // 服务端读写对象时使用的预签名链接有效期
+
+// s3Backend S3 兼容的对象储存，所有请求均通过预签名链接完成
+type s3Backend struct{}
+
+func (b *s3Backend) do(ctx context.Context, bucket *schema.Bucket, method string, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	presigned, err := s3_utils.PresignURL(bucket, method, key, s3RequestExpire)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, presigned, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// checkResponse 非 2xx 响应转换为错误，404 转换为 ErrObjectNotFound，并关闭响应体
+func checkResponse(resp *http.Response, method string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s object failed: %s %s", method, resp.Status, body)
+}
+
+func (b *s3Backend) Put(ctx context.Context, bucket *schema.Bucket, key string, r io.Reader, size int64, contentType string) error {
+	resp, err := b.do(ctx, bucket, http.MethodPut, key, r, size, contentType)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, http.MethodPut); err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (b *s3Backend) Get(ctx context.Context, bucket *schema.Bucket, key string) (io.ReadCloser, error) {
+	resp, err := b.do(ctx, bucket, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, http.MethodGet); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (b *s3Backend) Stat(ctx context.Context, bucket *schema.Bucket, key string) (int64, error) {
+	resp, err := b.do(ctx, bucket, http.MethodHead, key, nil, 0, "")
+	if err != nil {
+		return 0, err
+	}
+	if err := checkResponse(resp, http.MethodHead); err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+func (b *s3Backend) Delete(ctx context.Context, bucket *schema.Bucket, key string) error {
+	resp, err := b.do(ctx, bucket, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, http.MethodDelete); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (b *s3Backend) PresignURL(bucket *schema.Bucket, method string, key string, expires time.Duration) (string, error) {
+	return s3_utils.PresignURL(bucket, method, key, expires)
+}
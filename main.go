@@ -8,6 +8,7 @@ import (
 	"github.com/fcraft/open-chat/internal/storage/gorm"
 	"github.com/fcraft/open-chat/internal/storage/helper"
 	"github.com/fcraft/open-chat/internal/storage/redis"
+	"github.com/fcraft/open-chat/internal/utils/chat_utils"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"log"
@@ -30,8 +31,21 @@ func main() {
 	services.InitPresetService(baseService)                       // 初始化预设缓存服务 !高优先级
 	services.InitScheduleService(baseService)                     // 初始化定时任务服务 !高优先级
 	services.InitSystemConfigService(baseService)                 // 初始化系统配置服务
+	services.InitCompletionCancelService(baseService)             // 初始化补全停止服务
+	services.InitAPIKeyService(baseService)                       // 初始化 API Key 健康检查服务
+	services.InitAccessTokenService(baseService)                  // 初始化个人访问令牌服务
+	services.InitUsageService(baseService)                        // 初始化用量流水服务
+	services.InitVoucherService(baseService)                      // 初始化兑换码服务
+	services.InitPlanService(baseService)                         // 初始化订阅套餐服务
+	services.InitSearchService(baseService)                       // 初始化联网搜索服务
+	services.InitEmbeddingService(baseService)                    // 初始化文本向量化服务
+	services.InitKnowledgeService(baseService)                    // 初始化知识库服务
+	services.InitToolRegistryService(baseService)                 // 初始化工具中心，需先于注册工具的服务
+	services.InitURLFetchService(baseService)                     // 初始化链接读取服务
+	services.InitStorageService(baseService)                      // 初始化文件储存服务
 	intervalCacheService := services.NewCacheService(baseService) // 定时缓存服务
 	go services.InitEncryptService()
+	go chat_utils.InitTokenizer()                       // 加载分词器
 	go services.InitOAuthService(baseService)           // 注册OAuth服务
 	go services.InitChatService(baseService)            // 注册对话服务
 	go services.InitMakeQuestionService(baseService)    // 初始化题目生成服务